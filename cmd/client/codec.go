@@ -0,0 +1,206 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bhojpur/service/pkg/engine/codec"
+	"github.com/bhojpur/service/pkg/engine/codec/schema"
+	"github.com/bhojpur/service/pkg/utils"
+)
+
+var (
+	codecSchema  string
+	codecMessage string
+	codecOutput  string
+	codecHex     bool
+)
+
+// codecCmd represents the codec command
+var codecCmd = &cobra.Command{
+	Use:   "codec",
+	Short: "Inspect and transcode the codec payloads",
+	Long:  "Inspect the codec payloads as a tree, and transcode between JSON and codec by the messages of codec schema",
+}
+
+var codecInspectCmd = &cobra.Command{
+	Use:   "inspect [file]",
+	Short: "Print the codec payload as a tree of tags and guessed values",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		buf := readCodecInput(args)
+		packets, err := codec.Inspect(buf)
+		fmt.Print(codec.FormatInspected(packets))
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var codecEncodeCmd = &cobra.Command{
+	Use:   "encode [file.json]",
+	Short: "Encode the JSON object as the message of codec schema",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s := loadCodecSchema()
+		buf, err := s.EncodeJSON(codecMessage, readInput(args))
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			os.Exit(1)
+		}
+		writeCodecOutput(buf)
+	},
+}
+
+var codecDecodeCmd = &cobra.Command{
+	Use:   "decode [file]",
+	Short: "Decode the codec payload as JSON by the message of codec schema",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s := loadCodecSchema()
+		data, err := s.DecodeJSON(codecMessage, readCodecInput(args))
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			os.Exit(1)
+		}
+		var out bytes.Buffer
+		_ = json.Indent(&out, data, "", "  ")
+		out.WriteByte('\n')
+		writeOutput(out.Bytes())
+	},
+}
+
+var codecRoundTripCmd = &cobra.Command{
+	Use:   "roundtrip [file.json]",
+	Short: "Encode the JSON object and decode it back, to verify the test fixtures",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s := loadCodecSchema()
+		input := readInput(args)
+		buf, err := s.EncodeJSON(codecMessage, input)
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			os.Exit(1)
+		}
+		data, err := s.DecodeJSON(codecMessage, buf)
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			os.Exit(1)
+		}
+		// the fields missing in input are decoded as zero values
+		encoded, err := s.EncodeJSON(codecMessage, data)
+		if err != nil || !bytes.Equal(encoded, buf) {
+			utils.FailureStatusEvent(os.Stdout, "%s is changed after round trip", codecMessage)
+			os.Exit(1)
+		}
+		if codecOutput != "" {
+			writeCodecOutput(buf)
+		}
+		utils.SuccessStatusEvent(os.Stdout, "%s round trip succeeded, %d bytes", codecMessage, len(buf))
+	},
+}
+
+// loadCodecSchema parses the schema given by --schema.
+func loadCodecSchema() *schema.Schema {
+	if codecSchema == "" || codecMessage == "" {
+		utils.FailureStatusEvent(os.Stdout, "--schema and --message are required")
+		os.Exit(1)
+	}
+	s, err := schema.ParseFile(codecSchema)
+	if err != nil {
+		failSchema(err)
+		os.Exit(1)
+	}
+	return s
+}
+
+// readInput reads the file, or stdin if the file is not given or is -.
+func readInput(args []string) []byte {
+	var (
+		buf []byte
+		err error
+	)
+	if len(args) == 0 || args[0] == "-" {
+		buf, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		buf, err = ioutil.ReadFile(args[0])
+	}
+	if err != nil {
+		utils.FailureStatusEvent(os.Stdout, err.Error())
+		os.Exit(1)
+	}
+	return buf
+}
+
+// readCodecInput reads the codec payload, which is hex text if --hex is set.
+func readCodecInput(args []string) []byte {
+	buf := readInput(args)
+	if !codecHex {
+		return buf
+	}
+	text := strings.NewReplacer("0x", "", "0X", "", ",", "", " ", "", "\n", "", "\t", "").Replace(string(buf))
+	buf, err := hex.DecodeString(text)
+	if err != nil {
+		utils.FailureStatusEvent(os.Stdout, err.Error())
+		os.Exit(1)
+	}
+	return buf
+}
+
+// writeCodecOutput writes the codec payload, as hex text if --hex is set.
+func writeCodecOutput(buf []byte) {
+	if codecHex {
+		buf = []byte(hex.EncodeToString(buf) + "\n")
+	}
+	writeOutput(buf)
+}
+
+// writeOutput writes to the file given by --output, or stdout.
+func writeOutput(buf []byte) {
+	if codecOutput == "" {
+		os.Stdout.Write(buf)
+		return
+	}
+	if err := ioutil.WriteFile(codecOutput, buf, 0644); err != nil {
+		utils.FailureStatusEvent(os.Stdout, err.Error())
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(codecCmd)
+	codecCmd.AddCommand(codecInspectCmd, codecEncodeCmd, codecDecodeCmd, codecRoundTripCmd)
+
+	codecCmd.PersistentFlags().StringVarP(&codecSchema, "schema", "s", "", "codec schema file of the message")
+	codecCmd.PersistentFlags().StringVarP(&codecMessage, "message", "m", "", "message name in the codec schema")
+	codecCmd.PersistentFlags().StringVarP(&codecOutput, "output", "o", "", "output file, default is stdout")
+	codecCmd.PersistentFlags().BoolVar(&codecHex, "hex", false, "the codec payload is hex text instead of binary")
+}
@@ -0,0 +1,99 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bhojpur/service/pkg/engine/codec/schema"
+	"github.com/bhojpur/service/pkg/utils"
+)
+
+var (
+	codegenOutput  string
+	codegenPackage string
+	codegenCheck   string
+)
+
+// codegenCmd represents the codegen command
+var codegenCmd = &cobra.Command{
+	Use:   "codegen [schema.codec]",
+	Short: "Generate Go code from the codec schema",
+	Long:  "Generate Go code from the codec schema, which encodes and decodes the messages without reflection. The compatibility with the previous version of schema is checked by --check",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		file := args[0]
+		s, err := schema.ParseFile(file)
+		if err != nil {
+			failSchema(err)
+			os.Exit(1)
+		}
+		if codegenCheck != "" {
+			prev, err := schema.ParseFile(codegenCheck)
+			if err != nil {
+				failSchema(err)
+				os.Exit(1)
+			}
+			if err := schema.Compatible(prev, s); err != nil {
+				failSchema(err)
+				os.Exit(1)
+			}
+			utils.SuccessStatusEvent(os.Stdout, "%s is compatible with %s", file, codegenCheck)
+		}
+		code, err := schema.GenerateGo(s, schema.GoOptions{Package: codegenPackage})
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, "%s: %s", file, err.Error())
+			os.Exit(1)
+		}
+		output := codegenOutput
+		if output == "" {
+			output = strings.TrimSuffix(file, ".codec") + ".gen.go"
+		}
+		if err := ioutil.WriteFile(output, code, 0644); err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			os.Exit(1)
+		}
+		utils.SuccessStatusEvent(os.Stdout, "Generated %s from %s", output, file)
+	},
+}
+
+// failSchema prints the problems of schema one per line.
+func failSchema(err error) {
+	if errs, ok := err.(schema.Errors); ok {
+		for _, e := range errs {
+			utils.FailureStatusEvent(os.Stdout, e.Error())
+		}
+		return
+	}
+	utils.FailureStatusEvent(os.Stdout, err.Error())
+}
+
+func init() {
+	rootCmd.AddCommand(codegenCmd)
+
+	codegenCmd.Flags().StringVarP(&codegenOutput, "output", "o", "", "output file of the generated code, default is <schema>.gen.go")
+	codegenCmd.Flags().StringVarP(&codegenPackage, "package", "p", "", "package of the generated code, default is the package in schema")
+	codegenCmd.Flags().StringVarP(&codegenCheck, "check", "c", "", "previous version of schema to check the compatibility with")
+}
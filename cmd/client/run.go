@@ -27,6 +27,7 @@ import (
 	_ "github.com/bhojpur/service/pkg/serverless/exec"
 	_ "github.com/bhojpur/service/pkg/serverless/golang"
 	_ "github.com/bhojpur/service/pkg/serverless/js"
+	_ "github.com/bhojpur/service/pkg/serverless/wasm"
 	"github.com/bhojpur/service/pkg/utils"
 	"github.com/spf13/cobra"
 )
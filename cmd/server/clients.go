@@ -0,0 +1,63 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	svcsvr "github.com/bhojpur/service/pkg/engine"
+	"github.com/bhojpur/service/pkg/utils"
+)
+
+// clientsCmd represents the clients command
+var clientsCmd = &cobra.Command{
+	Use:   "clients",
+	Short: "List the clients connected to a running Bhojpur Service-Processor",
+	Long:  "List the sources, stream functions and upstream processors connected to a running Bhojpur Service-Processor",
+	Run: func(cmd *cobra.Command, args []string) {
+		clients, err := svcsvr.GetAdminClients(adminAddr)
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tAPP ID\tCONNECTION\tOBSERVED TAGS")
+		for _, c := range clients {
+			tags := make([]string, 0, len(c.ObserveDataTags))
+			for _, tag := range c.ObserveDataTags {
+				tags = append(tags, fmt.Sprintf("%#x", tag))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Name, c.AppID, c.ConnID, strings.Join(tags, ","))
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(clientsCmd)
+
+	clientsCmd.Flags().StringVar(&adminAddr, "admin-addr", svcsvr.DefaultAdminAddr, "Address of the admin API")
+}
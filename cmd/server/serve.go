@@ -0,0 +1,125 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	svcsvr "github.com/bhojpur/service/pkg/engine"
+	"github.com/bhojpur/service/pkg/engine/auth"
+	"github.com/bhojpur/service/pkg/engine/logger"
+	pkgtls "github.com/bhojpur/service/pkg/engine/tls"
+	"github.com/bhojpur/service/pkg/utils"
+)
+
+var (
+	workflowConf string
+	meshConfURL  string
+	adminAddr    string
+	logLevel     string
+	tlsCertFile  string
+	tlsKeyFile   string
+	tlsCAFile    string
+	authAppID    string
+	authSecret   string
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a Bhojpur Service-Processor",
+	Long:  "Run a Bhojpur Service-Processor from the workflow config and the EdgeMesh config",
+	Run: func(cmd *cobra.Command, args []string) {
+		if workflowConf == "" {
+			utils.FailureStatusEvent(os.Stdout, "Please input the file name of workflow config")
+			return
+		}
+		// log level
+		if logLevel != "" {
+			lvl, err := logger.ParseLevel(logLevel)
+			if err != nil {
+				utils.FailureStatusEvent(os.Stdout, err.Error())
+				return
+			}
+			logger.SetLevel(lvl)
+		}
+
+		opts, err := processorOptions()
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			return
+		}
+		processor, err := svcsvr.NewProcessor(workflowConf, opts...)
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			return
+		}
+		err = processor.ConfigMesh(meshConfURL)
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			return
+		}
+
+		utils.InfoStatusEvent(os.Stdout, "Running Bhojpur Service-Processor...")
+		if adminAddr != "" {
+			utils.InfoStatusEvent(os.Stdout, "Admin API is listening on %s", adminAddr)
+		}
+		err = processor.ListenAndServe()
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			return
+		}
+	},
+}
+
+// processorOptions builds the processor options from the command flags.
+func processorOptions() ([]svcsvr.Option, error) {
+	opts := []svcsvr.Option{svcsvr.WithAdminAddr(adminAddr)}
+	// tls
+	if tlsCertFile != "" || tlsKeyFile != "" {
+		tc, err := pkgtls.CreateServerTLSConfigFromFiles(tlsCertFile, tlsKeyFile, tlsCAFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, svcsvr.WithTLSConfig(tc))
+	}
+	// auth
+	if authAppID != "" {
+		opts = append(opts, svcsvr.WithAuth(auth.NewAppKeyAuth(authAppID, authSecret)))
+	}
+	return opts, nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVarP(&workflowConf, "config", "c", "workflow.yaml", "Workflow config file")
+	serveCmd.Flags().StringVarP(&meshConfURL, "mesh-config", "m", "", "The URL of EdgeMesh configuration")
+	serveCmd.Flags().StringVar(&adminAddr, "admin-addr", svcsvr.DefaultAdminAddr, "Listen address of the admin API, empty to disable it")
+	serveCmd.Flags().StringVar(&logLevel, "log-level", "", "Log level: debug, info, warn or error")
+	serveCmd.Flags().StringVar(&tlsCertFile, "tls-cert", "", "TLS certificate file")
+	serveCmd.Flags().StringVar(&tlsKeyFile, "tls-key", "", "TLS private key file")
+	serveCmd.Flags().StringVar(&tlsCAFile, "tls-ca", "", "CA certificate file used to verify the client certificates")
+	serveCmd.Flags().StringVar(&authAppID, "auth-app-id", "", "AppID of the AppKey authentication, empty to disable it")
+	serveCmd.Flags().StringVar(&authSecret, "auth-app-secret", "", "AppSecret of the AppKey authentication")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	svcsvr "github.com/bhojpur/service/pkg/engine"
+	"github.com/bhojpur/service/pkg/utils"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of a running Bhojpur Service-Processor",
+	Long:  "Show the status of a running Bhojpur Service-Processor by querying its admin API",
+	Run: func(cmd *cobra.Command, args []string) {
+		status, err := svcsvr.GetAdminStatus(adminAddr)
+		if err != nil {
+			utils.FailureStatusEvent(os.Stdout, err.Error())
+			return
+		}
+		fmt.Printf("Name:        %s\n", status.Name)
+		fmt.Printf("Address:     %s\n", status.Addr)
+		fmt.Printf("Uptime:      %s\n", status.Uptime)
+		fmt.Printf("Connections: %d\n", status.Connections)
+		fmt.Printf("DataFrames:  %d\n", status.DataFrames)
+		fmt.Printf("Downstreams: %s\n", strings.Join(status.Downstreams, ", "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringVar(&adminAddr, "admin-addr", svcsvr.DefaultAdminAddr, "Address of the admin API")
+}
@@ -0,0 +1,175 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/bhojpur/service/pkg/engine/logger"
+)
+
+const (
+	// DefaultAdminAddr is the default listen address of the admin API.
+	DefaultAdminAddr = "localhost:9141"
+)
+
+// AdminStatus is the response of the admin API `GET /status`.
+type AdminStatus struct {
+	Name        string   `json:"name"`
+	Addr        string   `json:"addr"`
+	Connections int      `json:"connections"`
+	Downstreams []string `json:"downstreams"`
+	DataFrames  int64    `json:"data_frames"`
+	Uptime      string   `json:"uptime"`
+}
+
+// AdminClient is an item of the admin API `GET /clients` response.
+type AdminClient struct {
+	ConnID          string `json:"conn_id"`
+	AppID           string `json:"app_id"`
+	Name            string `json:"name"`
+	ObserveDataTags []int  `json:"observe_data_tags"`
+}
+
+// adminServer exposes the state of a processor through HTTP.
+type adminServer struct {
+	processor *processor
+	startAt   time.Time
+	srv       *http.Server
+}
+
+func newAdminServer(addr string, z *processor) *adminServer {
+	a := &adminServer{
+		processor: z,
+		startAt:   time.Now(),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", a.handleStatus)
+	mux.HandleFunc("/clients", a.handleClients)
+	a.srv = &http.Server{Addr: addr, Handler: mux}
+	return a
+}
+
+// ListenAndServe starts the admin API.
+func (a *adminServer) ListenAndServe() error {
+	logger.Printf("%s✅ admin API listening on: %s", processorLogPrefix, a.srv.Addr)
+	return a.srv.ListenAndServe()
+}
+
+// Close shutdowns the admin API.
+func (a *adminServer) Close() error {
+	return a.srv.Close()
+}
+
+func (a *adminServer) status() *AdminStatus {
+	server := a.processor.server
+	status := &AdminStatus{
+		Name:        a.processor.name,
+		Addr:        a.processor.addr,
+		Connections: len(server.StatsFunctions()),
+		Downstreams: make([]string, 0),
+		DataFrames:  server.StatsCounter(),
+		Uptime:      time.Since(a.startAt).Round(time.Second).String(),
+	}
+	for addr := range server.Downstreams() {
+		status.Downstreams = append(status.Downstreams, addr)
+	}
+	sort.Strings(status.Downstreams)
+	return status
+}
+
+func (a *adminServer) clients() []AdminClient {
+	clients := make([]AdminClient, 0)
+	for connID, app := range a.processor.server.Connector().GetAppSnapshot() {
+		client := AdminClient{
+			ConnID:          connID,
+			AppID:           app.ID(),
+			Name:            app.Name(),
+			ObserveDataTags: make([]int, 0),
+		}
+		for _, tag := range app.ObserveDataTags() {
+			client.ObserveDataTags = append(client.ObserveDataTags, int(tag))
+		}
+		clients = append(clients, client)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].Name == clients[j].Name {
+			return clients[i].ConnID < clients[j].ConnID
+		}
+		return clients[i].Name < clients[j].Name
+	})
+	return clients
+}
+
+func (a *adminServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeAdminResponse(w, r, a.status())
+}
+
+func (a *adminServer) handleClients(w http.ResponseWriter, r *http.Request) {
+	writeAdminResponse(w, r, a.clients())
+}
+
+func writeAdminResponse(w http.ResponseWriter, r *http.Request, v interface{}) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Errorf("%sadmin API: %v", processorLogPrefix, err)
+	}
+}
+
+// GetAdminStatus queries the admin API of the processor at addr for its status.
+func GetAdminStatus(addr string) (*AdminStatus, error) {
+	status := &AdminStatus{}
+	if err := getAdmin(addr, "/status", status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// GetAdminClients queries the admin API of the processor at addr for the connected clients.
+func GetAdminClients(addr string) ([]AdminClient, error) {
+	clients := make([]AdminClient, 0)
+	if err := getAdmin(addr, "/clients", &clients); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+func getAdmin(addr string, path string, v interface{}) error {
+	client := &http.Client{Timeout: 5 * time.Second}
+	res, err := client.Get(fmt.Sprintf("http://%s%s", addr, path))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("admin API %s responds %s", path, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,59 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminAPI(t *testing.T) {
+	z := createProcessorServer("admin-processor", NewOptions(WithProcessorAddr("localhost:9142")))
+	admin := newAdminServer("localhost:9143", z)
+
+	// status
+	rec := httptest.NewRecorder()
+	admin.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	status := &AdminStatus{}
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), status))
+	assert.Equal(t, "admin-processor", status.Name)
+	assert.Equal(t, "localhost:9142", status.Addr)
+	assert.Equal(t, 0, status.Connections)
+
+	// clients
+	z.server.Connector().LinkApp("conn-1", "app-1", "sfn-1", []byte{0x33})
+	rec = httptest.NewRecorder()
+	admin.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/clients", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	clients := make([]AdminClient, 0)
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &clients))
+	assert.Equal(t, []AdminClient{{ConnID: "conn-1", AppID: "app-1", Name: "sfn-1", ObserveDataTags: []int{0x33}}}, clients)
+
+	// method not allowed
+	rec = httptest.NewRecorder()
+	admin.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/status", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,55 @@
+package auth
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/subtle"
+
+	"github.com/bhojpur/service/pkg/engine/core/auth"
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+)
+
+var _ auth.Authentication = (*AppKeyAuth)(nil)
+
+// AppKeyAuth authenticates the clients which use AppKeyCredential.
+type AppKeyAuth struct {
+	appID   string
+	payload []byte
+}
+
+// NewAppKeyAuth creates a server side AppKey authentication.
+func NewAppKeyAuth(appID string, appSecret string) *AppKeyAuth {
+	return &AppKeyAuth{
+		appID:   appID,
+		payload: NewAppKeyCredential(appID, appSecret).Payload(),
+	}
+}
+
+func (a *AppKeyAuth) Type() auth.AuthType {
+	return auth.AuthTypeAppKey
+}
+
+func (a *AppKeyAuth) Authenticate(f *frame.HandshakeFrame) bool {
+	if auth.AuthType(f.AuthType()) != auth.AuthTypeAppKey {
+		return false
+	}
+	return f.AppID() == a.appID && subtle.ConstantTimeCompare(f.AuthPayload(), a.payload) == 1
+}
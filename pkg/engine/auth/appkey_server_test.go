@@ -0,0 +1,43 @@
+package auth
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/bhojpur/service/pkg/engine/core/auth"
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppKeyAuth(t *testing.T) {
+	a := NewAppKeyAuth("app-id", "app-secret")
+	assert.Equal(t, auth.AuthTypeAppKey, a.Type())
+
+	handshake := func(cred auth.Credential) *frame.HandshakeFrame {
+		return frame.NewHandshakeFrame("test", 0x5F, nil, cred.AppID(), byte(cred.Type()), cred.Payload())
+	}
+
+	assert.True(t, a.Authenticate(handshake(NewAppKeyCredential("app-id", "app-secret"))))
+	assert.False(t, a.Authenticate(handshake(NewAppKeyCredential("app-id", "wrong-secret"))))
+	assert.False(t, a.Authenticate(handshake(NewAppKeyCredential("other-id", "app-secret"))))
+	assert.False(t, a.Authenticate(handshake(auth.NewCredendialNone())))
+}
@@ -0,0 +1,261 @@
+package codec
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+
+	"github.com/bhojpur/service/pkg/engine/codec/internal/utils"
+	"github.com/bhojpur/service/pkg/utils/encoding"
+)
+
+// ErrNotPrimitive is returned when reading the value of a node packet as primitive.
+var ErrNotPrimitive = errors.New("codec: not a primitive packet")
+
+// Accessor reads a packet in place, unlike DecodeNodePacket and DecodePrimitivePacket, it
+// neither copies the buffer nor allocates the packets, the children are decoded lazily when
+// navigating to them. Accessor is only valid while the underlying buffer is not modified.
+type Accessor struct {
+	buf []byte
+	// start of value in buf
+	start int
+	// pooled is the buffer taken from pool by PacketReader
+	pooled *[]byte
+}
+
+// NewAccessor creates the Accessor of the packet at the beginning of buf.
+func NewAccessor(buf []byte) (Accessor, error) {
+	if len(buf) < 2 {
+		return Accessor{}, ErrTruncated
+	}
+	var length int32
+	codec := encoding.VarCodec{}
+	if err := codec.DecodePVarInt32(buf[1:], &length); err != nil || length < 0 {
+		return Accessor{}, ErrTruncated
+	}
+	start := 1 + codec.Size
+	end := start + int(length)
+	if end > len(buf) {
+		return Accessor{}, ErrTruncated
+	}
+	return Accessor{buf: buf[:end], start: start}, nil
+}
+
+// Valid returns false if the Accessor is not found or not created.
+func (a Accessor) Valid() bool {
+	return len(a.buf) > 0
+}
+
+// Key returns the sequence ID of tag.
+func (a Accessor) Key() byte {
+	return a.buf[0] & utils.DropMSBArrayFlag
+}
+
+// IsNode returns true if the packet is a node.
+func (a Accessor) IsNode() bool {
+	return a.buf[0]&utils.MSB == utils.MSB
+}
+
+// IsSlice returns true if the packet is a slice node.
+func (a Accessor) IsSlice() bool {
+	return a.buf[0]&utils.SliceFlag == utils.SliceFlag
+}
+
+// Raw returns the whole packet, including tag and length.
+func (a Accessor) Raw() []byte {
+	return a.buf
+}
+
+// Bytes returns the value of packet in place, it's the encoded children for node.
+func (a Accessor) Bytes() []byte {
+	return a.buf[a.start:]
+}
+
+// Child returns the first child of key, it's only found in node.
+func (a Accessor) Child(key byte) (Accessor, bool) {
+	if !a.Valid() || !a.IsNode() {
+		return Accessor{}, false
+	}
+	for pos := a.start; pos < len(a.buf); {
+		child, err := NewAccessor(a.buf[pos:])
+		if err != nil {
+			return Accessor{}, false
+		}
+		if child.Key() == key {
+			return child, true
+		}
+		pos += len(child.buf)
+	}
+	return Accessor{}, false
+}
+
+// Find navigates to the nested packet by the path of keys, e.g., Find(0x30, 0x12) returns
+// the packet 0x12 in node 0x30.
+func (a Accessor) Find(path ...byte) (Accessor, bool) {
+	ok := a.Valid()
+	for _, key := range path {
+		if a, ok = a.Child(key); !ok {
+			return Accessor{}, false
+		}
+	}
+	return a, ok
+}
+
+// Index returns the ith child of node, e.g., the ith item of slice.
+func (a Accessor) Index(i int) (Accessor, bool) {
+	if !a.Valid() || !a.IsNode() || i < 0 {
+		return Accessor{}, false
+	}
+	for pos := a.start; pos < len(a.buf); i-- {
+		child, err := NewAccessor(a.buf[pos:])
+		if err != nil {
+			return Accessor{}, false
+		}
+		if i == 0 {
+			return child, true
+		}
+		pos += len(child.buf)
+	}
+	return Accessor{}, false
+}
+
+// Len returns the number of children of node, 0 for primitive.
+func (a Accessor) Len() int {
+	n := 0
+	a.Range(func(Accessor) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Range calls fn for the children of node in order, until fn returns false.
+func (a Accessor) Range(fn func(child Accessor) bool) {
+	if !a.Valid() || !a.IsNode() {
+		return
+	}
+	for pos := a.start; pos < len(a.buf); {
+		child, err := NewAccessor(a.buf[pos:])
+		if err != nil || !fn(child) {
+			return
+		}
+		pos += len(child.buf)
+	}
+}
+
+// primitive returns the value of primitive packet.
+func (a Accessor) primitive() ([]byte, error) {
+	if !a.Valid() || a.IsNode() {
+		return nil, ErrNotPrimitive
+	}
+	return a.buf[a.start:], nil
+}
+
+// Int32 reads the value as int32.
+func (a Accessor) Int32() (int32, error) {
+	var v int32
+	buf, err := a.primitive()
+	if err != nil {
+		return v, err
+	}
+	codec := encoding.VarCodec{}
+	err = codec.DecodePVarInt32(buf, &v)
+	return v, err
+}
+
+// UInt32 reads the value as uint32.
+func (a Accessor) UInt32() (uint32, error) {
+	var v uint32
+	buf, err := a.primitive()
+	if err != nil {
+		return v, err
+	}
+	codec := encoding.VarCodec{}
+	err = codec.DecodePVarUInt32(buf, &v)
+	return v, err
+}
+
+// Int64 reads the value as int64.
+func (a Accessor) Int64() (int64, error) {
+	var v int64
+	buf, err := a.primitive()
+	if err != nil {
+		return v, err
+	}
+	codec := encoding.VarCodec{}
+	err = codec.DecodePVarInt64(buf, &v)
+	return v, err
+}
+
+// UInt64 reads the value as uint64.
+func (a Accessor) UInt64() (uint64, error) {
+	var v uint64
+	buf, err := a.primitive()
+	if err != nil {
+		return v, err
+	}
+	codec := encoding.VarCodec{}
+	err = codec.DecodePVarUInt64(buf, &v)
+	return v, err
+}
+
+// Float32 reads the value as float32.
+func (a Accessor) Float32() (float32, error) {
+	var v float32
+	buf, err := a.primitive()
+	if err != nil {
+		return v, err
+	}
+	codec := encoding.VarCodec{Size: len(buf)}
+	err = codec.DecodeVarFloat32(buf, &v)
+	return v, err
+}
+
+// Float64 reads the value as float64.
+func (a Accessor) Float64() (float64, error) {
+	var v float64
+	buf, err := a.primitive()
+	if err != nil {
+		return v, err
+	}
+	codec := encoding.VarCodec{Size: len(buf)}
+	err = codec.DecodeVarFloat64(buf, &v)
+	return v, err
+}
+
+// Bool reads the value as bool.
+func (a Accessor) Bool() (bool, error) {
+	var v bool
+	buf, err := a.primitive()
+	if err != nil {
+		return v, err
+	}
+	codec := encoding.VarCodec{Size: len(buf)}
+	err = codec.DecodePVarBool(buf, &v)
+	return v, err
+}
+
+// ToUTF8String reads the value as string, which is a copy of the value. Use Bytes to read the
+// value in place.
+func (a Accessor) ToUTF8String() (string, error) {
+	buf, err := a.primitive()
+	return string(buf), err
+}
@@ -0,0 +1,186 @@
+package codec
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bhojpur/service/pkg/engine/codec/internal/tester"
+)
+
+func TestAccessor(t *testing.T) {
+	input := exampleData{
+		Name:  "bhojpur",
+		Noise: float32(456),
+		Therm: thermometer{Temperature: float32(30), Humidity: float32(40)},
+	}
+	buf, err := NewCodec(0x30).Marshal(input)
+	assert.NoError(t, err)
+
+	root, err := NewAccessor(buf)
+	assert.NoError(t, err)
+	assert.True(t, root.IsNode())
+	assert.Equal(t, byte(0x01), root.Key())
+
+	name, ok := root.Find(0x30, 0x10)
+	assert.True(t, ok)
+	v, err := name.ToUTF8String()
+	assert.NoError(t, err)
+	assert.Equal(t, "bhojpur", v)
+	assert.Equal(t, []byte("bhojpur"), name.Bytes())
+
+	temperature, ok := root.Find(0x30, 0x12, 0x13)
+	assert.True(t, ok)
+	f, err := temperature.Float32()
+	assert.NoError(t, err)
+	assert.Equal(t, float32(30), f)
+
+	_, ok = root.Find(0x30, 0x20)
+	assert.False(t, ok)
+
+	observed, _ := root.Child(0x30)
+	assert.Equal(t, 3, observed.Len())
+	_, err = observed.Int32()
+	assert.Equal(t, ErrNotPrimitive, err)
+
+	// the observed packet is the same as Observable gives
+	var mold exampleData
+	assert.NoError(t, ToObject(observed.Raw(), &mold))
+	assert.Equal(t, input, mold)
+}
+
+func TestAccessorSlice(t *testing.T) {
+	input := tester.SliceTestData{
+		Vfoo:        "foo",
+		Vint64Slice: []int64{-1, 1 << 40},
+		Vbar:        []string{"a", "b"},
+	}
+	buf, err := NewCodec(0x30).Marshal(input)
+	assert.NoError(t, err)
+
+	root, err := NewAccessor(buf)
+	assert.NoError(t, err)
+	slice, ok := root.Find(0x30, 0x33)
+	assert.True(t, ok)
+	assert.True(t, slice.IsSlice())
+	assert.Equal(t, 2, slice.Len())
+
+	item, ok := slice.Index(1)
+	assert.True(t, ok)
+	v, err := item.Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1<<40), v)
+
+	_, ok = slice.Index(2)
+	assert.False(t, ok)
+
+	var items []string
+	bar, _ := root.Find(0x30, 0x31)
+	bar.Range(func(child Accessor) bool {
+		s, _ := child.ToUTF8String()
+		items = append(items, s)
+		return true
+	})
+	assert.Equal(t, input.Vbar, items)
+}
+
+func TestAccessorTruncated(t *testing.T) {
+	buf, _ := NewCodec(0x30).Marshal(exampleData{Name: "bhojpur"})
+	_, err := NewAccessor(buf[:len(buf)-1])
+	assert.Equal(t, ErrTruncated, err)
+
+	// the corrupted children are not found
+	root, _ := NewAccessor(buf)
+	corrupted := append([]byte{}, buf...)
+	corrupted[3] = 0x7f
+	root.buf = corrupted
+	_, ok := root.Find(0x30, 0x10)
+	assert.False(t, ok)
+}
+
+func TestPacketReader(t *testing.T) {
+	var stream bytes.Buffer
+	for _, name := range []string{"a", "b", "c"} {
+		buf, _ := NewCodec(0x30).Marshal(exampleData{Name: name, Noise: 1})
+		stream.Write(buf)
+	}
+
+	reader := NewPacketReader(&stream)
+	var names []string
+	for {
+		a, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		name, ok := a.Find(0x30, 0x10)
+		assert.True(t, ok)
+		v, _ := name.ToUTF8String()
+		names = append(names, v)
+		a.Release()
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+
+	buf, _ := NewCodec(0x30).Marshal(exampleData{Name: "a"})
+	_, err := NewPacketReader(bytes.NewReader(buf[:len(buf)-1])).Read()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestPacketReader_TooLarge(t *testing.T) {
+	buf, _ := NewCodec(0x30).Marshal(exampleData{Name: "a"})
+	reader := NewPacketReader(bytes.NewReader(buf))
+	reader.MaxSize = len(buf) - 1
+	_, err := reader.Read()
+	assert.Equal(t, ErrPacketTooLarge, err)
+
+	// a header of ~2 GiB length is rejected before the payload arrives
+	_, err = NewPacketReader(bytes.NewReader([]byte{0x30, 0x87, 0xff, 0xff, 0xff, 0x7f})).Read()
+	assert.Equal(t, ErrPacketTooLarge, err)
+}
+
+func TestAccessorAllocations(t *testing.T) {
+	buf, _ := NewCodec(0x30).Marshal(exampleData{
+		Name:  "bhojpur",
+		Noise: float32(456),
+		Therm: thermometer{Temperature: float32(30), Humidity: float32(40)},
+	})
+	allocs := testing.AllocsPerRun(100, func() {
+		root, _ := NewAccessor(buf)
+		temperature, _ := root.Find(0x30, 0x12, 0x13)
+		_, _ = temperature.Float32()
+	})
+	assert.Equal(t, float64(0), allocs)
+
+	stream := bytes.NewReader(nil)
+	reader := NewPacketReader(stream)
+	allocs = testing.AllocsPerRun(100, func() {
+		stream.Reset(buf)
+		a, _ := reader.Read()
+		humidity, _ := a.Find(0x30, 0x12, 0x14)
+		_, _ = humidity.Float32()
+		a.Release()
+	})
+	assert.Equal(t, float64(0), allocs)
+}
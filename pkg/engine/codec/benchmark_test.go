@@ -0,0 +1,172 @@
+package codec
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/service/pkg/engine/codec/internal/tester"
+)
+
+func BenchmarkMarshalStruct(b *testing.B) {
+	input := newBasic()
+	codec := NewCodec(0x30)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = codec.Marshal(input)
+	}
+}
+
+func BenchmarkMarshalMap(b *testing.B) {
+	input := newMapData()
+	codec := NewCodec(0x30)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = codec.Marshal(input)
+	}
+}
+
+func BenchmarkMarshalTime(b *testing.B) {
+	input := newTimeData()
+	codec := NewCodec(0x30)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = codec.Marshal(input)
+	}
+}
+
+func BenchmarkToObjectStruct(b *testing.B) {
+	buf, _ := NewCodec(0x30).Marshal(newBasic())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var mold tester.BasicTestData
+		_ = ToObject(buf, &mold)
+	}
+}
+
+func BenchmarkToObjectMap(b *testing.B) {
+	buf, _ := NewCodec(0x30).Marshal(newMapData())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var mold tester.MapTestData
+		_ = ToObject(buf, &mold)
+	}
+}
+
+func BenchmarkToObjectTime(b *testing.B) {
+	buf, _ := NewCodec(0x30).Marshal(newTimeData())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var mold tester.TimeTestData
+		_ = ToObject(buf, &mold)
+	}
+}
+
+func newMapData() tester.MapTestData {
+	return tester.MapTestData{
+		Vfoo:    "foo",
+		Vtags:   map[string]string{"a": "1", "b": "2", "c": "3"},
+		Vcounts: map[string]int64{"x": 1, "y": 2},
+		Vbasics: map[string]tester.BasicTestData{"basic": newBasic()},
+	}
+}
+
+func newTimeData() tester.TimeTestData {
+	now := time.Now()
+	return tester.TimeTestData{
+		Vtime:      now,
+		Vduration:  time.Second,
+		Vtimes:     []time.Time{now, now},
+		Vdurations: []time.Duration{time.Second, time.Minute},
+		Vint:       big.NewInt(1 << 62),
+		Vfloat:     big.NewFloat(1.5),
+		Vrat:       big.NewRat(1, 3),
+	}
+}
+
+func newAccessorBuffer() []byte {
+	buf, _ := NewCodec(0x30).Marshal(exampleData{
+		Name:  "bhojpur",
+		Noise: float32(456),
+		Therm: thermometer{Temperature: float32(30), Humidity: float32(40)},
+	})
+	return buf
+}
+
+func BenchmarkDecodeNodePacketFind(b *testing.B) {
+	buf := newAccessorBuffer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		root, _, _ := DecodeNodePacket(buf)
+		for _, observed := range root.NodePackets {
+			if observed.SeqID() != 0x30 {
+				continue
+			}
+			for _, therm := range observed.NodePackets {
+				if therm.SeqID() != 0x12 {
+					continue
+				}
+				for _, p := range therm.PrimitivePackets {
+					if p.SeqID() == 0x13 {
+						_, _ = p.ToFloat32()
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkToObjectFind(b *testing.B) {
+	buf := newAccessorBuffer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var mold exampleData
+		_ = ToObject(buf, &mold)
+		_ = mold.Therm.Temperature
+	}
+}
+
+func BenchmarkAccessorFind(b *testing.B) {
+	buf := newAccessorBuffer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		root, _ := NewAccessor(buf)
+		temperature, _ := root.Find(0x30, 0x12, 0x13)
+		_, _ = temperature.Float32()
+	}
+}
+
+func BenchmarkPacketReader(b *testing.B) {
+	buf := newAccessorBuffer()
+	stream := bytes.NewReader(nil)
+	reader := NewPacketReader(stream)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		stream.Reset(buf)
+		a, _ := reader.Read()
+		temperature, _ := a.Find(0x30, 0x12, 0x13)
+		_, _ = temperature.Float32()
+		a.Release()
+	}
+}
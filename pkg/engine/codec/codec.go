@@ -26,6 +26,12 @@ import (
 	"github.com/bhojpur/service/pkg/engine/codec/internal/utils"
 )
 
+// RootToken is the key of root node which wraps the observed data.
+const RootToken = utils.RootToken
+
+// KeyOfSliceItem is the key of items in a slice node.
+const KeyOfSliceItem = utils.KeyOfSliceItem
+
 // Codec encode the user's data according to the Bhojpur Service encoding rules
 type Codec interface {
 	// Marshal encode interface to []byte
@@ -22,8 +22,10 @@ package codec
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/bhojpur/service/pkg/engine/codec/internal/utils"
 
@@ -357,6 +359,36 @@ func TestMarshalObjectSliceNil(t *testing.T) {
 	}
 }
 
+func TestMarshalMapAndTime(t *testing.T) {
+	flag := false
+	input := exampleEvent{
+		At:      time.Date(2021, 10, 12, 8, 30, 0, 0, time.UTC),
+		Elapsed: 1500 * time.Millisecond,
+		Tags:    map[string]string{"room": "a", "floor": "1"},
+		Amount:  big.NewRat(1999, 100),
+	}
+
+	codec := NewCodec(0x30)
+	inputBuf, err := codec.Marshal(input)
+	assert.NoError(t, err)
+
+	testDecoder(0x30, inputBuf, func(v []byte) (interface{}, error) {
+		flag = true
+		var mold exampleEvent
+		err := ToObject(v, &mold)
+		assert.NoError(t, err, fmt.Sprintf("decode error:%v", err))
+		assert.Equal(t, input.At, mold.At)
+		assert.Equal(t, input.Elapsed, mold.Elapsed)
+		assert.Equal(t, input.Tags, mold.Tags)
+		assert.Equal(t, "19.99", mold.Amount.FloatString(2))
+		return mold, err
+	})
+
+	if !flag {
+		t.Error("The key 0x30 is not observed")
+	}
+}
+
 type exampleData struct {
 	Name  string      `bhojpur:"0x10"`
 	Noise float32     `bhojpur:"0x11"`
@@ -368,6 +400,13 @@ type thermometer struct {
 	Humidity    float32 `bhojpur:"0x14"`
 }
 
+type exampleEvent struct {
+	At      time.Time         `bhojpur:"0x10"`
+	Elapsed time.Duration     `bhojpur:"0x11"`
+	Tags    map[string]string `bhojpur:"0x12"`
+	Amount  *big.Rat          `bhojpur:"0x13"`
+}
+
 type exampleSlice struct {
 	Therms []thermometer `bhojpur:"0x12"`
 }
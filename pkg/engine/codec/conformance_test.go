@@ -0,0 +1,410 @@
+package codec
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The conformance vectors are shared by the implementations of codec in other languages, see
+// "Conformance Vectors" of README.md for the format. Run `go test -run TestConformance -update`
+// to regenerate them after adding vectors to conformanceVectors.
+var updateConformance = flag.Bool("update", false, "regenerate the conformance vectors")
+
+const conformanceFile = "testdata/conformance/v1.json"
+
+type conformanceSuite struct {
+	Version     int                 `json:"version"`
+	Description string              `json:"description"`
+	Vectors     []conformanceVector `json:"vectors"`
+}
+
+type conformanceVector struct {
+	Name   string             `json:"name"`
+	Packet *conformancePacket `json:"packet,omitempty"`
+	Hex    string             `json:"hex"`
+	// Error is the kind of error when decoding hex, the vectors with error have no packet.
+	Error string `json:"error,omitempty"`
+}
+
+type conformancePacket struct {
+	Key      byte                `json:"key"`
+	Type     string              `json:"type"`
+	Value    json.RawMessage     `json:"value,omitempty"`
+	Children []conformancePacket `json:"children,omitempty"`
+}
+
+func primitive(key byte, typ string, value interface{}) conformancePacket {
+	switch v := value.(type) {
+	case int64:
+		// 64 bits integers are strings, since they can't be represented by numbers in JSON
+		value = strconv.FormatInt(v, 10)
+	case uint64:
+		value = strconv.FormatUint(v, 10)
+	case []byte:
+		value = hex.EncodeToString(v)
+	case float32:
+		value = floatValue(float64(v))
+	case float64:
+		value = floatValue(v)
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return conformancePacket{Key: key, Type: typ, Value: raw}
+}
+
+func floatValue(v float64) interface{} {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return v
+}
+
+func node(key byte, children ...conformancePacket) conformancePacket {
+	return conformancePacket{Key: key, Type: "node", Children: children}
+}
+
+func slice(key byte, children ...conformancePacket) conformancePacket {
+	return conformancePacket{Key: key, Type: "slice", Children: children}
+}
+
+// conformanceVectors are the source of conformance vectors.
+func conformanceVectors() []conformanceVector {
+	var vectors []conformanceVector
+	add := func(name string, p conformancePacket) {
+		vectors = append(vectors, conformanceVector{Name: name, Packet: &p})
+	}
+
+	for _, v := range []int32{0, 1, -1, 63, 64, -64, -65, 127, 128, 8191, 8192, -8193, math.MaxInt32, math.MinInt32} {
+		add(fmt.Sprintf("int32/%d", v), primitive(0x10, "int32", v))
+	}
+	for _, v := range []int64{0, -1, 1 << 31, -1 << 31, 1 << 53, math.MaxInt64, math.MinInt64} {
+		add(fmt.Sprintf("int64/%d", v), primitive(0x10, "int64", v))
+	}
+	for _, v := range []uint32{0, 1, 63, 64, 127, 128, math.MaxUint32} {
+		add(fmt.Sprintf("uint32/%d", v), primitive(0x10, "uint32", v))
+	}
+	for _, v := range []uint64{0, 1 << 32, 1 << 63, math.MaxUint64} {
+		add(fmt.Sprintf("uint64/%d", v), primitive(0x10, "uint64", v))
+	}
+	for _, v := range []float32{0, 1, -1.5, 0.25, 3.4028235e38, 1e-45, float32(math.Inf(1)), float32(math.Inf(-1))} {
+		add(fmt.Sprintf("float32/%v", v), primitive(0x10, "float32", v))
+	}
+	for _, v := range []float64{0, 1, -1.5, 0.1, math.MaxFloat64, 5e-324, math.Inf(1)} {
+		add(fmt.Sprintf("float64/%v", v), primitive(0x10, "float64", v))
+	}
+	add("bool/true", primitive(0x10, "bool", true))
+	add("bool/false", primitive(0x10, "bool", false))
+	add("string/empty", primitive(0x10, "string", ""))
+	add("string/ascii", primitive(0x10, "string", "bhojpur"))
+	add("string/utf8", primitive(0x10, "string", "भोजपुर 😀"))
+	add("string/length-63", primitive(0x10, "string", strings.Repeat("a", 63)))
+	add("string/length-64", primitive(0x10, "string", strings.Repeat("a", 64)))
+	add("string/length-8192", primitive(0x10, "string", strings.Repeat("b", 8192)))
+	add("bytes/binary", primitive(0x10, "bytes", []byte{0x00, 0x01, 0x7f, 0x80, 0xff}))
+	for _, key := range []byte{0x00, 0x01, 0x0f, 0x10, 0x3f} {
+		add(fmt.Sprintf("key/%#02x", key), primitive(key, "int32", int32(1)))
+	}
+
+	add("node/empty", node(0x10))
+	add("node/primitives", node(0x01,
+		primitive(0x02, "int32", int32(-1)),
+		primitive(0x03, "string", "C"),
+	))
+	add("node/nested", node(0x01,
+		primitive(0x02, "int32", int32(-1)),
+		node(0x03, primitive(0x04, "string", "C")),
+	))
+	add("node/deep", node(0x01, node(0x3a, node(0x3b, node(0x3c, primitive(0x3d, "float32", float32(1.5)))))))
+	add("node/duplicate-keys", node(0x10,
+		primitive(0x11, "int32", int32(1)),
+		primitive(0x11, "int32", int32(2)),
+	))
+	add("slice/empty", slice(0x10))
+	add("slice/int32", slice(0x10,
+		primitive(0x00, "int32", int32(-1)),
+		primitive(0x00, "int32", int32(127)),
+	))
+	add("slice/string", slice(0x10,
+		primitive(0x00, "string", "a"),
+		primitive(0x00, "string", "b"),
+	))
+	add("slice/node", slice(0x10,
+		node(0x00, primitive(0x13, "float32", float32(30)), primitive(0x14, "float32", float32(40))),
+		node(0x00, primitive(0x13, "float32", float32(50)), primitive(0x14, "float32", float32(60))),
+	))
+	// the data observed by 0x30 with signals 0x02 and 0x03 in the root node
+	add("signal/observed", node(0x01,
+		primitive(0x02, "string", "a"),
+		primitive(0x03, "int64", int64(-1)),
+		node(0x30, primitive(0x10, "string", "bhojpur")),
+	))
+
+	errors := []struct {
+		name, hex, err string
+	}{
+		{"error/empty-length", "10", "truncated"},
+		{"error/unterminated-length", "10ff", "truncated"},
+		{"error/primitive-truncated", "100301", "truncated"},
+		{"error/node-truncated", "81041001", "truncated"},
+		{"error/child-truncated", "8103100501", "truncated"},
+	}
+	for _, e := range errors {
+		vectors = append(vectors, conformanceVector{Name: e.name, Hex: e.hex, Error: e.err})
+	}
+	return vectors
+}
+
+// encodeConformance encodes the packet by the encoders.
+func encodeConformance(p conformancePacket) ([]byte, error) {
+	switch p.Type {
+	case "node", "slice":
+		var n *NodePacketEncoder
+		if p.Type == "node" {
+			n = NewNodePacketEncoder(int(p.Key))
+		} else {
+			n = NewNodeSlicePacketEncoder(int(p.Key))
+		}
+		for _, child := range p.Children {
+			buf, err := encodeConformance(child)
+			if err != nil {
+				return nil, err
+			}
+			n.AddBytes(buf)
+		}
+		return n.Encode(), nil
+	}
+
+	e := NewPrimitivePacketEncoder(int(p.Key))
+	var s string
+	switch p.Type {
+	case "int64", "uint64", "bytes", "string":
+		if err := json.Unmarshal(p.Value, &s); err != nil {
+			return nil, err
+		}
+	}
+	switch p.Type {
+	case "int32":
+		var v int32
+		if err := json.Unmarshal(p.Value, &v); err != nil {
+			return nil, err
+		}
+		e.SetInt32Value(v)
+	case "uint32":
+		var v uint32
+		if err := json.Unmarshal(p.Value, &v); err != nil {
+			return nil, err
+		}
+		e.SetUInt32Value(v)
+	case "int64":
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		e.SetInt64Value(v)
+	case "uint64":
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		e.SetUInt64Value(v)
+	case "float32":
+		v, err := parseConformanceFloat(p.Value, 32)
+		if err != nil {
+			return nil, err
+		}
+		e.SetFloat32Value(float32(v))
+	case "float64":
+		v, err := parseConformanceFloat(p.Value, 64)
+		if err != nil {
+			return nil, err
+		}
+		e.SetFloat64Value(v)
+	case "bool":
+		var v bool
+		if err := json.Unmarshal(p.Value, &v); err != nil {
+			return nil, err
+		}
+		e.SetBoolValue(v)
+	case "string":
+		e.SetStringValue(s)
+	case "bytes":
+		v, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		e.SetBytesValue(v)
+	default:
+		return nil, fmt.Errorf("unknown type %s", p.Type)
+	}
+	return e.Encode(), nil
+}
+
+func parseConformanceFloat(raw json.RawMessage, bits int) (float64, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		switch s {
+		case "NaN":
+			return math.NaN(), nil
+		case "+Inf":
+			return math.Inf(1), nil
+		case "-Inf":
+			return math.Inf(-1), nil
+		}
+		return 0, fmt.Errorf("invalid float %s", s)
+	}
+	return strconv.ParseFloat(string(raw), bits)
+}
+
+// checkConformance checks the packet read by Accessor matches p.
+func checkConformance(t *testing.T, name string, a Accessor, p conformancePacket) {
+	assert.Equal(t, p.Key, a.Key(), "%s: key", name)
+	switch p.Type {
+	case "node", "slice":
+		assert.True(t, a.IsNode(), "%s: node", name)
+		assert.Equal(t, p.Type == "slice", a.IsSlice(), "%s: slice", name)
+		assert.Equal(t, len(p.Children), a.Len(), "%s: children", name)
+		for i, child := range p.Children {
+			c, ok := a.Index(i)
+			if assert.True(t, ok, "%s: child %d", name, i) {
+				checkConformance(t, fmt.Sprintf("%s[%d]", name, i), c, child)
+			}
+		}
+		return
+	}
+
+	assert.False(t, a.IsNode(), "%s: primitive", name)
+	var (
+		actual interface{}
+		err    error
+	)
+	switch p.Type {
+	case "int32":
+		actual, err = a.Int32()
+	case "uint32":
+		actual, err = a.UInt32()
+	case "int64":
+		var v int64
+		v, err = a.Int64()
+		actual = strconv.FormatInt(v, 10)
+	case "uint64":
+		var v uint64
+		v, err = a.UInt64()
+		actual = strconv.FormatUint(v, 10)
+	case "float32":
+		var v float32
+		v, err = a.Float32()
+		actual = floatValue(float64(v))
+	case "float64":
+		var v float64
+		v, err = a.Float64()
+		actual = floatValue(v)
+	case "bool":
+		actual, err = a.Bool()
+	case "string":
+		actual, err = a.ToUTF8String()
+	case "bytes":
+		actual = hex.EncodeToString(a.Bytes())
+	}
+	assert.NoError(t, err, name)
+	raw, _ := json.Marshal(actual)
+	assert.JSONEq(t, string(p.Value), string(raw), "%s: value", name)
+}
+
+func TestConformance(t *testing.T) {
+	if *updateConformance {
+		suite := conformanceSuite{
+			Version:     1,
+			Description: "codec conformance vectors, see Conformance Vectors of pkg/engine/codec/README.md for the format",
+			Vectors:     conformanceVectors(),
+		}
+		for i, v := range suite.Vectors {
+			if v.Packet == nil {
+				continue
+			}
+			buf, err := encodeConformance(*v.Packet)
+			assert.NoError(t, err, v.Name)
+			suite.Vectors[i].Hex = hex.EncodeToString(buf)
+		}
+		data, err := json.MarshalIndent(suite, "", "  ")
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile(conformanceFile, append(data, '\n'), 0644))
+	}
+
+	data, err := ioutil.ReadFile(conformanceFile)
+	assert.NoError(t, err)
+	var suite conformanceSuite
+	assert.NoError(t, json.Unmarshal(data, &suite))
+	assert.Equal(t, 1, suite.Version)
+	assert.Len(t, suite.Vectors, len(conformanceVectors()), "the vectors are outdated, run with -update")
+
+	for _, v := range suite.Vectors {
+		buf, err := hex.DecodeString(v.Hex)
+		assert.NoError(t, err, v.Name)
+
+		if v.Error != "" {
+			if _, err := NewAccessor(buf); err != nil {
+				// the packet itself is malformed, the decoders must fail as well
+				if buf[0]&0x80 == 0x80 {
+					_, _, err = DecodeNodePacket(buf)
+				} else {
+					_, _, _, err = DecodePrimitivePacket(buf)
+				}
+				assert.Error(t, err, v.Name)
+			}
+			// DecodeNodePacket skips the malformed children, Inspect reports them
+			_, err := Inspect(buf)
+			assert.Error(t, err, v.Name)
+			continue
+		}
+
+		// encode
+		encoded, err := encodeConformance(*v.Packet)
+		assert.NoError(t, err, v.Name)
+		assert.Equal(t, v.Hex, hex.EncodeToString(encoded), "%s: encoded", v.Name)
+
+		// decode
+		a, err := NewAccessor(buf)
+		if assert.NoError(t, err, v.Name) {
+			checkConformance(t, v.Name, a, *v.Packet)
+		}
+		packets, err := Inspect(buf)
+		assert.NoError(t, err, v.Name)
+		assert.Len(t, packets, 1, v.Name)
+	}
+}
@@ -0,0 +1,84 @@
+//go:build go1.18
+// +build go1.18
+
+package codec
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+// FuzzDecode checks that decoding arbitrary input never panics, it is seeded by the
+// conformance vectors. Run `go test -fuzz FuzzDecode` to explore more input.
+func FuzzDecode(f *testing.F) {
+	data, err := ioutil.ReadFile(conformanceFile)
+	if err != nil {
+		f.Fatal(err)
+	}
+	var suite conformanceSuite
+	if err := json.Unmarshal(data, &suite); err != nil {
+		f.Fatal(err)
+	}
+	for _, v := range suite.Vectors {
+		buf, err := hex.DecodeString(v.Hex)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(buf)
+	}
+
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		if len(buf) > 0 && buf[0]&0x80 == 0x80 {
+			DecodeNodePacket(buf)
+		} else {
+			DecodePrimitivePacket(buf)
+		}
+		Inspect(buf)
+		if a, err := NewAccessor(buf); err == nil {
+			walkFuzzed(a)
+		}
+		newPathMatcher(Path{Wildcard, Wildcard}).feed(buf, func(PathValue) {})
+	})
+}
+
+func walkFuzzed(a Accessor) {
+	if !a.IsNode() {
+		a.Int32()
+		a.UInt32()
+		a.Int64()
+		a.UInt64()
+		a.Float32()
+		a.Float64()
+		a.Bool()
+		a.ToUTF8String()
+		return
+	}
+	a.Find(0x01, 0x10)
+	a.Index(a.Len())
+	a.Range(func(child Accessor) bool {
+		walkFuzzed(child)
+		return true
+	})
+}
@@ -0,0 +1,208 @@
+package codec
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bhojpur/service/pkg/engine/codec/internal/mark"
+	"github.com/bhojpur/service/pkg/utils/encoding"
+)
+
+// ErrTruncated is returned by Inspect when the length of packet exceeds the buffer.
+var ErrTruncated = errors.New("codec: truncated packet")
+
+// InspectedPacket is a packet decoded for human reading.
+type InspectedPacket struct {
+	// Tag is the raw tag, including the node and slice flags.
+	Tag byte
+	// Key is the sequence ID of tag.
+	Key    byte
+	Node   bool
+	Slice  bool
+	Length int
+	// Value is the raw value of primitive packet.
+	Value []byte
+	// Guesses are the possible values of primitive packet, since the type isn't encoded.
+	Guesses []Guess
+	// Packets are the children of node packet.
+	Packets []*InspectedPacket
+}
+
+// Guess is a possible value of primitive packet.
+type Guess struct {
+	Type  string
+	Value string
+}
+
+// Inspect decodes the consecutive packets in buf into a tree, the packets decoded before an
+// error are returned with the error.
+func Inspect(buf []byte) ([]*InspectedPacket, error) {
+	var packets []*InspectedPacket
+	for pos := 0; pos < len(buf); {
+		p, n, err := inspectPacket(buf[pos:])
+		if p != nil {
+			packets = append(packets, p)
+		}
+		if err != nil {
+			return packets, fmt.Errorf("%w at offset %d", err, pos+n)
+		}
+		pos += n
+	}
+	return packets, nil
+}
+
+// inspectPacket decodes a packet and returns the position after it.
+func inspectPacket(buf []byte) (*InspectedPacket, int, error) {
+	tag := mark.NewTag(buf[0])
+	p := &InspectedPacket{
+		Tag:   tag.Raw(),
+		Key:   tag.SeqID(),
+		Node:  tag.IsNode(),
+		Slice: tag.IsSlice(),
+	}
+	var length int32
+	codec := encoding.VarCodec{}
+	if err := codec.DecodePVarInt32(buf[1:], &length); err != nil || length < 0 {
+		return p, 1, ErrTruncated
+	}
+	p.Length = int(length)
+	start := 1 + codec.Size
+	end := start + p.Length
+	var truncated error
+	if end > len(buf) {
+		// inspect the available part
+		end, truncated = len(buf), ErrTruncated
+	}
+	if !p.Node {
+		p.Value = buf[start:end]
+		if truncated == nil {
+			p.Guesses = guessValues(p.Value)
+		}
+		return p, end, truncated
+	}
+	for pos := start; pos < end; {
+		child, n, err := inspectPacket(buf[pos:end])
+		if child != nil {
+			p.Packets = append(p.Packets, child)
+		}
+		if err != nil {
+			return p, pos + n, err
+		}
+		pos += n
+	}
+	return p, end, truncated
+}
+
+// guessValues decodes the value as every primitive type it may be.
+func guessValues(v []byte) []Guess {
+	var guesses []Guess
+	if len(v) > 0 && utf8.Valid(v) && isPrintable(string(v)) {
+		guesses = append(guesses, Guess{"string", strconv.Quote(string(v))})
+	}
+	if len(v) == 0 {
+		return guesses
+	}
+	var i64 int64
+	codec := encoding.VarCodec{}
+	if err := codec.DecodePVarInt64(v, &i64); err == nil && codec.Size == len(v) {
+		guesses = append(guesses, Guess{"int", strconv.FormatInt(i64, 10)})
+		var u64 uint64
+		codec = encoding.VarCodec{}
+		if err := codec.DecodePVarUInt64(v, &u64); err == nil && u64 != uint64(i64) {
+			guesses = append(guesses, Guess{"uint", strconv.FormatUint(u64, 10)})
+		}
+		if len(v) == 1 && (v[0] == 0 || v[0] == 1) {
+			guesses = append(guesses, Guess{"bool", strconv.FormatBool(v[0] == 1)})
+		}
+	}
+	if len(v) <= 4 {
+		var f32 float32
+		codec = encoding.VarCodec{Size: len(v)}
+		if err := codec.DecodeVarFloat32(v, &f32); err == nil && !math.IsNaN(float64(f32)) {
+			guesses = append(guesses, Guess{"float32", strconv.FormatFloat(float64(f32), 'g', -1, 32)})
+		}
+	}
+	if len(v) <= 8 {
+		var f64 float64
+		codec = encoding.VarCodec{Size: len(v)}
+		if err := codec.DecodeVarFloat64(v, &f64); err == nil && !math.IsNaN(f64) {
+			guesses = append(guesses, Guess{"float64", strconv.FormatFloat(f64, 'g', -1, 64)})
+		}
+	}
+	return guesses
+}
+
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatInspected formats the packets as an indented tree, e.g.,
+//
+//	0x81 node key=0x01 len=8
+//	  0x90 node key=0x10 len=6
+//	    0x11 primitive key=0x11 len=4 [0x42 0x20 0x00 0x00] float32=40 ...
+func FormatInspected(packets []*InspectedPacket) string {
+	var b strings.Builder
+	for _, p := range packets {
+		formatInspected(&b, p, 0)
+	}
+	return b.String()
+}
+
+func formatInspected(b *strings.Builder, p *InspectedPacket, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	kind := "primitive"
+	if p.Slice {
+		kind = "slice"
+	} else if p.Node {
+		kind = "node"
+	}
+	fmt.Fprintf(b, "%#02x %s key=%#02x len=%d", p.Tag, kind, p.Key, p.Length)
+	if !p.Node {
+		b.WriteString(" [")
+		for i, c := range p.Value {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(b, "%#02x", c)
+		}
+		b.WriteString("]")
+		for _, g := range p.Guesses {
+			fmt.Fprintf(b, " %s=%s", g.Type, g.Value)
+		}
+	}
+	b.WriteString("\n")
+	for _, child := range p.Packets {
+		formatInspected(b, child, depth+1)
+	}
+}
@@ -0,0 +1,81 @@
+package codec
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspect(t *testing.T) {
+	input := thermometer{Temperature: float32(30), Humidity: float32(40)}
+	buf, err := NewCodec(0x12).Marshal(input)
+	assert.NoError(t, err)
+
+	packets, err := Inspect(buf)
+	assert.NoError(t, err)
+	assert.Len(t, packets, 1)
+
+	root := packets[0]
+	assert.True(t, root.Node)
+	assert.Equal(t, byte(0x01), root.Key)
+	assert.Len(t, root.Packets, 1)
+
+	observed := root.Packets[0]
+	assert.Equal(t, byte(0x12), observed.Key)
+	assert.Len(t, observed.Packets, 2)
+	assert.False(t, observed.Packets[0].Node)
+	assert.Contains(t, observed.Packets[0].Guesses, Guess{"float32", "30"})
+
+	assert.Equal(t, `0x81 node key=0x01 len=10
+  0x92 node key=0x12 len=8
+    0x13 primitive key=0x13 len=2 [0x41 0xf0] float32=30 float64=4.294967296e+09
+    0x14 primitive key=0x14 len=2 [0x42 0x20] string="B " float32=40 float64=3.4359738368e+10
+`, FormatInspected(packets))
+}
+
+func TestInspectGuesses(t *testing.T) {
+	p := NewPrimitivePacketEncoder(0x10)
+	p.SetStringValue("hello")
+	packets, err := Inspect(p.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, Guess{"string", `"hello"`}, packets[0].Guesses[0])
+
+	p = NewPrimitivePacketEncoder(0x10)
+	p.SetInt64Value(-1)
+	packets, err = Inspect(p.Encode())
+	assert.NoError(t, err)
+	assert.Contains(t, packets[0].Guesses, Guess{"int", "-1"})
+}
+
+func TestInspectTruncated(t *testing.T) {
+	input := thermometer{Temperature: float32(30), Humidity: float32(40)}
+	buf, err := NewCodec(0x12).Marshal(input)
+	assert.NoError(t, err)
+
+	packets, err := Inspect(buf[:len(buf)-1])
+	assert.True(t, errors.Is(err, ErrTruncated))
+	assert.Len(t, packets, 1)
+	assert.Len(t, packets[0].Packets[0].Packets, 2)
+	assert.Equal(t, []byte{0x42}, packets[0].Packets[0].Packets[1].Value)
+}
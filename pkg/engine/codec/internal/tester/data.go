@@ -20,6 +20,11 @@ package tester
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
 // THE SOFTWARE.
 
+import (
+	"math/big"
+	"time"
+)
+
 // BasicTestData is data of basic test
 type BasicTestData struct {
 	Vstring  string  `bhojpur:"0x10"`
@@ -115,3 +120,28 @@ type Sub2NestedTestData struct {
 type Sub3NestedTestData struct {
 	BasicList []BasicTestData `bhojpur:"0x3d"`
 }
+
+// MapTestData is data of map test
+type MapTestData struct {
+	Vfoo       string                     `bhojpur:"0x10"`
+	Vtags      map[string]string          `bhojpur:"0x11"`
+	Vcounts    map[string]int64           `bhojpur:"0x12"`
+	Vbasics    map[string]BasicTestData   `bhojpur:"0x13"`
+	Vtimes     map[string]time.Time       `bhojpur:"0x14"`
+	Vempty     map[string]float32         `bhojpur:"0x15"`
+	Vslices    map[string][]string        `bhojpur:"0x16"`
+	Vnestedmap map[string]map[string]bool `bhojpur:"0x17"`
+}
+
+// TimeTestData is data of time and decimal test
+type TimeTestData struct {
+	Vtime      time.Time       `bhojpur:"0x10"`
+	Vzero      time.Time       `bhojpur:"0x11"`
+	Vduration  time.Duration   `bhojpur:"0x12"`
+	Vtimes     []time.Time     `bhojpur:"0x13"`
+	Vdurations []time.Duration `bhojpur:"0x14"`
+	Vint       *big.Int        `bhojpur:"0x15"`
+	Vfloat     *big.Float      `bhojpur:"0x16"`
+	Vrat       *big.Rat        `bhojpur:"0x17"`
+	Vnil       *big.Rat        `bhojpur:"0x18"`
+}
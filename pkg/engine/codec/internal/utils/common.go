@@ -20,7 +20,11 @@ package utils
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
 // THE SOFTWARE.
 
-import "reflect"
+import (
+	"math/big"
+	"reflect"
+	"time"
+)
 
 // MSB `1000 0000`
 const MSB byte = 0x80
@@ -40,6 +44,15 @@ const KeyOfSliceItem = 0x00
 // KeyStringOfSliceItem
 const KeyStringOfSliceItem = "0x00"
 
+// KeyOfMapKey TLV sid of the key in map entry
+const KeyOfMapKey = 0x10
+
+// KeyOfMapValue TLV sid of the value in map entry
+const KeyOfMapValue = 0x11
+
+// KeyStringOfMapValue
+const KeyStringOfMapValue = "0x11"
+
 // RootToken
 const RootToken byte = 0x01
 
@@ -69,3 +82,15 @@ var TypeOfFloat64Slice = reflect.TypeOf([]float64{})
 
 // TypeOfBoolSlice Type of []bool{}
 var TypeOfBoolSlice = reflect.TypeOf([]bool{})
+
+// TypeOfTime Type of time.Time{}
+var TypeOfTime = reflect.TypeOf(time.Time{})
+
+// TypeOfBigInt Type of *big.Int
+var TypeOfBigInt = reflect.TypeOf((*big.Int)(nil))
+
+// TypeOfBigFloat Type of *big.Float
+var TypeOfBigFloat = reflect.TypeOf((*big.Float)(nil))
+
+// TypeOfBigRat Type of *big.Rat
+var TypeOfBigRat = reflect.TypeOf((*big.Rat)(nil))
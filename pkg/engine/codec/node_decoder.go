@@ -22,6 +22,7 @@ package codec
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/bhojpur/service/pkg/engine/codec/internal/mark"
 	"github.com/bhojpur/service/pkg/utils/encoding"
@@ -79,6 +80,9 @@ func DecodeNodePacket(buf []byte) (pct *NodePacket, endPos int, err error) {
 	// `raw` is pct.Length() length
 	vl := int(vallen)
 	endPos = pos + vl
+	if vl < 0 || endPos > len(buf) {
+		return nil, 0, fmt.Errorf("beyond the boundary, pos=%v, endPos=%v", pos, endPos)
+	}
 	pct.basePacket.valBuf = make([]byte, vl)
 	copy(pct.basePacket.valBuf, buf[pos:endPos])
 
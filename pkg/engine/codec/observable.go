@@ -36,7 +36,9 @@ type Iterable interface {
 type Observable interface {
 	Iterable
 	Subscribe(key byte) Observable
+	SubscribePath(path ...PathSegment) Observable
 	OnObserve(function func(v []byte) (interface{}, error)) chan interface{}
+	OnObservePath(function func(path Path, v []byte) (interface{}, error)) chan interface{}
 }
 
 type observableImpl struct {
@@ -133,8 +135,7 @@ func (o *observableImpl) OnObserve(function func(v []byte) (interface{}, error))
 				if !ok {
 					return
 				}
-				buf := item.([]byte)
-				value, err := function(buf)
+				value, err := function(bytesOf(item))
 				if err != nil {
 					return
 				}
@@ -149,6 +150,56 @@ func (o *observableImpl) OnObserve(function func(v []byte) (interface{}, error))
 	return _next
 }
 
+//Processing callback function with the path of value, which is nil if the value is not from SubscribePath
+func (o *observableImpl) OnObservePath(function func(path Path, v []byte) (interface{}, error)) chan interface{} {
+	_next := make(chan interface{})
+
+	f := func(next chan interface{}) {
+		defer close(next)
+
+		for item := range o.Observe() {
+			var (
+				value interface{}
+				err   error
+			)
+			switch v := item.(type) {
+			case PathValue:
+				value, err = function(v.Path, v.Value)
+			default:
+				value, err = function(nil, bytesOf(v))
+			}
+			if err != nil {
+				return
+			}
+			next <- value
+		}
+	}
+
+	go f(_next)
+
+	return _next
+}
+
+//Get the packets matching the path from the stream, the path starts from the packets at top
+//level of stream, e.g., the root node 0x01. Wildcard matches any key, and Index(i) matches the
+//ith item of slice. Every matched packet is a PathValue with its concrete path, so the values
+//of different paths can be dispatched in one pass.
+func (o *observableImpl) SubscribePath(path ...PathSegment) Observable {
+	f := func(next chan interface{}) {
+		defer close(next)
+
+		matcher := newPathMatcher(path)
+		emit := func(v PathValue) {
+			next <- v
+		}
+		for item := range o.Observe() {
+			matcher.feed(bytesOf(item), emit)
+		}
+	}
+
+	return createObservable(f)
+}
+
 //Get the value of the subscribe key from the stream
 func (o *observableImpl) Subscribe(key byte) Observable {
 
@@ -237,6 +288,18 @@ func (o *observableImpl) Subscribe(key byte) Observable {
 
 }
 
+//bytesOf returns the bytes of item, the value of PathValue when the observable is chained after SubscribePath
+func bytesOf(item interface{}) []byte {
+	switch v := item.(type) {
+	case PathValue:
+		return v.Value
+	case []byte:
+		return v
+	default:
+		return nil
+	}
+}
+
 func createObservable(f func(next chan interface{})) Observable {
 	next := make(chan interface{})
 	subscribers := make([]chan interface{}, 0)
@@ -269,7 +332,7 @@ func filterRoot(observe <-chan interface{}) <-chan interface{} {
 					return
 				}
 
-				buf := item.([]byte)
+				buf := bytesOf(item)
 				i := 0
 
 				for {
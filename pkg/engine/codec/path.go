@@ -0,0 +1,252 @@
+package codec
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bhojpur/service/pkg/engine/codec/common"
+	"github.com/bhojpur/service/pkg/engine/codec/internal/utils"
+)
+
+// PathSegment matches the packets at a level of path, it's either a key in 0x00-0x3f, the
+// Wildcard, or an index of slice item created by Index.
+type PathSegment int
+
+// Wildcard matches the packets of any key.
+const Wildcard PathSegment = -1
+
+// Index matches the ith child of node, e.g., the ith item of slice.
+func Index(i int) PathSegment {
+	return PathSegment(-2 - i)
+}
+
+// IsKey returns true if the segment is a key.
+func (s PathSegment) IsKey() bool {
+	return s >= 0
+}
+
+// IsIndex returns true if the segment is an index.
+func (s PathSegment) IsIndex() bool {
+	return s <= -2
+}
+
+// Index returns the index of segment, -1 if it's not an index.
+func (s PathSegment) Index() int {
+	if !s.IsIndex() {
+		return -1
+	}
+	return int(-2 - s)
+}
+
+func (s PathSegment) String() string {
+	switch {
+	case s == Wildcard:
+		return "*"
+	case s.IsIndex():
+		return fmt.Sprintf("[%d]", s.Index())
+	default:
+		return fmt.Sprintf("%#02x", int(s))
+	}
+}
+
+// Path is a key path from the packets at top level of stream, e.g., the path of name in
+// `foo{bar{name}}` is Path{0x01, 0x03, 0x04}.
+type Path []PathSegment
+
+func (p Path) String() string {
+	segments := make([]string, len(p))
+	for i, s := range p {
+		segments[i] = s.String()
+	}
+	return strings.Join(segments, ".")
+}
+
+// PathValue is a packet matched by SubscribePath.
+type PathValue struct {
+	// Path is the concrete path of the packet, the items of slice are indexes.
+	Path Path
+	// Value is the whole packet, including tag and length.
+	Value []byte
+}
+
+// pathMatcher matches the packets of path in the byte stream, the packets not in the path
+// are skipped without decoding or buffering.
+type pathMatcher struct {
+	pattern Path
+	// offset of the stream
+	pos    int64
+	state  int
+	header []byte
+	// bytes to skip or capture
+	remaining int
+	captured  []byte
+	path      Path
+	stack     []pathFrame
+	// children of stream at top level
+	topChildren int
+}
+
+// pathFrame is a node in the path being matched.
+type pathFrame struct {
+	end      int64
+	slice    bool
+	children int
+	path     Path
+}
+
+const (
+	pathStateTag = iota
+	pathStateLength
+	pathStateSkip
+	pathStateCapture
+)
+
+func newPathMatcher(pattern Path) *pathMatcher {
+	return &pathMatcher{pattern: pattern}
+}
+
+// feed parses the next chunk of stream.
+func (m *pathMatcher) feed(buf []byte, emit func(PathValue)) {
+	for i := 0; i < len(buf); {
+		switch m.state {
+		case pathStateTag:
+			m.header = append(m.header[:0], buf[i])
+			m.state = pathStateLength
+			i++
+			m.pos++
+		case pathStateLength:
+			m.header = append(m.header, buf[i])
+			i++
+			m.pos++
+			length, err := common.DecodeLength(m.header[1:])
+			if err != nil {
+				if len(m.header) > 6 {
+					// malformed length, resync from next byte
+					m.state = pathStateTag
+				}
+				continue
+			}
+			if length < 0 {
+				// negative length, resync from next byte
+				m.state = pathStateTag
+				continue
+			}
+			m.onHeader(int(length), emit)
+		case pathStateSkip, pathStateCapture:
+			n := len(buf) - i
+			if n > m.remaining {
+				n = m.remaining
+			}
+			if m.state == pathStateCapture {
+				m.captured = append(m.captured, buf[i:i+n]...)
+			}
+			i += n
+			m.pos += int64(n)
+			m.remaining -= n
+			if m.remaining == 0 {
+				m.onValue(emit)
+			}
+		}
+	}
+}
+
+// onHeader decides whether to capture, descend into or skip the packet.
+func (m *pathMatcher) onHeader(length int, emit func(PathValue)) {
+	tag := m.header[0]
+	depth := len(m.stack)
+
+	var (
+		parentPath Path
+		index      int
+		slice      bool
+	)
+	if depth > 0 {
+		parent := &m.stack[depth-1]
+		parentPath, index, slice = parent.path, parent.children, parent.slice
+		parent.children++
+	} else {
+		index = m.topChildren
+		m.topChildren++
+	}
+
+	key := tag & utils.DropMSBArrayFlag
+	segment := PathSegment(key)
+	if slice {
+		segment = Index(index)
+	}
+	matched := false
+	if depth < len(m.pattern) {
+		switch p := m.pattern[depth]; {
+		case p == Wildcard:
+			matched = true
+		case p.IsIndex():
+			matched = p.Index() == index
+		default:
+			matched = byte(p) == key
+		}
+	}
+
+	isNode := tag&utils.MSB == utils.MSB
+	switch {
+	case matched && depth == len(m.pattern)-1:
+		m.path = append(append(Path{}, parentPath...), segment)
+		// the length is untrusted until the value arrives, so don't reserve more than a chunk
+		size := length
+		if size > maxPooledBuffer {
+			size = maxPooledBuffer
+		}
+		m.captured = append(make([]byte, 0, len(m.header)+size), m.header...)
+		m.state, m.remaining = pathStateCapture, length
+	case matched && isNode:
+		m.stack = append(m.stack, pathFrame{
+			end:   m.pos + int64(length),
+			slice: tag&utils.SliceFlag == utils.SliceFlag,
+			path:  append(append(Path{}, parentPath...), segment),
+		})
+		m.state = pathStateTag
+	default:
+		m.state, m.remaining = pathStateSkip, length
+	}
+	if m.state != pathStateTag && m.remaining == 0 {
+		m.onValue(emit)
+		return
+	}
+	m.pop()
+}
+
+// onValue completes the value of packet.
+func (m *pathMatcher) onValue(emit func(PathValue)) {
+	if m.state == pathStateCapture {
+		emit(PathValue{Path: m.path, Value: m.captured})
+		m.path, m.captured = nil, nil
+	}
+	m.state = pathStateTag
+	m.pop()
+}
+
+// pop closes the nodes which end at current offset.
+func (m *pathMatcher) pop() {
+	for len(m.stack) > 0 && m.stack[len(m.stack)-1].end <= m.pos {
+		m.stack = m.stack[:len(m.stack)-1]
+	}
+}
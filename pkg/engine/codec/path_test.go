@@ -0,0 +1,185 @@
+package codec
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newPathTestStream encodes foo{id, bar{name}, items[{name}, {name}]} twice.
+func newPathTestStream() []byte {
+	var stream []byte
+	for _, name := range []string{"a", "b"} {
+		foo := NewNodePacketEncoder(0x01)
+		id := NewPrimitivePacketEncoder(0x02)
+		id.SetInt32Value(-1)
+		foo.AddPrimitivePacket(id)
+
+		bar := NewNodePacketEncoder(0x03)
+		p := NewPrimitivePacketEncoder(0x04)
+		p.SetStringValue(name)
+		bar.AddPrimitivePacket(p)
+		foo.AddNodePacket(bar)
+
+		items := NewNodeSlicePacketEncoder(0x05)
+		for _, item := range []string{name + "1", name + "2"} {
+			node := NewNodePacketEncoder(KeyOfSliceItem)
+			p := NewPrimitivePacketEncoder(0x04)
+			p.SetStringValue(item)
+			node.AddPrimitivePacket(p)
+			items.AddNodePacket(node)
+		}
+		foo.AddNodePacket(items)
+
+		stream = append(stream, foo.Encode()...)
+	}
+	return stream
+}
+
+func matchPath(stream []byte, path ...PathSegment) []PathValue {
+	var values []PathValue
+	matcher := newPathMatcher(path)
+	// feed byte by byte to cover the values split across chunks
+	for i := range stream {
+		matcher.feed(stream[i:i+1], func(v PathValue) {
+			values = append(values, v)
+		})
+	}
+	return values
+}
+
+func pathStrings(t *testing.T, values []PathValue) map[string]string {
+	result := make(map[string]string)
+	for _, v := range values {
+		a, err := NewAccessor(v.Value)
+		assert.NoError(t, err)
+		s, _ := a.ToUTF8String()
+		result[v.Path.String()] = s
+	}
+	return result
+}
+
+func TestSubscribePathKeys(t *testing.T) {
+	values := matchPath(newPathTestStream(), 0x01, 0x03, 0x04)
+	assert.Equal(t, []PathValue{
+		{Path: Path{0x01, 0x03, 0x04}, Value: []byte{0x04, 0x01, 'a'}},
+		{Path: Path{0x01, 0x03, 0x04}, Value: []byte{0x04, 0x01, 'b'}},
+	}, values)
+}
+
+func TestSubscribePathWildcard(t *testing.T) {
+	values := matchPath(newPathTestStream(), 0x01, 0x05, Wildcard, 0x04)
+	assert.Len(t, values, 4)
+	assert.Equal(t, "0x01.0x05.[1].0x04", values[1].Path.String())
+	assert.Equal(t, map[string]string{
+		"0x01.0x05.[0].0x04": "b1",
+		"0x01.0x05.[1].0x04": "b2",
+	}, pathStrings(t, values[2:]))
+
+	// the whole node is matched
+	values = matchPath(newPathTestStream(), 0x01, Wildcard)
+	assert.Len(t, values, 6)
+	assert.Equal(t, Path{0x01, 0x02}, values[0].Path)
+	id, _ := NewAccessor(values[0].Value)
+	v, _ := id.Int32()
+	assert.Equal(t, int32(-1), v)
+	bar, _ := NewAccessor(values[1].Value)
+	assert.True(t, bar.IsNode())
+}
+
+func TestSubscribePathIndex(t *testing.T) {
+	values := matchPath(newPathTestStream(), 0x01, 0x05, Index(1), 0x04)
+	assert.Equal(t, map[string]string{"0x01.0x05.[1].0x04": "b2"}, pathStrings(t, values[1:]))
+	assert.Len(t, values, 2)
+
+	assert.Empty(t, matchPath(newPathTestStream(), 0x01, 0x05, Index(2)))
+	assert.Empty(t, matchPath(newPathTestStream(), 0x01, 0x06))
+}
+
+func TestSubscribePathObservable(t *testing.T) {
+	reader := iotest.OneByteReader(bytes.NewReader(newPathTestStream()))
+	consumer := FromStream(reader).SubscribePath(0x01, Wildcard, Wildcard, 0x04).
+		OnObservePath(func(path Path, v []byte) (interface{}, error) {
+			a, err := NewAccessor(v)
+			if err != nil {
+				return nil, err
+			}
+			s, err := a.ToUTF8String()
+			return path.String() + "=" + s, err
+		})
+
+	var results []string
+	for v := range consumer {
+		results = append(results, v.(string))
+	}
+	assert.Equal(t, []string{
+		"0x01.0x05.[0].0x04=a1",
+		"0x01.0x05.[1].0x04=a2",
+		"0x01.0x05.[0].0x04=b1",
+		"0x01.0x05.[1].0x04=b2",
+	}, results)
+}
+
+func TestSubscribePathChained(t *testing.T) {
+	// the values of SubscribePath are the packets of stream observed by the chained operators
+	bars := FromStream(bytes.NewReader(newPathTestStream())).SubscribePath(0x01, 0x03).
+		SubscribePath(0x03, 0x04).
+		OnObserve(func(v []byte) (interface{}, error) {
+			a, err := NewAccessor(v)
+			if err != nil {
+				return nil, err
+			}
+			return a.ToUTF8String()
+		})
+	var names []string
+	for v := range bars {
+		names = append(names, v.(string))
+	}
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	ids := FromStream(bytes.NewReader(newPathTestStream())).SubscribePath(0x01).Subscribe(0x02).
+		OnObserve(func(v []byte) (interface{}, error) {
+			return v, nil
+		})
+	count := 0
+	for v := range ids {
+		a, err := NewAccessor(v.([]byte))
+		assert.NoError(t, err)
+		id, _ := a.Int32()
+		assert.Equal(t, int32(-1), id)
+		count++
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestPathSegment(t *testing.T) {
+	assert.True(t, PathSegment(0x10).IsKey())
+	assert.False(t, Wildcard.IsKey())
+	assert.False(t, Wildcard.IsIndex())
+	assert.True(t, Index(0).IsIndex())
+	assert.Equal(t, 3, Index(3).Index())
+	assert.Equal(t, -1, PathSegment(0x10).Index())
+	assert.Equal(t, "0x10.*.[3]", Path{0x10, Wildcard, Index(3)}.String())
+}
@@ -0,0 +1,152 @@
+package codec
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/bhojpur/service/pkg/utils/encoding"
+)
+
+// maxPooledBuffer is the capacity limit of buffers put back to pool, the larger ones are
+// left to GC to avoid holding memory.
+const maxPooledBuffer = 64 * 1024
+
+var packetPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
+
+// DefaultMaxPacketSize is the size limit of a packet read by PacketReader, unless its MaxSize is set.
+const DefaultMaxPacketSize = 16 * 1024 * 1024
+
+var (
+	// ErrMalformedLength is returned when the length of packet can't be decoded.
+	ErrMalformedLength = errors.New("codec: malformed length")
+	// ErrPacketTooLarge is returned when the length of packet exceeds the size limit of PacketReader.
+	ErrPacketTooLarge = errors.New("codec: packet too large")
+)
+
+// PacketReader reads the packets from stream into the pooled buffers, the Accessor returned by
+// Read should be released when it's no longer used, so its buffer can be reused by the next
+// packets without allocating.
+type PacketReader struct {
+	// MaxSize is the size limit of a packet, including its tag and length, DefaultMaxPacketSize
+	// when it's 0. The buffer of a packet is allocated only when its length is within the limit.
+	MaxSize int
+	reader  io.ByteReader
+	r       io.Reader
+}
+
+// NewPacketReader creates a PacketReader, r is buffered if it's not an io.ByteReader.
+func NewPacketReader(r io.Reader) *PacketReader {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		buffered := bufio.NewReader(r)
+		br, r = buffered, buffered
+	}
+	return &PacketReader{reader: br, r: r}
+}
+
+// Read reads the next packet, io.EOF is returned when the stream ends between packets.
+func (pr *PacketReader) Read() (Accessor, error) {
+	tag, err := pr.reader.ReadByte()
+	if err != nil {
+		return Accessor{}, err
+	}
+	pooled := packetPool.Get().(*[]byte)
+	buf := append((*pooled)[:0], tag)
+	// the length is varint, the last byte has no MSB
+	for {
+		b, err := pr.reader.ReadByte()
+		if err != nil {
+			putPacket(pooled, buf)
+			return Accessor{}, unexpectedEOF(err)
+		}
+		buf = append(buf, b)
+		if b&0x80 == 0 {
+			break
+		}
+		if len(buf) > 6 {
+			putPacket(pooled, buf)
+			return Accessor{}, ErrMalformedLength
+		}
+	}
+	var length int32
+	codec := encoding.VarCodec{}
+	if err := codec.DecodePVarInt32(buf[1:], &length); err != nil || length < 0 {
+		putPacket(pooled, buf)
+		return Accessor{}, ErrMalformedLength
+	}
+	header := len(buf)
+	size := header + int(length)
+	maxSize := pr.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxPacketSize
+	}
+	if size > maxSize {
+		putPacket(pooled, buf)
+		return Accessor{}, ErrPacketTooLarge
+	}
+	if cap(buf) < size {
+		grown := make([]byte, header, size)
+		copy(grown, buf)
+		buf = grown
+	}
+	buf = buf[:size]
+	if _, err := io.ReadFull(pr.r, buf[header:]); err != nil {
+		putPacket(pooled, buf)
+		return Accessor{}, unexpectedEOF(err)
+	}
+	*pooled = buf
+	return Accessor{buf: buf, start: header, pooled: pooled}, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Release puts the buffer of Accessor read by PacketReader back to pool, the Accessor and the
+// Accessors or bytes taken from it must not be used after released. It does nothing for the
+// Accessor created by NewAccessor.
+func (a Accessor) Release() {
+	if a.pooled == nil {
+		return
+	}
+	putPacket(a.pooled, *a.pooled)
+}
+
+// putPacket puts buf back to pool as pooled, unless it's larger than maxPooledBuffer.
+func putPacket(pooled *[]byte, buf []byte) {
+	if cap(buf) > maxPooledBuffer {
+		return
+	}
+	*pooled = buf
+	packetPool.Put(pooled)
+}
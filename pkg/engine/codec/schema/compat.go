@@ -0,0 +1,56 @@
+package schema
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Compatible checks whether the data encoded by the prev schema can be decoded by the next
+// schema and vice versa. The fields can be added, removed or renamed, but the messages
+// observed by data tags can't be removed, the data tags can't be changed, and the keys
+// can't be reused by the fields of different types. The incompatibilities are returned as
+// Errors located in the next schema.
+func Compatible(prev, next *Schema) error {
+	var errs Errors
+	for _, pm := range prev.Messages {
+		nm := next.Message(pm.Name)
+		if nm == nil {
+			if pm.Tag != 0 {
+				errs.add(prev.File, pm.Line, "message %s with tag %#x is removed", pm.Name, pm.Tag)
+			}
+			continue
+		}
+		if pm.Tag != nm.Tag {
+			errs.add(next.File, nm.Line, "tag of message %s is changed from %#x to %#x", nm.Name, pm.Tag, nm.Tag)
+		}
+		for _, pf := range pm.Fields {
+			nf := nm.FieldByKey(pf.Key)
+			if nf == nil {
+				continue
+			}
+			if pf.TypeString() != nf.TypeString() {
+				errs.add(next.File, nf.Line, "type of key %#x in message %s is changed from %s to %s",
+					nf.Key, nm.Name, pf.TypeString(), nf.TypeString())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package schema
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompatible(t *testing.T) {
+	old, err := Parse("old.codec", []byte(`package a
+message A = 0x10 {
+  b string = 0x10
+  c int32  = 0x11
+}
+message B = 0x11 {
+}`))
+	assert.NoError(t, err)
+
+	compatible, err := Parse("new.codec", []byte(`package a
+message A = 0x10 {
+  renamed string = 0x10
+  d       bool   = 0x12
+}
+message B = 0x11 {
+}`))
+	assert.NoError(t, err)
+	assert.NoError(t, Compatible(old, compatible))
+
+	incompatible, err := Parse("new.codec", []byte(`package a
+message A = 0x12 {
+  b []string = 0x10
+  c int64    = 0x11
+}`))
+	assert.NoError(t, err)
+	err = Compatible(old, incompatible)
+	assert.Error(t, err)
+	assert.Len(t, err.(Errors), 4)
+	assert.Contains(t, err.Error(), "new.codec:2: tag of message A is changed from 0x10 to 0x12")
+	assert.Contains(t, err.Error(), "new.codec:3: type of key 0x10 in message A is changed from string to []string")
+	assert.Contains(t, err.Error(), "new.codec:4: type of key 0x11 in message A is changed from int32 to int64")
+	assert.Contains(t, err.Error(), "old.codec:6: message B with tag 0x11 is removed")
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package schema implements the schema language of codec, which describes the messages,
+// their data tags and the keys of fields, e.g.,
+//
+//	package noise
+//
+//	// NoiseData is observed by the stream functions.
+//	message NoiseData = 0x33 {
+//	    noise float32     = 0x10
+//	    time  int64       = 0x11
+//	    from  string      = 0x12
+//	    therm Thermometer = 0x13
+//	    tags  []string    = 0x14
+//	}
+//
+//	message Thermometer {
+//	    temperature float32 = 0x10
+//	}
+//
+// The Go code generated from the schema encodes and decodes the messages without reflection,
+// and it's compatible with the struct encoder of codec.
+package schema
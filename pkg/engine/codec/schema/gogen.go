@@ -0,0 +1,303 @@
+package schema
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// codecImportPath is the import path of codec used by the generated code.
+const codecImportPath = "github.com/bhojpur/service/pkg/engine/codec"
+
+var goTypes = map[string]string{
+	TypeString:  "string",
+	TypeBytes:   "[]byte",
+	TypeBool:    "bool",
+	TypeInt32:   "int32",
+	TypeInt64:   "int64",
+	TypeUint32:  "uint32",
+	TypeUint64:  "uint64",
+	TypeFloat32: "float32",
+	TypeFloat64: "float64",
+}
+
+var setters = map[string]string{
+	TypeString:  "SetStringValue",
+	TypeBytes:   "SetBytesValue",
+	TypeBool:    "SetBoolValue",
+	TypeInt32:   "SetInt32Value",
+	TypeInt64:   "SetInt64Value",
+	TypeUint32:  "SetUInt32Value",
+	TypeUint64:  "SetUInt64Value",
+	TypeFloat32: "SetFloat32Value",
+	TypeFloat64: "SetFloat64Value",
+}
+
+var getters = map[string]string{
+	TypeString:  "ToUTF8String",
+	TypeBool:    "ToBool",
+	TypeInt32:   "ToInt32",
+	TypeInt64:   "ToInt64",
+	TypeUint32:  "ToUInt32",
+	TypeUint64:  "ToUInt64",
+	TypeFloat32: "ToFloat32",
+	TypeFloat64: "ToFloat64",
+}
+
+var goTemplate = template.Must(template.New("go").Funcs(template.FuncMap{
+	"goName": GoName,
+	"goType": func(f *Field) string {
+		t, ok := goTypes[f.Type]
+		if !ok {
+			t = GoName(f.Type)
+		}
+		if f.Repeated {
+			return "[]" + t
+		}
+		return t
+	},
+	"elemType": func(f *Field) string {
+		if t, ok := goTypes[f.Type]; ok {
+			return t
+		}
+		return GoName(f.Type)
+	},
+	"setter": func(f *Field) string { return setters[f.Type] },
+	"getter": func(f *Field) string { return getters[f.Type] },
+	// canBeEmpty reports the values which are skipped when empty, same as the struct encoder.
+	"canBeEmpty": func(f *Field) bool { return f.Type == TypeString || f.Type == TypeBytes },
+	"hex":        func(b byte) string { return fmt.Sprintf("%#02x", b) },
+	"comment": func(indent, s string) string {
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(indent+"// "+line, " ")
+		}
+		return strings.Join(lines, "\n")
+	},
+	"primitives": func(m *Message) []*Field {
+		var fields []*Field
+		for _, f := range m.Fields {
+			if f.IsScalar() && !f.Repeated {
+				fields = append(fields, f)
+			}
+		}
+		return fields
+	},
+	"nodes": func(m *Message) []*Field {
+		var fields []*Field
+		for _, f := range m.Fields {
+			if !f.IsScalar() || f.Repeated {
+				fields = append(fields, f)
+			}
+		}
+		return fields
+	},
+}).Parse(`// Code generated by svcutl codegen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	codec "{{.Import}}"
+)
+{{- $tagged := false}}{{range .Messages}}{{if .Tag}}{{$tagged = true}}{{end}}{{end}}
+{{if $tagged}}
+// Data tags of the messages.
+const (
+{{- range .Messages}}{{if .Tag}}
+	{{goName .Name}}Tag byte = {{hex .Tag}}
+{{- end}}{{end}}
+)
+{{end}}
+{{- range .Messages}}{{$m := goName .Name}}
+{{- if .Fields}}
+// Keys of the fields of {{$m}}.
+const (
+{{- range .Fields}}
+	{{$m}}{{goName .Name}}Key byte = {{hex .Key}}
+{{- end}}
+)
+{{end}}
+{{if .Comment}}{{comment "" .Comment}}
+{{else}}// {{$m}} is generated from message {{.Name}}.
+{{end -}}
+type {{$m}} struct {
+{{- range .Fields}}
+{{- if .Comment}}
+{{comment "\t" .Comment}}{{- end}}
+	{{goName .Name}} {{goType .}} ` + "`" + `bhojpur:"{{hex .Key}}"` + "`" + `
+{{- end}}
+}
+{{if .Tag}}
+// MarshalCodec encodes {{$m}} observed by {{$m}}Tag, the result is the same as
+// codec.NewCodec({{$m}}Tag).Marshal(m).
+func (m *{{$m}}) MarshalCodec() ([]byte, error) {
+	root := codec.NewNodePacketEncoder(int(codec.RootToken))
+	root.AddNodePacket(m.EncodeCodecNode({{$m}}Tag))
+	return root.Encode(), nil
+}
+{{end}}
+// UnmarshalCodec decodes {{$m}} from the buffer of node{{if .Tag}}, e.g., the data observed by {{$m}}Tag{{end}}.
+func (m *{{$m}}) UnmarshalCodec(buf []byte) error {
+	node, _, err := codec.DecodeNodePacket(buf)
+	if err != nil {
+		return err
+	}
+	return m.DecodeCodecNode(node)
+}
+
+// EncodeCodecNode encodes {{$m}} to the node of key.
+func (m *{{$m}}) EncodeCodecNode(key byte) *codec.NodePacketEncoder {
+	node := codec.NewNodePacketEncoder(int(key))
+{{- range .Fields}}{{$f := goName .Name}}
+{{- if .Repeated}}
+	{
+		slice := codec.NewNodeSlicePacketEncoder(int({{$m}}{{$f}}Key))
+		for i := range m.{{$f}} {
+{{- if .IsScalar}}
+			item := codec.NewPrimitivePacketEncoder(codec.KeyOfSliceItem)
+			item.{{setter .}}(m.{{$f}}[i])
+{{- if canBeEmpty .}}
+			if !item.IsEmpty() {
+				slice.AddPrimitivePacket(item)
+			}
+{{- else}}
+			slice.AddPrimitivePacket(item)
+{{- end}}
+{{- else}}
+			if item := m.{{$f}}[i].EncodeCodecNode(codec.KeyOfSliceItem); !item.IsEmpty() {
+				slice.AddNodePacket(item)
+			}
+{{- end}}
+		}
+		node.AddNodePacket(slice)
+	}
+{{- else if .IsScalar}}
+	{
+		p := codec.NewPrimitivePacketEncoder(int({{$m}}{{$f}}Key))
+		p.{{setter .}}(m.{{$f}})
+{{- if canBeEmpty .}}
+		if !p.IsEmpty() {
+			node.AddPrimitivePacket(p)
+		}
+{{- else}}
+		node.AddPrimitivePacket(p)
+{{- end}}
+	}
+{{- else}}
+	if n := m.{{$f}}.EncodeCodecNode({{$m}}{{$f}}Key); !n.IsEmpty() {
+		node.AddNodePacket(n)
+	}
+{{- end}}
+{{- end}}
+	return node
+}
+
+// DecodeCodecNode decodes {{$m}} from the node, the fields not in the node are reset to zero values.
+func (m *{{$m}}) DecodeCodecNode(node *codec.NodePacket) error {
+	*m = {{$m}}{}
+{{- with primitives .}}
+	for i := range node.PrimitivePackets {
+		p := &node.PrimitivePackets[i]
+		switch p.SeqID() {
+{{- range .}}{{$f := goName .Name}}
+		case {{$m}}{{$f}}Key:
+{{- if eq .Type "bytes"}}
+			m.{{$f}} = p.ToBytes()
+{{- else}}
+			v, err := p.{{getter .}}()
+			if err != nil {
+				return err
+			}
+			m.{{$f}} = v
+{{- end}}
+{{- end}}
+		}
+	}
+{{- end}}
+{{- with nodes .}}
+	for i := range node.NodePackets {
+		n := &node.NodePackets[i]
+		switch n.SeqID() {
+{{- range .}}{{$f := goName .Name}}
+		case {{$m}}{{$f}}Key:
+{{- if and .Repeated .IsScalar}}
+			m.{{$f}} = make({{goType .}}, 0, len(n.PrimitivePackets))
+			for j := range n.PrimitivePackets {
+				v, err := n.PrimitivePackets[j].{{getter .}}()
+				if err != nil {
+					return err
+				}
+				m.{{$f}} = append(m.{{$f}}, v)
+			}
+{{- else if .Repeated}}
+			m.{{$f}} = make({{goType .}}, len(n.NodePackets))
+			for j := range n.NodePackets {
+				if err := m.{{$f}}[j].DecodeCodecNode(&n.NodePackets[j]); err != nil {
+					return err
+				}
+			}
+{{- else}}
+			if err := m.{{$f}}.DecodeCodecNode(n); err != nil {
+				return err
+			}
+{{- end}}
+{{- end}}
+		}
+	}
+{{- end}}
+	return nil
+}
+{{end}}`))
+
+// GoOptions are the options of generating Go code.
+type GoOptions struct {
+	// Package overrides the package of schema.
+	Package string
+}
+
+// GenerateGo generates the Go code of the schema, which contains the data tags and keys as
+// constants, the structs and the methods to encode and decode them without reflection.
+func GenerateGo(s *Schema, opts GoOptions) ([]byte, error) {
+	pkg := s.Package
+	if opts.Package != "" {
+		pkg = opts.Package
+	}
+	var buf bytes.Buffer
+	err := goTemplate.Execute(&buf, map[string]interface{}{
+		"Source":   filepath.Base(s.File),
+		"Package":  pkg,
+		"Import":   codecImportPath,
+		"Messages": s.Messages,
+	})
+	if err != nil {
+		return nil, err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v", err)
+	}
+	return code, nil
+}
@@ -0,0 +1,100 @@
+package noise
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/bhojpur/service/pkg/engine/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func newNoiseData() NoiseData {
+	return NoiseData{
+		Noise:   40.5,
+		Time:    1634000000000,
+		From:    "localhost",
+		Therm:   Thermometer{Temperature: 30, Humidity: 40, Location: "room"},
+		Tags:    []string{"a", "b"},
+		Raw:     []byte{0x01, 0x02},
+		Level:   7,
+		Valid:   true,
+		Seq:     1 << 40,
+		Score:   -3.25,
+		Delta:   -12,
+		History: []Thermometer{{Temperature: 10, Humidity: 20, Location: "hall"}, {Temperature: 11, Humidity: 21, Location: "yard"}},
+		Points:  []int64{-1, 0, 1},
+	}
+}
+
+func TestMarshalCodec(t *testing.T) {
+	data := newNoiseData()
+	buf, err := data.MarshalCodec()
+	assert.NoError(t, err)
+
+	expected, err := codec.NewCodec(NoiseDataTag).Marshal(data)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, buf)
+}
+
+func TestUnmarshalCodec(t *testing.T) {
+	data := newNoiseData()
+	buf := data.EncodeCodecNode(NoiseDataTag).Encode()
+
+	var result NoiseData
+	assert.NoError(t, result.UnmarshalCodec(buf))
+	assert.Equal(t, data, result)
+}
+
+func TestUnmarshalCodecToObject(t *testing.T) {
+	therm := Thermometer{Temperature: 30, Humidity: 40, Location: "room"}
+	buf, err := codec.NewCodec(0x10).Marshal(therm)
+	assert.NoError(t, err)
+	root, _, err := codec.DecodeNodePacket(buf)
+	assert.NoError(t, err)
+
+	var result Thermometer
+	assert.NoError(t, result.DecodeCodecNode(&root.NodePackets[0]))
+	assert.Equal(t, therm, result)
+}
+
+func TestUnmarshalCodecReset(t *testing.T) {
+	buf := (&NoiseData{Noise: 1, From: "a"}).EncodeCodecNode(NoiseDataTag).Encode()
+
+	result := newNoiseData()
+	assert.NoError(t, result.UnmarshalCodec(buf))
+	assert.Equal(t, NoiseData{Noise: 1, From: "a", Tags: []string{}, History: []Thermometer{}, Points: []int64{}}, result)
+}
+
+func BenchmarkMarshalCodec(b *testing.B) {
+	data := newNoiseData()
+	for i := 0; i < b.N; i++ {
+		_, _ = data.MarshalCodec()
+	}
+}
+
+func BenchmarkReflectMarshal(b *testing.B) {
+	data := newNoiseData()
+	c := codec.NewCodec(NoiseDataTag)
+	for i := 0; i < b.N; i++ {
+		_, _ = c.Marshal(data)
+	}
+}
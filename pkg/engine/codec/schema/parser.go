@@ -0,0 +1,262 @@
+package schema
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	identPattern   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	packagePattern = regexp.MustCompile(`^package\s+(\S+)$`)
+	messagePattern = regexp.MustCompile(`^message\s+(\S+)\s*(?:=\s*(\S+)\s*)?\{$`)
+	fieldPattern   = regexp.MustCompile(`^(\S+)\s+(\S+)\s*=\s*(\S+)$`)
+)
+
+// reservedNames are the methods of generated structs.
+var reservedNames = map[string]bool{
+	"MarshalCodec":    true,
+	"UnmarshalCodec":  true,
+	"EncodeCodecNode": true,
+	"DecodeCodecNode": true,
+}
+
+// ParseFile parses and validates the schema file.
+func ParseFile(path string) (*Schema, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, src)
+}
+
+// Parse parses and validates the schema, the problems are returned as Errors.
+func Parse(file string, src []byte) (*Schema, error) {
+	var errs Errors
+	s := &Schema{File: file}
+
+	var (
+		message *Message
+		comment []string
+		lineNo  int
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "//") {
+			comment = append(comment, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+			continue
+		}
+		// trailing comment
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		line = strings.TrimSpace(strings.TrimSuffix(line, ";"))
+		if line == "" {
+			comment = nil
+			continue
+		}
+		doc := strings.Join(comment, "\n")
+		comment = nil
+
+		switch {
+		case line == "}":
+			if message == nil {
+				errs.add(file, lineNo, "unexpected }")
+				continue
+			}
+			message = nil
+		case message != nil:
+			m := fieldPattern.FindStringSubmatch(line)
+			if m == nil {
+				errs.add(file, lineNo, "invalid field %q, expected: name type = key", line)
+				continue
+			}
+			f := &Field{Name: m[1], Type: m[2], Comment: doc, Line: lineNo}
+			if strings.HasPrefix(f.Type, "[]") {
+				f.Repeated = true
+				f.Type = strings.TrimPrefix(f.Type, "[]")
+			}
+			key, err := parseKey(m[3])
+			if err != nil {
+				errs.add(file, lineNo, "invalid key of field %s: %s", f.Name, m[3])
+				continue
+			}
+			f.Key = key
+			message.Fields = append(message.Fields, f)
+		case strings.HasPrefix(line, "package"):
+			m := packagePattern.FindStringSubmatch(line)
+			if m == nil || !identPattern.MatchString(m[1]) {
+				errs.add(file, lineNo, "invalid package %q", line)
+				continue
+			}
+			if s.Package != "" {
+				errs.add(file, lineNo, "duplicate package")
+				continue
+			}
+			s.Package = m[1]
+		case strings.HasPrefix(line, "message"):
+			m := messagePattern.FindStringSubmatch(line)
+			if m == nil {
+				errs.add(file, lineNo, "invalid message %q, expected: message Name [= tag] {", line)
+				continue
+			}
+			message = &Message{Name: m[1], Comment: doc, Line: lineNo}
+			if m[2] != "" {
+				tag, err := parseKey(m[2])
+				if err != nil {
+					errs.add(file, lineNo, "invalid tag of message %s: %s", message.Name, m[2])
+				}
+				message.Tag = tag
+			}
+			s.Messages = append(s.Messages, message)
+		default:
+			errs.add(file, lineNo, "unexpected %q", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if message != nil {
+		errs.add(file, message.Line, "message %s is not closed", message.Name)
+	}
+
+	validate(s, &errs)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return s, nil
+}
+
+// parseKey parses the key in hex (0x10) or decimal (16).
+func parseKey(v string) (byte, error) {
+	n, err := strconv.ParseUint(v, 0, 8)
+	return byte(n), err
+}
+
+// validate checks the names, types and keys of the schema.
+func validate(s *Schema, errs *Errors) {
+	if s.Package == "" {
+		errs.add(s.File, 0, "missing package")
+	}
+	messages := make(map[string]*Message)
+	tags := make(map[byte]*Message)
+	for _, m := range s.Messages {
+		if !identPattern.MatchString(m.Name) {
+			errs.add(s.File, m.Line, "invalid message name %q", m.Name)
+		}
+		if first, ok := messages[m.Name]; ok {
+			errs.add(s.File, m.Line, "duplicate message %s, first defined at line %d", m.Name, first.Line)
+			continue
+		}
+		messages[m.Name] = m
+		if m.Tag != 0 {
+			if m.Tag < MinKey || m.Tag > MaxKey {
+				errs.add(s.File, m.Line, "tag %#x of message %s is out of range [%#x, %#x]", m.Tag, m.Name, MinKey, MaxKey)
+			}
+			if first, ok := tags[m.Tag]; ok {
+				errs.add(s.File, m.Line, "duplicate tag %#x of message %s, first used by %s", m.Tag, m.Name, first.Name)
+			}
+			tags[m.Tag] = m
+		}
+	}
+
+	for _, m := range s.Messages {
+		names := make(map[string]*Field)
+		keys := make(map[byte]*Field)
+		for _, f := range m.Fields {
+			if !identPattern.MatchString(f.Name) {
+				errs.add(s.File, f.Line, "invalid field name %q", f.Name)
+			}
+			goName := GoName(f.Name)
+			if first, ok := names[goName]; ok {
+				errs.add(s.File, f.Line, "duplicate field %s.%s, first defined at line %d", m.Name, f.Name, first.Line)
+			}
+			names[goName] = f
+			if reservedNames[goName] {
+				errs.add(s.File, f.Line, "field %s.%s conflicts with the generated method %s", m.Name, f.Name, goName)
+			}
+			if f.Key < MinKey || f.Key > MaxKey {
+				errs.add(s.File, f.Line, "key %#x of field %s.%s is out of range [%#x, %#x]", f.Key, m.Name, f.Name, MinKey, MaxKey)
+			}
+			if first, ok := keys[f.Key]; ok {
+				errs.add(s.File, f.Line, "duplicate key %#x of field %s.%s, first used by %s", f.Key, m.Name, f.Name, first.Name)
+			}
+			keys[f.Key] = f
+			if !f.IsScalar() && messages[f.Type] == nil {
+				errs.add(s.File, f.Line, "unknown type %s of field %s.%s", f.TypeString(), m.Name, f.Name)
+			}
+			if f.Repeated && f.Type == TypeBytes {
+				errs.add(s.File, f.Line, "unsupported type []bytes of field %s.%s", m.Name, f.Name)
+			}
+		}
+	}
+
+	// the nested messages which are not repeated can't be recursive
+	for _, m := range s.Messages {
+		if path := findCycle(messages, m, nil); path != nil {
+			errs.add(s.File, m.Line, "recursive message %s", strings.Join(path, " -> "))
+		}
+	}
+}
+
+func findCycle(messages map[string]*Message, m *Message, path []string) []string {
+	for _, name := range path {
+		if name == m.Name {
+			if path[0] == m.Name {
+				return append(path, m.Name)
+			}
+			// the cycle is reported by the message in it
+			return nil
+		}
+	}
+	path = append(path, m.Name)
+	for _, f := range m.Fields {
+		if f.Repeated || f.IsScalar() {
+			continue
+		}
+		if next, ok := messages[f.Type]; ok {
+			if cycle := findCycle(messages, next, path); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// GoName converts the name in schema to the exported Go name, e.g., sensor_id to SensorId.
+func GoName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
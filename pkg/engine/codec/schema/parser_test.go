@@ -0,0 +1,83 @@
+package schema
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	s, err := ParseFile("internal/noise/noise.codec")
+	assert.NoError(t, err)
+	assert.Equal(t, "noise", s.Package)
+	assert.Len(t, s.Messages, 2)
+
+	m := s.Message("NoiseData")
+	assert.Equal(t, byte(0x33), m.Tag)
+	assert.Equal(t, "NoiseData is the data observed by the stream functions.", m.Comment)
+
+	f := m.FieldByKey(0x13)
+	assert.Equal(t, "therm", f.Name)
+	assert.Equal(t, "Thermometer", f.Type)
+	assert.Equal(t, "therm is the nested message.", f.Comment)
+	assert.False(t, f.IsScalar())
+
+	f = m.Field("tags")
+	assert.True(t, f.Repeated)
+	assert.Equal(t, "[]string", f.TypeString())
+
+	assert.Equal(t, byte(0), s.Message("Thermometer").Tag)
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{"missing package", "message A = 0x10 {\n}", "a.codec: missing package"},
+		{"invalid field", "package a\nmessage A {\n  b string\n}", "a.codec:3: invalid field"},
+		{"not closed", "package a\nmessage A {\n", "a.codec:2: message A is not closed"},
+		{"tag out of range", "package a\nmessage A = 0x40 {\n}", "a.codec:2: tag 0x40 of message A is out of range"},
+		{"duplicate tag", "package a\nmessage A = 0x10 {\n}\nmessage B = 0x10 {\n}", "a.codec:4: duplicate tag 0x10 of message B"},
+		{"duplicate message", "package a\nmessage A {\n}\nmessage A {\n}", "a.codec:4: duplicate message A"},
+		{"key out of range", "package a\nmessage A {\n  b string = 0x01\n}", "a.codec:3: key 0x1 of field A.b is out of range"},
+		{"duplicate key", "package a\nmessage A {\n  b string = 0x10\n  c int32 = 0x10\n}", "a.codec:4: duplicate key 0x10 of field A.c"},
+		{"duplicate field", "package a\nmessage A {\n  b_c string = 0x10\n  bC int32 = 0x11\n}", "a.codec:4: duplicate field A.bC"},
+		{"unknown type", "package a\nmessage A {\n  b C = 0x10\n}", "a.codec:3: unknown type C of field A.b"},
+		{"reserved name", "package a\nmessage A {\n  marshal_codec string = 0x10\n}", "a.codec:3: field A.marshal_codec conflicts"},
+		{"recursive", "package a\nmessage A {\n  b B = 0x10\n}\nmessage B {\n  a A = 0x10\n}", "a.codec:2: recursive message A -> B -> A"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Parse("a.codec", []byte(c.src))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), c.err)
+		})
+	}
+}
+
+func TestParseRepeatedRecursive(t *testing.T) {
+	_, err := Parse("a.codec", []byte("package a\nmessage A {\n  children []A = 0x10\n}"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,161 @@
+package schema
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scalar types of fields.
+const (
+	TypeString  = "string"
+	TypeBytes   = "bytes"
+	TypeBool    = "bool"
+	TypeInt32   = "int32"
+	TypeInt64   = "int64"
+	TypeUint32  = "uint32"
+	TypeUint64  = "uint64"
+	TypeFloat32 = "float32"
+	TypeFloat64 = "float64"
+)
+
+var scalarTypes = map[string]bool{
+	TypeString:  true,
+	TypeBytes:   true,
+	TypeBool:    true,
+	TypeInt32:   true,
+	TypeInt64:   true,
+	TypeUint32:  true,
+	TypeUint64:  true,
+	TypeFloat32: true,
+	TypeFloat64: true,
+}
+
+// The range of keys which can be used by fields and data tags, the others are reserved by codec.
+const (
+	MinKey byte = 0x10
+	MaxKey byte = 0x3f
+)
+
+// Schema is a parsed schema file.
+type Schema struct {
+	// File is the name of schema file.
+	File string
+	// Package is the Go package of the generated code.
+	Package string
+	// Messages are in order of definition.
+	Messages []*Message
+}
+
+// Message describes a struct.
+type Message struct {
+	Name string
+	// Tag is the data tag observed by stream functions, 0 if the message is only nested.
+	Tag     byte
+	Fields  []*Field
+	Comment string
+	Line    int
+}
+
+// Field describes a field of message.
+type Field struct {
+	Name string
+	// Type is a scalar type or the name of message.
+	Type string
+	// Repeated is true if the field is a slice of Type.
+	Repeated bool
+	Key      byte
+	Comment  string
+	Line     int
+}
+
+// Message returns the message by name, nil if it's not defined.
+func (s *Schema) Message(name string) *Message {
+	for _, m := range s.Messages {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Field returns the field by name, nil if it's not defined.
+func (m *Message) Field(name string) *Field {
+	for _, f := range m.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// FieldByKey returns the field by key, nil if it's not defined.
+func (m *Message) FieldByKey(key byte) *Field {
+	for _, f := range m.Fields {
+		if f.Key == key {
+			return f
+		}
+	}
+	return nil
+}
+
+// IsScalar returns if the type of field is a scalar type.
+func (f *Field) IsScalar() bool {
+	return scalarTypes[f.Type]
+}
+
+// TypeString returns the type of field in the schema language, e.g., []string.
+func (f *Field) TypeString() string {
+	if f.Repeated {
+		return "[]" + f.Type
+	}
+	return f.Type
+}
+
+// Error is a problem of the schema file.
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Msg)
+}
+
+// Errors is the list of problems of the schema file.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *Errors) add(file string, line int, format string, args ...interface{}) {
+	*e = append(*e, &Error{File: file, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
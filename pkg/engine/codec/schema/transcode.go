@@ -0,0 +1,360 @@
+package schema
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/bhojpur/service/pkg/engine/codec"
+	"github.com/bhojpur/service/pkg/engine/codec/common"
+)
+
+// EncodeJSON encodes the JSON object as the message, which is observed by the tag of message.
+// The result is the same as the MarshalCodec method of generated code, the fields missing in
+// JSON are encoded as zero values, []byte is represented as base64 string.
+func (s *Schema) EncodeJSON(message string, data []byte) ([]byte, error) {
+	m := s.Message(message)
+	if m == nil {
+		return nil, fmt.Errorf("message %s is not defined", message)
+	}
+	if m.Tag == 0 {
+		return nil, fmt.Errorf("message %s has no tag", message)
+	}
+	var obj interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	node, err := s.encodeMessage(m, m.Tag, obj, m.Name)
+	if err != nil {
+		return nil, err
+	}
+	root := codec.NewNodePacketEncoder(int(codec.RootToken))
+	root.AddNodePacket(node)
+	return root.Encode(), nil
+}
+
+func (s *Schema) encodeMessage(m *Message, key byte, v interface{}, path string) (*codec.NodePacketEncoder, error) {
+	obj, ok := v.(map[string]interface{})
+	if v != nil && !ok {
+		return nil, fmt.Errorf("%s: expected object of %s", path, m.Name)
+	}
+	for name := range obj {
+		if m.Field(name) == nil {
+			return nil, fmt.Errorf("%s.%s: field is not defined in %s", path, name, m.Name)
+		}
+	}
+	node := codec.NewNodePacketEncoder(int(key))
+	for _, f := range m.Fields {
+		fieldPath := path + "." + f.Name
+		value := obj[f.Name]
+		switch {
+		case f.Repeated:
+			items, ok := value.([]interface{})
+			if value != nil && !ok {
+				return nil, fmt.Errorf("%s: expected array of %s", fieldPath, f.Type)
+			}
+			slice := codec.NewNodeSlicePacketEncoder(int(f.Key))
+			for i, item := range items {
+				itemPath := fieldPath + "[" + strconv.Itoa(i) + "]"
+				if f.IsScalar() {
+					p, err := encodeScalar(f.Type, codec.KeyOfSliceItem, item, itemPath)
+					if err != nil {
+						return nil, err
+					}
+					if !p.IsEmpty() {
+						slice.AddPrimitivePacket(p)
+					}
+					continue
+				}
+				n, err := s.encodeMessage(s.Message(f.Type), codec.KeyOfSliceItem, item, itemPath)
+				if err != nil {
+					return nil, err
+				}
+				if !n.IsEmpty() {
+					slice.AddNodePacket(n)
+				}
+			}
+			node.AddNodePacket(slice)
+		case f.IsScalar():
+			p, err := encodeScalar(f.Type, f.Key, value, fieldPath)
+			if err != nil {
+				return nil, err
+			}
+			if !p.IsEmpty() {
+				node.AddPrimitivePacket(p)
+			}
+		default:
+			n, err := s.encodeMessage(s.Message(f.Type), f.Key, value, fieldPath)
+			if err != nil {
+				return nil, err
+			}
+			if !n.IsEmpty() {
+				node.AddNodePacket(n)
+			}
+		}
+	}
+	return node, nil
+}
+
+func encodeScalar(typ string, key byte, v interface{}, path string) (*codec.PrimitivePacketEncoder, error) {
+	p := codec.NewPrimitivePacketEncoder(int(key))
+	switch typ {
+	case TypeString:
+		s, ok := v.(string)
+		if v != nil && !ok {
+			return nil, fmt.Errorf("%s: expected string", path)
+		}
+		p.SetStringValue(s)
+	case TypeBytes:
+		s, ok := v.(string)
+		if v != nil && !ok {
+			return nil, fmt.Errorf("%s: expected base64 string", path)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		p.SetBytesValue(b)
+	case TypeBool:
+		b, ok := v.(bool)
+		if v != nil && !ok {
+			return nil, fmt.Errorf("%s: expected bool", path)
+		}
+		p.SetBoolValue(b)
+	default:
+		n, ok := v.(json.Number)
+		if v != nil && !ok {
+			return nil, fmt.Errorf("%s: expected %s", path, typ)
+		}
+		if n == "" {
+			n = "0"
+		}
+		if err := setNumber(p, typ, string(n)); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return p, nil
+}
+
+func setNumber(p *codec.PrimitivePacketEncoder, typ, n string) error {
+	switch typ {
+	case TypeInt32, TypeInt64:
+		bits := 64
+		if typ == TypeInt32 {
+			bits = 32
+		}
+		v, err := strconv.ParseInt(n, 10, bits)
+		if err != nil {
+			return err
+		}
+		if typ == TypeInt32 {
+			p.SetInt32Value(int32(v))
+		} else {
+			p.SetInt64Value(v)
+		}
+	case TypeUint32, TypeUint64:
+		bits := 64
+		if typ == TypeUint32 {
+			bits = 32
+		}
+		v, err := strconv.ParseUint(n, 10, bits)
+		if err != nil {
+			return err
+		}
+		if typ == TypeUint32 {
+			p.SetUInt32Value(uint32(v))
+		} else {
+			p.SetUInt64Value(v)
+		}
+	case TypeFloat32:
+		v, err := strconv.ParseFloat(n, 32)
+		if err != nil {
+			return err
+		}
+		p.SetFloat32Value(float32(v))
+	case TypeFloat64:
+		v, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return err
+		}
+		p.SetFloat64Value(v)
+	}
+	return nil
+}
+
+// DecodeJSON decodes the message as JSON object, buf is either encoded by EncodeJSON, or the
+// node observed by the tag of message. All the fields of message are in the JSON object.
+func (s *Schema) DecodeJSON(message string, buf []byte) ([]byte, error) {
+	m := s.Message(message)
+	if m == nil {
+		return nil, fmt.Errorf("message %s is not defined", message)
+	}
+	if len(buf) == 0 {
+		return nil, fmt.Errorf("empty buffer")
+	}
+	node, _, err := codec.DecodeNodePacket(buf)
+	if err != nil {
+		return nil, err
+	}
+	if common.IsRootTag(buf[0]) {
+		var observed *codec.NodePacket
+		for i := range node.NodePackets {
+			if node.NodePackets[i].SeqID() == m.Tag {
+				observed = &node.NodePackets[i]
+				break
+			}
+		}
+		if observed == nil {
+			return nil, fmt.Errorf("tag %#x of message %s is not found", m.Tag, m.Name)
+		}
+		node = observed
+	}
+	obj, err := s.decodeMessage(m, node, m.Name)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+func (s *Schema) decodeMessage(m *Message, node *codec.NodePacket, path string) (map[string]interface{}, error) {
+	obj := make(map[string]interface{}, len(m.Fields))
+	for _, f := range m.Fields {
+		fieldPath := path + "." + f.Name
+		var err error
+		switch {
+		case f.Repeated:
+			items := make([]interface{}, 0)
+			if n := findNode(node, f.Key); n != nil {
+				if f.IsScalar() {
+					for i := range n.PrimitivePackets {
+						item, err := decodeScalar(f.Type, &n.PrimitivePackets[i])
+						if err != nil {
+							return nil, fmt.Errorf("%s[%d]: %v", fieldPath, i, err)
+						}
+						items = append(items, item)
+					}
+				} else {
+					for i := range n.NodePackets {
+						item, err := s.decodeMessage(s.Message(f.Type), &n.NodePackets[i], fmt.Sprintf("%s[%d]", fieldPath, i))
+						if err != nil {
+							return nil, err
+						}
+						items = append(items, item)
+					}
+				}
+			}
+			obj[f.Name] = items
+		case f.IsScalar():
+			p := findPrimitive(node, f.Key)
+			if p == nil {
+				p = &codec.PrimitivePacket{}
+			}
+			obj[f.Name], err = decodeScalar(f.Type, p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", fieldPath, err)
+			}
+		default:
+			n := findNode(node, f.Key)
+			if n == nil {
+				n = &codec.NodePacket{}
+			}
+			obj[f.Name], err = s.decodeMessage(s.Message(f.Type), n, fieldPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return obj, nil
+}
+
+func decodeScalar(typ string, p *codec.PrimitivePacket) (interface{}, error) {
+	empty := len(p.ToBytes()) == 0
+	switch typ {
+	case TypeString:
+		return p.ToUTF8String()
+	case TypeBytes:
+		return p.ToBytes(), nil
+	case TypeBool:
+		if empty {
+			return false, nil
+		}
+		return p.ToBool()
+	}
+	if empty {
+		return 0, nil
+	}
+	switch typ {
+	case TypeInt32:
+		return p.ToInt32()
+	case TypeInt64:
+		return p.ToInt64()
+	case TypeUint32:
+		return p.ToUInt32()
+	case TypeUint64:
+		return p.ToUInt64()
+	case TypeFloat32:
+		v, err := p.ToFloat32()
+		if err != nil {
+			return nil, err
+		}
+		// keep the shortest representation of float32 in JSON
+		return json.Number(strconv.FormatFloat(float64(v), 'g', -1, 32)), checkFinite(float64(v))
+	case TypeFloat64:
+		v, err := p.ToFloat64()
+		if err != nil {
+			return nil, err
+		}
+		return v, checkFinite(v)
+	}
+	return nil, fmt.Errorf("unknown type %s", typ)
+}
+
+func checkFinite(v float64) error {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return fmt.Errorf("%v can't be represented in JSON", v)
+	}
+	return nil
+}
+
+func findPrimitive(node *codec.NodePacket, key byte) *codec.PrimitivePacket {
+	for i := range node.PrimitivePackets {
+		if node.PrimitivePackets[i].SeqID() == key {
+			return &node.PrimitivePackets[i]
+		}
+	}
+	return nil
+}
+
+func findNode(node *codec.NodePacket, key byte) *codec.NodePacket {
+	for i := range node.NodePackets {
+		if node.NodePackets[i].SeqID() == key {
+			return &node.NodePackets[i]
+		}
+	}
+	return nil
+}
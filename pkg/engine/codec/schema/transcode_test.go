@@ -0,0 +1,111 @@
+package schema
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/bhojpur/service/pkg/engine/codec/schema/internal/noise"
+	"github.com/stretchr/testify/assert"
+)
+
+const noiseJSON = `{
+	"noise": 40.5,
+	"time": 1634000000000,
+	"from": "localhost",
+	"therm": {"temperature": 30, "humidity": 40.25, "location": "room"},
+	"tags": ["a", "b"],
+	"raw": "AQI=",
+	"level": 7,
+	"valid": true,
+	"seq": 1099511627776,
+	"score": -3.25,
+	"delta": -12,
+	"history": [{"temperature": 10, "humidity": 20, "location": "hall"}],
+	"points": [-1, 0, 1]
+}`
+
+func TestEncodeJSON(t *testing.T) {
+	s, err := ParseFile("internal/noise/noise.codec")
+	assert.NoError(t, err)
+
+	buf, err := s.EncodeJSON("NoiseData", []byte(noiseJSON))
+	assert.NoError(t, err)
+
+	data := noise.NoiseData{
+		Noise:   40.5,
+		Time:    1634000000000,
+		From:    "localhost",
+		Therm:   noise.Thermometer{Temperature: 30, Humidity: 40.25, Location: "room"},
+		Tags:    []string{"a", "b"},
+		Raw:     []byte{0x01, 0x02},
+		Level:   7,
+		Valid:   true,
+		Seq:     1 << 40,
+		Score:   -3.25,
+		Delta:   -12,
+		History: []noise.Thermometer{{Temperature: 10, Humidity: 20, Location: "hall"}},
+		Points:  []int64{-1, 0, 1},
+	}
+	expected, err := data.MarshalCodec()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, buf)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	s, err := ParseFile("internal/noise/noise.codec")
+	assert.NoError(t, err)
+
+	buf, err := s.EncodeJSON("NoiseData", []byte(noiseJSON))
+	assert.NoError(t, err)
+	result, err := s.DecodeJSON("NoiseData", buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, noiseJSON, string(result))
+
+	// the missing fields are zero values
+	buf, err = s.EncodeJSON("NoiseData", []byte(`{"noise": 1}`))
+	assert.NoError(t, err)
+	result, err = s.DecodeJSON("NoiseData", buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"noise": 1, "time": 0, "from": "", "therm": {"temperature": 0, "humidity": 0, "location": ""},
+		"tags": [], "raw": null, "level": 0, "valid": false, "seq": 0, "score": 0, "delta": 0, "history": [], "points": []}`, string(result))
+}
+
+func TestEncodeJSONErrors(t *testing.T) {
+	s, err := ParseFile("internal/noise/noise.codec")
+	assert.NoError(t, err)
+
+	cases := map[string]string{
+		`{"unknown": 1}`:                       "NoiseData.unknown: field is not defined in NoiseData",
+		`{"noise": "1"}`:                       "NoiseData.noise: expected float32",
+		`{"delta": 1.5}`:                       "NoiseData.delta: strconv.ParseInt",
+		`{"tags": [1]}`:                        "NoiseData.tags[0]: expected string",
+		`{"history": [{"temperature": true}]}`: "NoiseData.history[0].temperature: expected float32",
+	}
+	for input, msg := range cases {
+		_, err := s.EncodeJSON("NoiseData", []byte(input))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), msg)
+	}
+
+	_, err = s.EncodeJSON("Thermometer", []byte(`{}`))
+	assert.EqualError(t, err, "message Thermometer has no tag")
+}
@@ -21,10 +21,12 @@ package codec
 // THE SOFTWARE.
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/bhojpur/service/pkg/engine/codec/internal/utils"
 )
@@ -192,7 +194,7 @@ func (d *structDecoderImpl) decodeStruct(data *NodePacket, val reflect.Value) er
 
 // decodeStructFromNodePacket decode struct from NodePacket
 func (d *structDecoderImpl) decodeStructFromNodePacket(fieldType reflect.Type, fieldName string, fieldValue reflect.Value, dataVal *NodePacket) error {
-	if fieldType.Kind() == reflect.Struct {
+	if fieldType.Kind() == reflect.Struct && fieldType != utils.TypeOfTime {
 		fieldValueType := fieldValue.Type()
 		for i := 0; i < fieldValueType.NumField(); i++ {
 			currentFieldName := fieldNameByTag(d.config.TagName, fieldValueType.Field(i))
@@ -256,6 +258,8 @@ func (d *structDecoderImpl) takeNodeValue(fieldType reflect.Type, nodePacket Nod
 			return d.paddingToArray(fieldType, nodePacket), true
 		case reflect.Slice:
 			return d.paddingToSlice(fieldType, nodePacket), true
+		case reflect.Map:
+			return d.paddingToMap(fieldType, nodePacket), true
 		default:
 			panic(fmt.Errorf("unimplemented type %v", fieldType.Kind()))
 		}
@@ -361,61 +365,103 @@ func (d *structDecoderImpl) paddingToSlice(fieldType reflect.Type, nodePacket No
 	return slice
 }
 
+// paddingToMap padding to map from NodePacket, every entry is a node of the key and value
+func (d *structDecoderImpl) paddingToMap(fieldType reflect.Type, nodePacket NodePacket) reflect.Value {
+	mapValue := reflect.MakeMapWithSize(fieldType, len(nodePacket.NodePackets))
+	for _, entry := range nodePacket.NodePackets {
+		key := reflect.New(fieldType.Key()).Elem()
+		value := reflect.New(fieldType.Elem()).Elem()
+		for _, p := range entry.PrimitivePackets {
+			switch p.SeqID() {
+			case utils.KeyOfMapKey:
+				k, _ := p.ToUTF8String()
+				key.SetString(k)
+			case utils.KeyOfMapValue:
+				v, _ := d.takePrimitiveValue(fieldType.Elem(), p)
+				value.Set(v)
+			}
+		}
+		for _, n := range entry.NodePackets {
+			if n.SeqID() == utils.KeyOfMapValue {
+				v, _ := d.takeNodeValue(fieldType.Elem(), n)
+				value.Set(v)
+			}
+		}
+		mapValue.SetMapIndex(key, value)
+	}
+	return mapValue
+}
+
 // takePrimitiveValue take primitive value from PrimitivePacket
 func (d *structDecoderImpl) takePrimitiveValue(fieldType reflect.Type, primitivePacket PrimitivePacket) (reflect.Value, bool) {
+	switch fieldType {
+	case utils.TypeOfTime:
+		val, err := primitivePacket.ToInt64()
+		if err != nil {
+			panic(err)
+		}
+		return reflect.ValueOf(time.Unix(0, val).UTC()), true
+	case utils.TypeOfBigInt, utils.TypeOfBigFloat, utils.TypeOfBigRat:
+		val := reflect.New(fieldType.Elem())
+		if err := val.Interface().(encoding.TextUnmarshaler).UnmarshalText(primitivePacket.ToBytes()); err != nil {
+			panic(err)
+		}
+		return val, true
+	}
+
 	switch fieldType.Kind() {
 	case reflect.String:
 		val, err := primitivePacket.ToUTF8String()
 		if err != nil {
 			panic(err)
 		}
-		return reflect.Indirect(reflect.ValueOf(val)), true
+		return reflect.ValueOf(val).Convert(fieldType), true
 	case reflect.Int32:
 		val, err := primitivePacket.ToInt32()
 		if err != nil {
 			panic(err)
 		}
-		return reflect.Indirect(reflect.ValueOf(val)), true
+		return reflect.ValueOf(val).Convert(fieldType), true
 	case reflect.Int64:
 		val, err := primitivePacket.ToInt64()
 		if err != nil {
 			panic(err)
 		}
-		return reflect.Indirect(reflect.ValueOf(val)), true
+		return reflect.ValueOf(val).Convert(fieldType), true
 	case reflect.Uint32:
 		val, err := primitivePacket.ToUInt32()
 		if err != nil {
 			panic(err)
 		}
-		return reflect.Indirect(reflect.ValueOf(val)), true
+		return reflect.ValueOf(val).Convert(fieldType), true
 	case reflect.Uint64:
 		val, err := primitivePacket.ToUInt64()
 		if err != nil {
 			panic(err)
 		}
-		return reflect.Indirect(reflect.ValueOf(val)), true
+		return reflect.ValueOf(val).Convert(fieldType), true
 	case reflect.Float32:
 		val, err := primitivePacket.ToFloat32()
 		if err != nil {
 			panic(err)
 		}
-		return reflect.Indirect(reflect.ValueOf(val)), true
+		return reflect.ValueOf(val).Convert(fieldType), true
 	case reflect.Float64:
 		val, err := primitivePacket.ToFloat64()
 		if err != nil {
 			panic(err)
 		}
-		return reflect.Indirect(reflect.ValueOf(val)), true
+		return reflect.ValueOf(val).Convert(fieldType), true
 	case reflect.Bool:
 		val, err := primitivePacket.ToBool()
 		if err != nil {
 			panic(err)
 		}
-		return reflect.Indirect(reflect.ValueOf(val)), true
+		return reflect.ValueOf(val).Convert(fieldType), true
 	case reflect.Slice:
 		if fieldType == utils.TypeOfByteSlice {
 			val := primitivePacket.ToBytes()
-			return reflect.Indirect(reflect.ValueOf(val)), true
+			return reflect.ValueOf(val).Convert(fieldType), true
 		}
 		panic(errors.New("::takeValueByKey error: no matching type"))
 	default:
@@ -434,11 +480,15 @@ func (d *structDecoderImpl) matchingKey(key byte, node *NodePacket) (flag bool,
 	}
 
 	if len(node.NodePackets) > 0 {
+		// the children of node take precedence over the descendants, e.g., the fields of map values
+		// may be keyed as the fields of node
 		for i := range node.NodePackets {
-			n := node.NodePackets[i]
-			if key == n.SeqID() {
-				return true, true, n
+			if key == node.NodePackets[i].SeqID() {
+				return true, true, node.NodePackets[i]
 			}
+		}
+		for i := range node.NodePackets {
+			n := node.NodePackets[i]
 			flag, isNode, packet = d.matchingKey(key, &n)
 			if flag {
 				return
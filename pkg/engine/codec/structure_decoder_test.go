@@ -21,8 +21,13 @@ package codec
 // THE SOFTWARE.
 
 import (
+	"errors"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 
 	"github.com/bhojpur/service/pkg/engine/codec/internal/utils"
 
@@ -632,3 +637,105 @@ func testSliceFloat64(t *testing.T, result []float64, expected []float64) {
 		}
 	}
 }
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, 10, 12, 8, 30, 0, 123, time.UTC)
+	input := tester.MapTestData{
+		Vfoo:       "foo",
+		Vtags:      map[string]string{"b": "2", "a": "1", "empty": ""},
+		Vcounts:    map[string]int64{"x": -1, "y": 1 << 40},
+		Vbasics:    map[string]tester.BasicTestData{"basic": newBasic()},
+		Vtimes:     map[string]time.Time{"now": now},
+		Vempty:     map[string]float32{},
+		Vslices:    map[string][]string{"list": {"a", "b"}},
+		Vnestedmap: map[string]map[string]bool{"outer": {"inner": true}},
+	}
+	inputBuf, _ := newStructEncoder(0x3f, structEncoderOptionRoot(utils.RootToken)).Encode(input)
+
+	var result tester.MapTestData
+	runDecode(t, inputBuf, &result)
+	assert.Equal(t, input, result)
+
+	// the entries are encoded in order of keys
+	again, _ := newStructEncoder(0x3f, structEncoderOptionRoot(utils.RootToken)).Encode(result)
+	assert.Equal(t, inputBuf, again)
+}
+
+type keyedLeaf struct {
+	Vnames []string `bhojpur:"0x11"`
+}
+
+type keyedRoot struct {
+	Vleaves map[string]keyedLeaf `bhojpur:"0x10"`
+	Vnames  []string             `bhojpur:"0x11"`
+}
+
+func TestDecode_ChildBeforeDescendant(t *testing.T) {
+	t.Parallel()
+
+	// Vnames is keyed as the field of the map values as well, the child of root is decoded
+	input := keyedRoot{
+		Vleaves: map[string]keyedLeaf{"leaf": {Vnames: []string{"descendant"}}},
+		Vnames:  []string{"child"},
+	}
+	inputBuf, _ := newStructEncoder(0x3f, structEncoderOptionRoot(utils.RootToken)).Encode(input)
+
+	var result keyedRoot
+	runDecode(t, inputBuf, &result)
+	assert.Equal(t, input, result)
+}
+
+func TestTimeAndDecimal(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, 10, 12, 8, 30, 0, 123, time.UTC)
+	input := tester.TimeTestData{
+		Vtime:      now,
+		Vduration:  90 * time.Second,
+		Vtimes:     []time.Time{now, now.Add(time.Hour)},
+		Vdurations: []time.Duration{-time.Millisecond, time.Hour},
+		Vint:       new(big.Int).Lsh(big.NewInt(1), 100),
+		Vfloat:     big.NewFloat(3.141592653589793),
+		Vrat:       big.NewRat(1, 3),
+	}
+	inputBuf, _ := newStructEncoder(0x3f, structEncoderOptionRoot(utils.RootToken)).Encode(input)
+
+	var result tester.TimeTestData
+	runDecode(t, inputBuf, &result)
+	assert.True(t, now.Equal(result.Vtime))
+	assert.True(t, result.Vzero.IsZero())
+	assert.Equal(t, input.Vduration, result.Vduration)
+	assert.Equal(t, input.Vtimes, result.Vtimes)
+	assert.Equal(t, input.Vdurations, result.Vdurations)
+	assert.Equal(t, 0, input.Vint.Cmp(result.Vint))
+	// big.Float is decoded from the decimal text in 64 bits precision
+	assert.Equal(t, input.Vfloat.Text('g', -1), result.Vfloat.Text('g', -1))
+	assert.Equal(t, 0, input.Vrat.Cmp(result.Vrat))
+	assert.Nil(t, result.Vnil)
+}
+
+func TestTimeLocationAndRange(t *testing.T) {
+	t.Parallel()
+
+	// the location is not kept, the time is decoded in UTC
+	local := time.Date(2021, 10, 12, 8, 30, 0, 0, time.FixedZone("IST", 19800))
+	inputBuf, err := newStructEncoder(0x3f, structEncoderOptionRoot(utils.RootToken)).Encode(tester.TimeTestData{Vtime: local})
+	assert.NoError(t, err)
+	var result tester.TimeTestData
+	runDecode(t, inputBuf, &result)
+	assert.True(t, local.Equal(result.Vtime))
+	assert.Equal(t, time.UTC, result.Vtime.Location())
+
+	// the times beyond the nanoseconds since Unix epoch fail instead of overflowing
+	for _, out := range []time.Time{
+		time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		_, err := newStructEncoder(0x3f, structEncoderOptionRoot(utils.RootToken)).Encode(tester.TimeTestData{Vtime: out})
+		assert.True(t, errors.Is(err, ErrTimeOutOfRange))
+		_, err = newStructEncoder(0x3f, structEncoderOptionRoot(utils.RootToken)).Encode(tester.TimeTestData{Vtimes: []time.Time{out}})
+		assert.True(t, errors.Is(err, ErrTimeOutOfRange))
+	}
+}
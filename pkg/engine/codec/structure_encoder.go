@@ -22,12 +22,20 @@ package codec
 
 import (
 	"bytes"
+	"encoding"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"time"
 
 	"github.com/bhojpur/service/pkg/engine/codec/internal/utils"
 )
 
+// ErrTimeOutOfRange is returned when a time.Time can't be encoded as nanoseconds since Unix epoch,
+// which covers the years from 1678 to 2262.
+var ErrTimeOutOfRange = errors.New("codec: time out of range")
+
 // structEncoder is a Encoder for Struct type
 type structEncoder interface {
 	// Encode encode interface to bytes
@@ -112,7 +120,18 @@ func (e structEncoderImpl) Encode(input interface{}, signals ...*signal) ([]byte
 }
 
 // encode encode interface to bytes
-func (e *structEncoderImpl) encode(input interface{}, signals []*PrimitivePacketEncoder) ([]byte, error) {
+func (e *structEncoderImpl) encode(input interface{}, signals []*PrimitivePacketEncoder) (encoded []byte, err error) {
+	// the time out of range is returned as error, the other failures still panic
+	defer func() {
+		if r := recover(); r != nil {
+			if rerr, ok := r.(error); ok && errors.Is(rerr, ErrTimeOutOfRange) {
+				encoded, err = nil, rerr
+				return
+			}
+			panic(r)
+		}
+	}()
+
 	var inputVal reflect.Value
 
 	if input != nil {
@@ -205,6 +224,40 @@ func (e *structEncoderImpl) encodeStructFromField(fieldType reflect.Type, fieldN
 		panic(fmt.Errorf("prohibit the use of this key: %v", fieldName))
 	}
 
+	switch {
+	case fieldType == utils.TypeOfTime:
+		// time.Time is encoded as nanoseconds since Unix epoch, the zero time is omitted, and the
+		// location is not kept
+		t := fieldValue.Interface().(time.Time)
+		if !t.IsZero() {
+			nanos := t.UnixNano()
+			if !time.Unix(0, nanos).Equal(t) {
+				panic(fmt.Errorf("%w: %s", ErrTimeOutOfRange, t))
+			}
+			ppe := NewPrimitivePacketEncoder(int(utils.KeyOf(fieldName)))
+			ppe.SetInt64Value(nanos)
+			en.AddPrimitivePacket(ppe)
+		}
+		return
+	case fieldType == utils.TypeOfBigInt || fieldType == utils.TypeOfBigFloat || fieldType == utils.TypeOfBigRat:
+		// big numbers are encoded as decimal text, nil is omitted
+		if !fieldValue.IsNil() {
+			text, err := fieldValue.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				panic(err)
+			}
+			ppe := NewPrimitivePacketEncoder(int(utils.KeyOf(fieldName)))
+			ppe.SetBytesValue(text)
+			en.AddPrimitivePacket(ppe)
+		}
+		return
+	case fieldType.Kind() == reflect.Map:
+		mapNode := NewNodeSlicePacketEncoder(int(utils.KeyOf(fieldName)))
+		e.encodeMapFromField(fieldValue, mapNode)
+		en.AddNodePacket(mapNode)
+		return
+	}
+
 	if fieldType.Kind() == reflect.Struct {
 		leafNode := NewNodePacketEncoder(int(utils.KeyOf(fieldName)))
 		fieldValueType := fieldValue.Type()
@@ -282,6 +335,25 @@ func (e *structEncoderImpl) encodeSliceFromField(fieldValue reflect.Value, en *N
 	}
 }
 
+// encodeMapFromField encode map from field, every entry is a node of the key and value,
+// the entries are sorted by keys
+func (e *structEncoderImpl) encodeMapFromField(fieldValue reflect.Value, en *NodePacketEncoder) {
+	if fieldValue.Type().Key().Kind() != reflect.String {
+		panic(fmt.Errorf("unsupported map key type: %v", fieldValue.Type().Key()))
+	}
+	keys := fieldValue.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, key := range keys {
+		entry := NewNodePacketEncoder(utils.KeyOfSliceItem)
+		keyPacket := NewPrimitivePacketEncoder(utils.KeyOfMapKey)
+		keyPacket.SetStringValue(key.String())
+		entry.AddPrimitivePacket(keyPacket)
+		value := fieldValue.MapIndex(key)
+		e.encodeStructFromField(value.Type(), utils.KeyStringOfMapValue, value, entry)
+		en.AddNodePacket(entry)
+	}
+}
+
 // fieldValueToString get string value from fieldValue
 func (e *structEncoderImpl) fieldValueToString(fieldType reflect.Type, fieldValue reflect.Value) string {
 	if fieldValue.IsZero() && e.config.ZeroFields {
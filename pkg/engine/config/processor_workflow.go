@@ -21,11 +21,18 @@ package config
 // THE SOFTWARE.
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 // App represents a Bhojpur Service workflow application.
@@ -50,37 +57,244 @@ type WorkflowConfig struct {
 	Workflow `yaml:",inline"`
 }
 
-// LoadWorkflowConfig the WorkflowConfig by path.
+// workflowFile is the content of a single workflow config file.
+type workflowFile struct {
+	WorkflowConfig `yaml:",inline"`
+	// Include represents the workflow config files to be included, the paths are relative
+	// to the including file.
+	Include []string `yaml:"include"`
+}
+
+// position is the location of a value in the workflow config files.
+type position struct {
+	file string
+	line int
+}
+
+// source records where the values of a WorkflowConfig are defined.
+type source struct {
+	file      string
+	name      position
+	host      position
+	port      position
+	functions []position
+}
+
+// ValidationError describes a problem of the workflow config.
+type ValidationError struct {
+	// File is the workflow config file.
+	File string
+	// Line is the line number in File, 0 means the problem is not located at a specific line.
+	Line int
+	// Msg describes the problem.
+	Msg string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Msg)
+}
+
+// ValidationErrors is the list of problems of the workflow config.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *ValidationErrors) add(pos position, format string, args ...interface{}) {
+	*e = append(*e, &ValidationError{File: pos.file, Line: pos.line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// LoadWorkflowConfig the WorkflowConfig by path. The environment variables in form of `${ENV}` or
+// `${ENV:-default}` are interpolated, and the files listed in `include:` are merged into the config.
 func LoadWorkflowConfig(path string) (*WorkflowConfig, error) {
-	file, err := os.Open(path)
+	config, _, err := loadWorkflowConfig(path)
+	return config, err
+}
+
+func loadWorkflowConfig(path string) (*WorkflowConfig, *source, error) {
+	config := &WorkflowConfig{}
+	src := &source{file: path}
+	var errs ValidationErrors
+	loadFile(path, config, src, map[string]bool{}, &errs)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	return config, src, nil
+}
+
+// loadFile loads the workflow config file and its includes into config. The values already
+// set in config take precedence, the functions are appended after the existing ones.
+func loadFile(path string, config *WorkflowConfig, src *source, visiting map[string]bool, errs *ValidationErrors) {
+	abs, err := filepath.Abs(path)
 	if err != nil {
-		return nil, err
+		errs.add(position{file: path}, "%v", err)
+		return
+	}
+	if visiting[abs] {
+		errs.add(position{file: path}, "include cycle detected")
+		return
 	}
-	defer file.Close()
+	visiting[abs] = true
+	defer delete(visiting, abs)
 
-	fileInfo, err := file.Stat()
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		return nil, err
+		errs.add(position{file: path}, "%v", err)
+		return
+	}
+	data, ok := interpolate(path, data, errs)
+	if !ok {
+		return
+	}
+	wf, node, ok := decode(path, data, errs)
+	if !ok {
+		return
 	}
 
-	fileSize := fileInfo.Size()
-	buffer := make([]byte, fileSize)
+	if config.Name == "" && wf.Name != "" {
+		config.Name = wf.Name
+		src.name = position{path, keyLine(node, "name")}
+	}
+	if config.Host == "" && wf.Host != "" {
+		config.Host = wf.Host
+		src.host = position{path, keyLine(node, "host")}
+	}
+	if config.Port == 0 && wf.Port != 0 {
+		config.Port = wf.Port
+		src.port = position{path, keyLine(node, "port")}
+	}
+	lines := functionLines(node)
+	for i, app := range wf.Functions {
+		config.Functions = append(config.Functions, app)
+		pos := position{file: path}
+		if i < len(lines) {
+			pos.line = lines[i]
+		}
+		src.functions = append(src.functions, pos)
+	}
 
-	_, err = file.Read(buffer)
-	if err != nil {
-		return nil, err
+	for _, include := range wf.Include {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		loadFile(include, config, src, visiting, errs)
 	}
+}
 
-	return load(buffer)
+// decode decodes the workflow config file strictly, unknown keys are reported as errors.
+func decode(path string, data []byte, errs *ValidationErrors) (*workflowFile, *yaml.Node, bool) {
+	wf := &workflowFile{}
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(data, node); err != nil {
+		addYAMLError(path, err, errs)
+		return nil, nil, false
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(wf); err != nil {
+		// empty file
+		if errors.Is(err, io.EOF) {
+			return wf, node, true
+		}
+		addYAMLError(path, err, errs)
+		return nil, nil, false
+	}
+	return wf, node, true
 }
 
-func load(data []byte) (*WorkflowConfig, error) {
-	var config = &WorkflowConfig{}
-	err := yaml.Unmarshal(data, config)
-	if err != nil {
-		return nil, err
+var (
+	yamlLineRegexp     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	yamlUnknownFieldRe = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+)
+
+// addYAMLError converts the errors of yaml package to ValidationErrors.
+func addYAMLError(path string, err error, errs *ValidationErrors) {
+	msgs := []string{err.Error()}
+	if e, ok := err.(*yaml.TypeError); ok {
+		msgs = e.Errors
+	}
+	for _, msg := range msgs {
+		pos := position{file: path}
+		if m := yamlLineRegexp.FindStringSubmatch(msg); m != nil {
+			pos.line, _ = strconv.Atoi(m[1])
+			msg = m[2]
+		}
+		if m := yamlUnknownFieldRe.FindStringSubmatch(msg); m != nil {
+			msg = fmt.Sprintf("unknown key %q", m[1])
+		}
+		errs.add(pos, "%s", strings.TrimPrefix(msg, "yaml: "))
+	}
+}
+
+// mappingValue returns the key and value nodes of key in the top level mapping.
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func keyLine(node *yaml.Node, key string) int {
+	if k, _ := mappingValue(node, key); k != nil {
+		return k.Line
 	}
-	return config, nil
+	return 0
+}
+
+func functionLines(node *yaml.Node) []int {
+	lines := make([]int, 0)
+	_, functions := mappingValue(node, "functions")
+	if functions == nil {
+		return lines
+	}
+	for _, fn := range functions.Content {
+		lines = append(lines, fn.Line)
+	}
+	return lines
+}
+
+var envRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}\n]*))?\}`)
+
+// interpolate replaces `${ENV}` and `${ENV:-default}` with the value of the environment variables,
+// the default value is used when the variable is unset or empty.
+func interpolate(path string, data []byte, errs *ValidationErrors) ([]byte, bool) {
+	ok := true
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		// skip comments
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			continue
+		}
+		lines[i] = envRegexp.ReplaceAllFunc(line, func(match []byte) []byte {
+			m := envRegexp.FindSubmatch(match)
+			if val := os.Getenv(string(m[1])); val != "" {
+				return []byte(val)
+			}
+			if len(m[2]) > 0 {
+				return m[3]
+			}
+			errs.add(position{path, i + 1}, "environment variable %s is not set", m[1])
+			ok = false
+			return match
+		})
+	}
+	return bytes.Join(lines, []byte("\n")), ok
 }
 
 // ParseWorkflowConfig parses the config.
@@ -90,13 +304,13 @@ func ParseWorkflowConfig(config string) (*WorkflowConfig, error) {
 	}
 
 	// parse workflow.yaml
-	wfConf, err := LoadWorkflowConfig(config)
+	wfConf, src, err := loadWorkflowConfig(config)
 	if err != nil {
 		return nil, err
 	}
 
 	// validate
-	err = validateWorkflowConfig(wfConf)
+	err = validateWorkflowConfig(wfConf, src)
 	if err != nil {
 		return nil, err
 	}
@@ -104,36 +318,41 @@ func ParseWorkflowConfig(config string) (*WorkflowConfig, error) {
 	return wfConf, nil
 }
 
-func validateWorkflowConfig(wfConf *WorkflowConfig) error {
+func validateWorkflowConfig(wfConf *WorkflowConfig, src *source) error {
 	if wfConf == nil {
 		return errors.New("conf is nil")
 	}
 
-	m := map[string][]App{
-		"Functions": wfConf.Functions,
+	var errs ValidationErrors
+	main := position{file: src.file}
+	if wfConf.Name == "" {
+		errs.add(main, "missing processor name")
 	}
-
-	missingParams := []string{}
-	for k, apps := range m {
-		for _, app := range apps {
-			if app.Name == "" {
-				missingParams = append(missingParams, k)
-			}
-		}
+	if wfConf.Host == "" {
+		errs.add(main, "missing processor host")
 	}
-
-	errMsg := ""
-	if wfConf.Name == "" || wfConf.Host == "" || wfConf.Port <= 0 {
-		errMsg = "Missing name, host or port in workflow config. "
+	if wfConf.Port == 0 {
+		errs.add(main, "missing processor port")
+	} else if wfConf.Port < 0 || wfConf.Port > 65535 {
+		errs.add(src.port, "port %d is out of range [1, 65535]", wfConf.Port)
 	}
 
-	if len(missingParams) > 0 {
-		errMsg += "Missing name, host or port in " + strings.Join(missingParams, ", "+". ")
+	names := make(map[string]position)
+	for i, app := range wfConf.Functions {
+		pos := src.functions[i]
+		if app.Name == "" {
+			errs.add(pos, "missing name of function #%d", i+1)
+			continue
+		}
+		if first, ok := names[app.Name]; ok {
+			errs.add(pos, "duplicate function name %q, first defined at %s:%d", app.Name, first.file, first.line)
+			continue
+		}
+		names[app.Name] = pos
 	}
 
-	if errMsg != "" {
-		return errors.New(errMsg)
+	if len(errs) > 0 {
+		return errs
 	}
-
 	return nil
 }
@@ -0,0 +1,196 @@
+package config
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestParseWorkflowConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "workflow.yaml", `name: Service
+host: localhost
+port: 9140
+functions:
+  - name: Noise
+  - name: MockDB
+`)
+	conf, err := ParseWorkflowConfig(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "Service", conf.Name)
+	assert.Equal(t, "localhost", conf.Host)
+	assert.Equal(t, 9140, conf.Port)
+	assert.Equal(t, []App{{Name: "Noise"}, {Name: "MockDB"}}, conf.Functions)
+
+	_, err = ParseWorkflowConfig(filepath.Join(dir, "workflow.json"))
+	assert.NotNil(t, err)
+}
+
+func TestValidateWorkflowConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "workflow.yaml", `name: Service
+host: localhost
+port: 70000
+functions:
+  - name: Noise
+  - name: MockDB
+  - name: Noise
+  - name: ""
+`)
+	_, err := ParseWorkflowConfig(path)
+	assert.Equal(t, ValidationErrors{
+		{File: path, Line: 3, Msg: "port 70000 is out of range [1, 65535]"},
+		{File: path, Line: 7, Msg: `duplicate function name "Noise", first defined at ` + path + ":5"},
+		{File: path, Line: 8, Msg: "missing name of function #4"},
+	}, err)
+
+	path = writeFile(t, dir, "missing.yaml", `functions:
+  - name: Noise
+`)
+	_, err = ParseWorkflowConfig(path)
+	assert.Equal(t, ValidationErrors{
+		{File: path, Msg: "missing processor name"},
+		{File: path, Msg: "missing processor host"},
+		{File: path, Msg: "missing processor port"},
+	}, err)
+	assert.Equal(t, path+": missing processor name\n"+path+": missing processor host\n"+path+": missing processor port", err.Error())
+}
+
+func TestWorkflowConfigUnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "workflow.yaml", `name: Service
+host: localhost
+prot: 9140
+functions:
+  - name: Noise
+    tag: 0x33
+`)
+	_, err := ParseWorkflowConfig(path)
+	assert.Equal(t, ValidationErrors{
+		{File: path, Line: 3, Msg: `unknown key "prot"`},
+		{File: path, Line: 6, Msg: `unknown key "tag"`},
+	}, err)
+
+	path = writeFile(t, dir, "syntax.yaml", `name: Service
+host: [localhost
+`)
+	_, err = ParseWorkflowConfig(path)
+	assert.IsType(t, ValidationErrors{}, err)
+	assert.Equal(t, path, err.(ValidationErrors)[0].File)
+	assert.Greater(t, err.(ValidationErrors)[0].Line, 0)
+}
+
+func TestWorkflowConfigInterpolation(t *testing.T) {
+	os.Setenv("BHOJPUR_TEST_PROCESSOR_HOST", "0.0.0.0")
+	defer os.Unsetenv("BHOJPUR_TEST_PROCESSOR_HOST")
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "workflow.yaml", `name: ${BHOJPUR_TEST_PROCESSOR_NAME:-Service}
+host: ${BHOJPUR_TEST_PROCESSOR_HOST}
+port: ${BHOJPUR_TEST_PROCESSOR_PORT:-9140}
+# ${BHOJPUR_TEST_IN_COMMENT}
+functions:
+  - name: Noise
+`)
+	conf, err := ParseWorkflowConfig(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "Service", conf.Name)
+	assert.Equal(t, "0.0.0.0", conf.Host)
+	assert.Equal(t, 9140, conf.Port)
+
+	path = writeFile(t, dir, "unset.yaml", `name: Service
+host: ${BHOJPUR_TEST_UNSET_HOST}
+port: 9140
+`)
+	_, err = ParseWorkflowConfig(path)
+	assert.Equal(t, ValidationErrors{
+		{File: path, Line: 2, Msg: "environment variable BHOJPUR_TEST_UNSET_HOST is not set"},
+	}, err)
+}
+
+func TestWorkflowConfigInclude(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "functions"), 0755))
+	fns := writeFile(t, dir, "functions/noise.yaml", `functions:
+  - name: Noise
+  - name: MockDB
+`)
+	path := writeFile(t, dir, "workflow.yaml", `name: Service
+host: localhost
+port: 9140
+include:
+  - functions/noise.yaml
+functions:
+  - name: Filter
+  - name: Noise
+`)
+	conf, err := LoadWorkflowConfig(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []App{{Name: "Filter"}, {Name: "Noise"}, {Name: "Noise"}, {Name: "MockDB"}}, conf.Functions)
+
+	_, err = ParseWorkflowConfig(path)
+	assert.Equal(t, ValidationErrors{
+		{File: fns, Line: 2, Msg: `duplicate function name "Noise", first defined at ` + path + ":8"},
+	}, err)
+
+	// include cycle
+	writeFile(t, dir, "a.yaml", "include: [b.yaml]\n")
+	b := writeFile(t, dir, "b.yaml", "include: [a.yaml]\n")
+	_, err = LoadWorkflowConfig(b)
+	assert.Equal(t, ValidationErrors{
+		{File: b, Msg: "include cycle detected"},
+	}, err)
+}
+
+func TestValidateWorkflowFiles(t *testing.T) {
+	dir := t.TempDir()
+	valid := writeFile(t, dir, "workflow.yaml", `name: Service
+host: localhost
+port: 9140
+functions:
+  - name: Noise
+`)
+	invalid := writeFile(t, dir, "invalid.yaml", `name: Service
+host: localhost
+port: 70000
+`)
+
+	var out bytes.Buffer
+	assert.True(t, ValidateWorkflowFiles(&out, valid))
+	assert.Contains(t, out.String(), "processor Service with 1 stream functions is valid")
+
+	out.Reset()
+	assert.False(t, ValidateWorkflowFiles(&out, valid, invalid, filepath.Join(dir, "missing.yaml")))
+	assert.Contains(t, out.String(), invalid+":3: port 70000 is out of range [1, 65535]")
+	assert.Contains(t, out.String(), "missing.yaml")
+}
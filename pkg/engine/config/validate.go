@@ -0,0 +1,49 @@
+package config
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"io"
+
+	"github.com/bhojpur/service/pkg/utils"
+)
+
+// ValidateWorkflowFiles parses and validates the workflow config files, reports the result of each
+// file to w, and returns false if any of them is invalid.
+func ValidateWorkflowFiles(w io.Writer, files ...string) bool {
+	valid := true
+	for _, file := range files {
+		conf, err := ParseWorkflowConfig(file)
+		if errs, ok := err.(ValidationErrors); ok {
+			for _, e := range errs {
+				utils.FailureStatusEvent(w, e.Error())
+			}
+			valid = false
+			continue
+		} else if err != nil {
+			utils.FailureStatusEvent(w, "%s: %s", file, err.Error())
+			valid = false
+			continue
+		}
+		utils.SuccessStatusEvent(w, "%s: processor %s with %d stream functions is valid", file, conf.Name, len(conf.Functions))
+	}
+	return valid
+}
@@ -56,6 +56,21 @@ type Client struct {
 	opts       ClientOptions
 	localAddr  string // client local addr, it will be changed on reconnect
 	logger     log.Logger
+	// compression is the payload compression accepted by the server
+	compression frame.Compression
+	// cipher encrypts the payload end-to-end, nil if the encryption is disabled
+	cipher *payloadCipher
+	// lanes are the streams of priority lanes besides the main stream
+	lanes map[frame.Lane]*laneStream
+	// err is the error which closed the connection
+	err     *Error
+	errorfn func(error)
+}
+
+// laneStream is the QUIC stream of a priority lane.
+type laneStream struct {
+	stream quic.Stream
+	mu     sync.Mutex
 }
 
 // NewClient creates a new Bhojpur Service-Client.
@@ -118,6 +133,11 @@ func (c *Client) connect(ctx context.Context, addr string) error {
 
 	c.stream = stream
 	c.session = session
+	c.mu.Lock()
+	c.lanes = nil
+	c.mu.Unlock()
+	// the payload will be compressed after the server accepts the compression
+	c.setCompression(frame.CompressionNone)
 
 	c.state = ConnStateAuthenticating
 	// send handshake
@@ -129,6 +149,7 @@ func (c *Client) connect(ctx context.Context, addr string) error {
 		byte(c.opts.Credential.Type()),
 		c.opts.Credential.Payload(),
 	)
+	handshake.Compression = c.opts.Compression
 	err = c.WriteFrame(handshake)
 	if err != nil {
 		c.state = ConnStateRejected
@@ -139,7 +160,7 @@ func (c *Client) connect(ctx context.Context, addr string) error {
 
 	c.logger.Printf("%s❤️  [%s](%s) is connected to Bhojpur Service-Processor %s", ClientLogPrefix, c.name, c.localAddr, addr)
 
-	// receiving frames
+	// receiving frames, the priority lanes are opened after the server accepts them
 	go c.handleFrame()
 
 	return nil
@@ -155,37 +176,35 @@ func (c *Client) handleFrame() {
 		f, err := fs.ReadFrame()
 		if err != nil {
 			defer c.stream.Close()
-			defer c.session.CloseWithError(0xD0, err.Error())
+			defer c.session.CloseWithError(quic.ApplicationErrorCode(ErrorCodeGoaway), err.Error())
 
 			c.logger.Infof("%shandleFrame(): %T | %v", ClientLogPrefix, err, err)
-			if e, ok := err.(*quic.IdleTimeoutError); ok {
-				c.logger.Errorf("%s>>1 connection timeout, err=%v, processor=%s", ClientLogPrefix, e, c.addr)
-				c.setState(ConnStateDisconnected)
-			} else if e, ok := err.(*quic.ApplicationError); ok {
-				c.logger.Infof("%s>>2 application error, err=%v, errcode=%v", ClientLogPrefix, e, e.ErrorCode)
-				if e.ErrorCode == 0xCC {
-					c.logger.Errorf("%sIllegal client, server rejected.", ClientLogPrefix)
-					// stop reconnect policy
-					c.setState(ConnStateAborted)
-					break
-				} else if e.ErrorCode == 0x00 {
-					// client abort
-					c.logger.Infof("%sclient close the connection", ClientLogPrefix)
-					c.setState(ConnStateAborted)
-					break
+			e := ErrorFrom(err)
+			if e == nil {
+				if _, ok := err.(*quic.IdleTimeoutError); ok {
+					c.logger.Errorf("%sconnection timeout, err=%v, processor=%s", ClientLogPrefix, err, c.addr)
+					e = NewError(ErrorCodeClosed, err.Error())
+				} else if errors.Is(err, net.ErrClosed) {
+					// if client close the connection, net.ErrClosed will be raise
+					// by quic-go IdleTimeoutError after connection's KeepAlive config.
+					c.logger.Errorf("%sconnection is closed, err=%v", ClientLogPrefix, err)
+					e = NewError(ErrorCodeClosed, err.Error())
+				} else {
+					e = NewError(ErrorCodeUnknown, err.Error())
 				}
-			} else if errors.Is(err, net.ErrClosed) {
-				// if client close the connection, net.ErrClosed will be raise
-				c.logger.Errorf("%s>>3 connection is closed, err=%v", ClientLogPrefix, err)
+			}
+			// the client which is rejected is not reconnected, the retry fails as well
+			if e.Retryable() {
+				c.logger.Errorf("%sconnection error, will reconnect: %v", ClientLogPrefix, e)
 				c.setState(ConnStateDisconnected)
-				// by quic-go IdleTimeoutError after connection's KeepAlive config.
-				break
+			} else if e.Code == ErrorCodeClientAbort {
+				c.logger.Infof("%sclient close the connection", ClientLogPrefix)
+				c.setState(ConnStateAborted)
+			} else {
+				c.logger.Errorf("%sserver closed the connection, stop reconnecting: %v", ClientLogPrefix, e)
+				c.setState(ConnStateAborted)
 			}
-			c.logger.Infof("%s>>4 xxx, err=%v", ClientLogPrefix, err)
-			// any error occurred, we should close the session
-			// after this, session.AcceptStream() will raise the error
-			// which specific in session.CloseWithError()
-			c.setState(ConnStateDisconnected)
+			c.setError(e)
 			break
 		}
 
@@ -198,20 +217,24 @@ func (c *Client) handleFrame() {
 			c.setState(ConnStatePong)
 		case frame.TagOfAcceptedFrame:
 			c.setState(ConnStateAccepted)
+			if v, ok := f.(*frame.AcceptedFrame); ok {
+				if v.Compression != frame.CompressionNone {
+					c.logger.Printf("%spayload compression: %s", ClientLogPrefix, v.Compression)
+					c.setCompression(v.Compression)
+				}
+				// a server which does not support lanes never reads their streams, so all
+				// the frames are written on the main stream
+				if v.Lanes {
+					go c.openLanes(c.session)
+				}
+			}
 		case frame.TagOfRejectedFrame:
 			c.setState(ConnStateRejected)
 			c.Close()
 		case frame.TagOfDataFrame: // DataFrame carries user's data
 			if v, ok := f.(*frame.DataFrame); ok {
 				c.setState(ConnStateTransportData)
-				c.logger.Debugf("%sreceive DataFrame, tag=%# x, tid=%s, carry=%# x", ClientLogPrefix, v.GetDataTag(), v.TransactionID(), v.GetCarriage())
-				if c.processor == nil {
-					c.logger.Warnf("%sprocessor is nil", ClientLogPrefix)
-				} else {
-					// TODO: should c.processor accept a DataFrame as parameter?
-					// c.processor(v.GetDataTagID(), v.GetCarriage(), v.GetMetaFrame())
-					c.processor(v)
-				}
+				c.handleDataFrame(v)
 			}
 		default:
 			c.logger.Errorf("%sunknown signal", ClientLogPrefix)
@@ -219,6 +242,79 @@ func (c *Client) handleFrame() {
 	}
 }
 
+// handleDataFrame passes the DataFrame to the processor.
+func (c *Client) handleDataFrame(v *frame.DataFrame) {
+	// the encrypted and compressed carriage is transparent to user
+	v, err := c.decrypt(v)
+	if err != nil {
+		c.logger.Errorf("%sdecrypt DataFrame: %v", ClientLogPrefix, err)
+		return
+	}
+	v, err = v.Decompress()
+	if err != nil {
+		c.logger.Errorf("%sdecompress DataFrame: %v", ClientLogPrefix, err)
+		return
+	}
+	c.logger.Debugf("%sreceive DataFrame, tag=%# x, tid=%s, carry=%# x", ClientLogPrefix, v.GetDataTag(), v.TransactionID(), v.GetCarriage())
+	if c.processor == nil {
+		c.logger.Warnf("%sprocessor is nil", ClientLogPrefix)
+	} else {
+		// TODO: should c.processor accept a DataFrame as parameter?
+		// c.processor(v.GetDataTagID(), v.GetCarriage(), v.GetMetaFrame())
+		c.processor(v)
+	}
+}
+
+// openLanes opens a QUIC stream for each priority lane when the server accepts lanes, the
+// frames of the lane are written on it, so they are not blocked by the frames of the main
+// stream. The lane falls back to the main stream until it's opened, or if it can't be opened.
+func (c *Client) openLanes(session quic.Session) {
+	lanes := make(map[frame.Lane]*laneStream)
+	for _, lane := range frame.Lanes {
+		stream, err := session.OpenStreamSync(session.Context())
+		if err != nil {
+			c.logger.Errorf("%sopen %s lane: %v", ClientLogPrefix, lane, err)
+			continue
+		}
+		if _, err := stream.Write(frame.NewLaneFrame(lane).Encode()); err != nil {
+			c.logger.Errorf("%sopen %s lane: %v", ClientLogPrefix, lane, err)
+			stream.Close()
+			continue
+		}
+		lanes[lane] = &laneStream{stream: stream}
+		go c.handleLane(lane, stream)
+	}
+	c.mu.Lock()
+	c.lanes = lanes
+	c.mu.Unlock()
+}
+
+// handleLane handles the DataFrames received on a lane, the connection state is
+// maintained by the main stream.
+func (c *Client) handleLane(lane frame.Lane, stream quic.Stream) {
+	fs := NewFrameStream(stream)
+	for {
+		f, err := fs.ReadFrame()
+		if err != nil {
+			c.logger.Debugf("%s%s lane is closed: %v", ClientLogPrefix, lane, err)
+			return
+		}
+		if v, ok := f.(*frame.DataFrame); ok {
+			c.handleDataFrame(v)
+		}
+	}
+}
+
+// lane returns the stream of lane, nil if the lane is not opened.
+func (c *Client) lane(lane frame.Lane) *laneStream {
+	if lane == frame.LaneNormal {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lanes[lane]
+}
+
 // Close the client.
 func (c *Client) Close() (err error) {
 	c.logger.Printf("%sclose the connection, name:%s, addr:%s", ClientLogPrefix, c.name, c.session.RemoteAddr().String())
@@ -228,8 +324,13 @@ func (c *Client) Close() (err error) {
 			c.logger.Errorf("%s stream.Close(): %v", ClientLogPrefix, err)
 		}
 	}
+	c.mu.Lock()
+	for _, lane := range c.lanes {
+		lane.stream.Close()
+	}
+	c.mu.Unlock()
 	if c.session != nil {
-		err = c.session.CloseWithError(0, "client-ask-to-close-this-session")
+		err = c.session.CloseWithError(quic.ApplicationErrorCode(ErrorCodeClientAbort), "client-ask-to-close-this-session")
 		if err != nil {
 			c.logger.Errorf("%s session.Close(): %v", ClientLogPrefix, err)
 		}
@@ -249,16 +350,50 @@ func (c *Client) WriteFrame(frm frame.Frame) error {
 	if c.stream == nil {
 		return errors.New("stream is nil")
 	}
+	if c.state == ConnStateAborted {
+		if err := c.Err(); err != nil {
+			return err
+		}
+	}
 	if c.state == ConnStateDisconnected || c.state == ConnStateRejected {
 		return fmt.Errorf("client connection state is %s", c.state)
 	}
 	c.logger.Debugf("%s[%s](%s)@%s WriteFrame() will write frame: %s", ClientLogPrefix, c.name, c.localAddr, c.state, frm.Type())
 
+	if f, ok := frm.(*frame.DataFrame); ok {
+		f, err := c.compress(f)
+		if err != nil {
+			c.logger.Errorf("%sWriteFrame() compress error=%v", ClientLogPrefix, err)
+			return err
+		}
+		// the compressed carriage is encrypted
+		if c.cipher != nil {
+			f, err = c.cipher.encrypt(f)
+			if err != nil {
+				c.logger.Errorf("%sWriteFrame() encrypt error=%v", ClientLogPrefix, err)
+				return err
+			}
+		}
+		frm = f
+	}
+
 	data := frm.Encode()
-	// emit raw bytes of Frame
-	c.mu.Lock()
-	n, err := c.stream.Write(data)
-	c.mu.Unlock()
+	// emit raw bytes of Frame, the DataFrame with priority is written on its lane
+	var lane *laneStream
+	if f, ok := frm.(*frame.DataFrame); ok {
+		lane = c.lane(f.Lane())
+	}
+	var n int
+	var err error
+	if lane != nil {
+		lane.mu.Lock()
+		n, err = lane.stream.Write(data)
+		lane.mu.Unlock()
+	} else {
+		c.mu.Lock()
+		n, err = c.stream.Write(data)
+		c.mu.Unlock()
+	}
 	c.logger.Debugf("%sWriteFrame() wrote n=%d, data=%# x", ClientLogPrefix, n, frame.Shortly(data))
 	if err != nil {
 		c.setState(ConnStateDisconnected)
@@ -286,6 +421,81 @@ func (c *Client) setState(state ConnState) {
 	c.mu.Unlock()
 }
 
+// setError records the error which closed the connection, and notifies the error handler
+// unless the client closes the connection itself.
+func (c *Client) setError(e *Error) {
+	c.mu.Lock()
+	c.err = e
+	fn := c.errorfn
+	c.mu.Unlock()
+	if fn != nil && !(e.Code == ErrorCodeClientAbort && !e.Remote) {
+		fn(e)
+	}
+}
+
+// Err returns the error which closed the connection, it's an *Error which can be
+// matched by errors.Is, e.g., errors.Is(err, core.ErrRejected).
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		return nil
+	}
+	return c.err
+}
+
+// SetErrorHandler sets the handler which is invoked when the connection is closed by an error.
+func (c *Client) SetErrorHandler(fn func(err error)) {
+	c.mu.Lock()
+	c.errorfn = fn
+	c.mu.Unlock()
+}
+
+// update the payload compression accepted by server
+func (c *Client) setCompression(compression frame.Compression) {
+	c.mu.Lock()
+	c.compression = compression
+	c.mu.Unlock()
+}
+
+// compress the carriage of DataFrame by the compression accepted by server. The carriage
+// compressed by another algorithm, which is forwarded from other clients, is decompressed first.
+func (c *Client) compress(f *frame.DataFrame) (*frame.DataFrame, error) {
+	c.mu.Lock()
+	compression := c.compression
+	c.mu.Unlock()
+
+	var err error
+	if f.Compression() != frame.CompressionNone && f.Compression() != compression {
+		f, err = f.Decompress()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f.Compress(compression, c.opts.CompressionThreshold)
+}
+
+// decrypt the carriage of DataFrame by the key ID carried in the metadata.
+func (c *Client) decrypt(f *frame.DataFrame) (*frame.DataFrame, error) {
+	if !f.Encrypted() {
+		return f, nil
+	}
+	if c.cipher == nil {
+		return nil, fmt.Errorf("%w, key id=%s, the client has no secret store", frame.ErrEncryptedCarriage, f.EncryptionKeyID())
+	}
+	return c.cipher.decrypt(f)
+}
+
+// SetEncryptionKeyID rotates the key which encrypts the payload, the receivers look up
+// the new key by the key ID carried in the metadata.
+func (c *Client) SetEncryptionKeyID(keyID string) error {
+	if c.cipher == nil {
+		return errors.New("encryption is not enabled, use WithEncryption option")
+	}
+	c.cipher.setKeyID(keyID)
+	return nil
+}
+
 // update connection local addr
 func (c *Client) setLocalAddr(addr string) {
 	c.mu.Lock()
@@ -304,6 +514,10 @@ func (c *Client) reconnect(ctx context.Context, addr string) {
 	t := time.NewTicker(1 * time.Second)
 	defer t.Stop()
 	for range t.C {
+		// the aborted connection is not retryable
+		if c.state == ConnStateAborted {
+			return
+		}
 		if c.state == ConnStateDisconnected {
 			c.logger.Printf("%s[%s](%s) is reconnecting to Bhojpur Service-Processor %s...\n", ClientLogPrefix, c.name, c.localAddr, addr)
 			err := c.connect(ctx, addr)
@@ -369,6 +583,14 @@ func (c *Client) initOptions() error {
 			DisablePathMTUDiscovery:        true,
 		}
 	}
+	// compression threshold
+	if c.opts.CompressionThreshold <= 0 {
+		c.opts.CompressionThreshold = DefaultCompressionThreshold
+	}
+	// end-to-end encryption
+	if c.opts.SecretStore != nil && c.cipher == nil {
+		c.cipher = newPayloadCipher(c.opts.SecretStore, c.opts.EncryptionKeyID, c.opts.EncryptionTags)
+	}
 	// credential
 	if c.opts.Credential != nil {
 		c.logger.Printf("%suse credential: [%s]", ClientLogPrefix, c.opts.Credential.Type())
@@ -24,16 +24,26 @@ import (
 	"crypto/tls"
 
 	"github.com/bhojpur/service/pkg/engine/core/auth"
+	"github.com/bhojpur/service/pkg/engine/core/frame"
 	"github.com/bhojpur/service/pkg/engine/core/log"
+	"github.com/bhojpur/service/pkg/secretstores"
 	"github.com/lucas-clemente/quic-go"
 )
 
+// DefaultCompressionThreshold is the default minimal carriage size to be compressed.
+const DefaultCompressionThreshold = 512
+
 type ClientOptions struct {
-	ObserveDataTags []byte
-	QuicConfig      *quic.Config
-	TLSConfig       *tls.Config
-	Credential      auth.Credential
-	Logger          log.Logger
+	ObserveDataTags      []byte
+	QuicConfig           *quic.Config
+	TLSConfig            *tls.Config
+	Credential           auth.Credential
+	Logger               log.Logger
+	Compression          frame.Compression
+	CompressionThreshold int
+	SecretStore          secretstores.SecretStore
+	EncryptionKeyID      string
+	EncryptionTags       []byte
 }
 
 // WithObserveDataTags sets data tag list for the client.
@@ -70,3 +80,30 @@ func WithLogger(logger log.Logger) ClientOption {
 		o.Logger = logger
 	}
 }
+
+// WithCompression requests the payload compression algorithm for the client, the server
+// which does not support it keeps the payload uncompressed.
+func WithCompression(c frame.Compression) ClientOption {
+	return func(o *ClientOptions) {
+		o.Compression = c
+	}
+}
+
+// WithCompressionThreshold sets the minimal carriage size to be compressed.
+func WithCompressionThreshold(n int) ClientOption {
+	return func(o *ClientOptions) {
+		o.CompressionThreshold = n
+	}
+}
+
+// WithEncryption encrypts the payload end-to-end by the AEAD key, which is looked up by
+// keyID from the secret store. Only the payload of tags is encrypted, or all of the
+// payload if tags is empty. The client decrypts the payload it receives by the key ID
+// carried in the metadata, so an empty keyID only enables the decryption.
+func WithEncryption(store secretstores.SecretStore, keyID string, tags ...byte) ClientOption {
+	return func(o *ClientOptions) {
+		o.SecretStore = store
+		o.EncryptionKeyID = keyID
+		o.EncryptionTags = tags
+	}
+}
@@ -44,6 +44,10 @@ func (a *app) Name() string {
 	return a.name
 }
 
+func (a *app) ObserveDataTags() []byte {
+	return a.observed
+}
+
 var _ Connector = &connector{}
 
 // Connector is a interface to manage the connections and applications.
@@ -58,8 +62,14 @@ type Connector interface {
 	GetConnIDs(appID string, name string, tags byte) []string
 	// Write a DataFrame to a connection.
 	Write(f *frame.DataFrame, toID string) error
+	// SetCompression sets the payload compression accepted by a connection.
+	SetCompression(connID string, compression frame.Compression)
+	// AddLane adds the stream of a priority lane to a connection.
+	AddLane(connID string, lane frame.Lane, stream io.ReadWriteCloser)
 	// GetSnapshot gets the snapshot of all connections.
 	GetSnapshot() map[string]io.ReadWriteCloser
+	// GetAppSnapshot gets the snapshot of all linked apps, keyed by connID.
+	GetAppSnapshot() map[string]*app
 
 	// App gets the app by connID.
 	App(connID string) (*app, bool)
@@ -77,14 +87,18 @@ type Connector interface {
 }
 
 type connector struct {
-	conns sync.Map
-	apps  sync.Map
+	conns        sync.Map
+	apps         sync.Map
+	compressions sync.Map
+	lanes        sync.Map
 }
 
 func newConnector() Connector {
 	return &connector{
-		conns: sync.Map{},
-		apps:  sync.Map{},
+		conns:        sync.Map{},
+		apps:         sync.Map{},
+		compressions: sync.Map{},
+		lanes:        sync.Map{},
 	}
 }
 
@@ -100,6 +114,10 @@ func (c *connector) Remove(connID string) {
 	c.conns.Delete(connID)
 	// c.funcs.Delete(connID)
 	c.apps.Delete(connID)
+	c.compressions.Delete(connID)
+	for _, lane := range frame.Lanes {
+		c.lanes.Delete(laneKey(connID, lane))
+	}
 }
 
 // Get a connection by connection id.
@@ -170,14 +188,47 @@ func (c *connector) GetConnIDs(appID string, name string, tag byte) []string {
 // Write a DataFrame to a connection.
 func (c *connector) Write(f *frame.DataFrame, toID string) error {
 	targetStream := c.Get(toID)
+	// the frame is written on the stream of its lane, or the main stream without the lane
+	if lane := f.Lane(); lane != frame.LaneNormal {
+		if stream, ok := c.lanes.Load(laneKey(toID, lane)); ok {
+			targetStream = stream.(io.ReadWriteCloser)
+		}
+	}
 	if targetStream == nil {
 		logger.Warnf("%swill write to: [%s], target stream is nil", ServerLogPrefix, toID)
 		return fmt.Errorf("target[%s] stream is nil", toID)
 	}
+	// the compressed carriage is forwarded as it is, unless the target does not accept it.
+	// the encrypted carriage is always forwarded as it is, it's opaque to the processor.
+	if f.Compression() != frame.CompressionNone && !f.Encrypted() {
+		if compression, ok := c.compressions.Load(toID); !ok || compression.(frame.Compression) != f.Compression() {
+			var err error
+			f, err = f.Decompress()
+			if err != nil {
+				return err
+			}
+		}
+	}
 	_, err := targetStream.Write(f.Encode())
 	return err
 }
 
+// AddLane adds the stream of a priority lane to a connection.
+func (c *connector) AddLane(connID string, lane frame.Lane, stream io.ReadWriteCloser) {
+	logger.Debugf("%sconnector add lane: connID=%s, lane=%s", ServerLogPrefix, connID, lane)
+	c.lanes.Store(laneKey(connID, lane), stream)
+}
+
+func laneKey(connID string, lane frame.Lane) string {
+	return fmt.Sprintf("%s#%d", connID, lane)
+}
+
+// SetCompression sets the payload compression accepted by a connection.
+func (c *connector) SetCompression(connID string, compression frame.Compression) {
+	logger.Debugf("%sconnector set compression: connID=%s, compression=%s", ServerLogPrefix, connID, compression)
+	c.compressions.Store(connID, compression)
+}
+
 // GetSnapshot gets the snapshot of all connections.
 func (c *connector) GetSnapshot() map[string]io.ReadWriteCloser {
 	result := make(map[string]io.ReadWriteCloser)
@@ -188,6 +239,16 @@ func (c *connector) GetSnapshot() map[string]io.ReadWriteCloser {
 	return result
 }
 
+// GetAppSnapshot gets the snapshot of all linked apps, keyed by connID.
+func (c *connector) GetAppSnapshot() map[string]*app {
+	result := make(map[string]*app)
+	c.apps.Range(func(key interface{}, val interface{}) bool {
+		result[key.(string)] = val.(*app)
+		return true
+	})
+	return result
+}
+
 // LinkApp links the app and connection.
 func (c *connector) LinkApp(connID string, appID string, name string, observed []byte) {
 	logger.Debugf("%sconnector link application: connID[%s] --> app[%s::%s]", ServerLogPrefix, connID, appID, name)
@@ -222,4 +283,6 @@ func (c *connector) UnlinkApp(connID string, appID string, name string) {
 func (c *connector) Clean() {
 	c.conns = sync.Map{}
 	c.apps = sync.Map{}
+	c.compressions = sync.Map{}
+	c.lanes = sync.Map{}
 }
@@ -41,13 +41,15 @@ type Context struct {
 	// Keys store the key/value pairs in context.
 	Keys map[string]interface{}
 
-	mu sync.RWMutex
+	session quic.Session
+	mu      sync.RWMutex
 }
 
-func newContext(connID string, stream quic.Stream) *Context {
+func newContext(connID string, session quic.Session, stream quic.Stream) *Context {
 	return &Context{
-		ConnID: connID,
-		Stream: stream,
+		ConnID:  connID,
+		Stream:  stream,
+		session: session,
 		// keys:    make(map[string]interface{}),
 	}
 }
@@ -66,12 +68,15 @@ func (c *Context) Clean() {
 	c.Keys = nil
 }
 
-// CloseWithError closes the stream and cleans the context.
-func (c *Context) CloseWithError(code uint64, msg string) {
-	logger.Debugf("%sconn[%s] context close, errCode=%d, msg=%s", ServerLogPrefix, c.ConnID, code, msg)
+// CloseWithError closes the connection with the error code, the client receives it as Error.
+func (c *Context) CloseWithError(code ErrorCode, msg string) {
+	logger.Debugf("%sconn[%s] context close, errCode=%s, msg=%s", ServerLogPrefix, c.ConnID, code, msg)
 	if c.Stream != nil {
 		c.Stream.Close()
 	}
+	if c.session != nil {
+		c.session.CloseWithError(quic.ApplicationErrorCode(code), msg)
+	}
 	c.Clean()
 }
 
@@ -0,0 +1,128 @@
+package core
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+	"github.com/bhojpur/service/pkg/secretstores"
+)
+
+// payloadCipher encrypts and decrypts the carriage of DataFrame end-to-end, the AEAD keys
+// are looked up by key ID from the secret store, so the processor never reads the payload.
+type payloadCipher struct {
+	store secretstores.SecretStore
+	tags  []byte
+	mu    sync.RWMutex
+	keyID string
+	// aeads caches the AEAD of key ID, the key is rotated by a new key ID.
+	aeads sync.Map
+}
+
+func newPayloadCipher(store secretstores.SecretStore, keyID string, tags []byte) *payloadCipher {
+	return &payloadCipher{
+		store: store,
+		keyID: keyID,
+		tags:  tags,
+	}
+}
+
+// setKeyID rotates the key which encrypts the carriage.
+func (p *payloadCipher) setKeyID(keyID string) {
+	p.mu.Lock()
+	p.keyID = keyID
+	p.mu.Unlock()
+}
+
+// encrypt the carriage if the tag of DataFrame needs encryption.
+func (p *payloadCipher) encrypt(f *frame.DataFrame) (*frame.DataFrame, error) {
+	p.mu.RLock()
+	keyID := p.keyID
+	p.mu.RUnlock()
+	if keyID == "" || f.Encrypted() || !p.shouldEncrypt(f.Tag()) {
+		return f, nil
+	}
+	aead, err := p.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return f.Encrypt(keyID, aead)
+}
+
+// decrypt the carriage by the key ID carried in the metadata.
+func (p *payloadCipher) decrypt(f *frame.DataFrame) (*frame.DataFrame, error) {
+	if !f.Encrypted() {
+		return f, nil
+	}
+	aead, err := p.aead(f.EncryptionKeyID())
+	if err != nil {
+		return nil, err
+	}
+	return f.Decrypt(aead)
+}
+
+func (p *payloadCipher) shouldEncrypt(tag byte) bool {
+	if len(p.tags) == 0 {
+		return true
+	}
+	for _, t := range p.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// aead returns the AEAD of key ID, the secret is a base64 encoded 16, 24 or 32 bytes AES key.
+func (p *payloadCipher) aead(keyID string) (cipher.AEAD, error) {
+	if keyID == "" {
+		return nil, errors.New("encryption key id is empty")
+	}
+	if v, ok := p.aeads.Load(keyID); ok {
+		return v.(cipher.AEAD), nil
+	}
+	resp, err := p.store.GetSecret(secretstores.GetSecretRequest{Name: keyID})
+	if err != nil {
+		return nil, fmt.Errorf("get encryption key [%s]: %w", keyID, err)
+	}
+	secret, ok := resp.Data[keyID]
+	if !ok {
+		secret, ok = resp.Data[secretstores.DefaultSecretRefKeyName]
+	}
+	if !ok {
+		return nil, fmt.Errorf("encryption key [%s] not found", keyID)
+	}
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key [%s] is not base64 encoded: %w", keyID, err)
+	}
+	aead, err := frame.NewAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key [%s]: %w", keyID, err)
+	}
+	p.aeads.Store(keyID, aead)
+	return aead, nil
+}
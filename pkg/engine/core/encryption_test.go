@@ -0,0 +1,130 @@
+package core
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+	"github.com/bhojpur/service/pkg/secretstores"
+)
+
+type mockSecretStore struct {
+	secrets map[string]string
+	gets    int
+}
+
+func (s *mockSecretStore) Init(metadata secretstores.Metadata) error {
+	return nil
+}
+
+func (s *mockSecretStore) GetSecret(req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
+	s.gets++
+	secret, ok := s.secrets[req.Name]
+	if !ok {
+		return secretstores.GetSecretResponse{}, errors.New("secret not found")
+	}
+	return secretstores.GetSecretResponse{Data: map[string]string{req.Name: secret}}, nil
+}
+
+func (s *mockSecretStore) BulkGetSecret(req secretstores.BulkGetSecretRequest) (secretstores.BulkGetSecretResponse, error) {
+	return secretstores.BulkGetSecretResponse{}, nil
+}
+
+func TestPayloadCipher(t *testing.T) {
+	store := &mockSecretStore{secrets: map[string]string{
+		"key-1": base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")),
+		"key-2": base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef")),
+	}}
+	sender := newPayloadCipher(store, "key-1", []byte{0x33})
+	receiver := newPayloadCipher(store, "", nil)
+
+	data := frame.NewDataFrame()
+	data.SetCarriage(0x33, []byte("noise"))
+
+	encrypted, err := sender.encrypt(data)
+	assert.NoError(t, err)
+	assert.True(t, encrypted.Encrypted())
+	assert.Equal(t, "key-1", encrypted.EncryptionKeyID())
+	assert.NotEqual(t, []byte("noise"), encrypted.GetCarriage())
+
+	// the processor forwards the encoded frame
+	forwarded, err := frame.DecodeToDataFrame(encrypted.Encode())
+	assert.NoError(t, err)
+	_, err = forwarded.Decompress()
+	assert.NoError(t, err)
+
+	decrypted, err := receiver.decrypt(forwarded)
+	assert.NoError(t, err)
+	assert.False(t, decrypted.Encrypted())
+	assert.Equal(t, []byte("noise"), decrypted.GetCarriage())
+	assert.Equal(t, data.TransactionID(), decrypted.TransactionID())
+
+	// the tags without encryption
+	other := frame.NewDataFrame()
+	other.SetCarriage(0x34, []byte("plain"))
+	plain, err := sender.encrypt(other)
+	assert.NoError(t, err)
+	assert.False(t, plain.Encrypted())
+
+	// rotate the key
+	sender.setKeyID("key-2")
+	encrypted, err = sender.encrypt(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "key-2", encrypted.EncryptionKeyID())
+	decrypted, err = receiver.decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("noise"), decrypted.GetCarriage())
+
+	// the keys are cached
+	_, err = receiver.decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, store.gets)
+
+	// unknown key
+	sender.setKeyID("key-3")
+	_, err = sender.encrypt(data)
+	assert.Error(t, err)
+}
+
+func TestPayloadCipherTampered(t *testing.T) {
+	store := &mockSecretStore{secrets: map[string]string{
+		"key-1": base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")),
+	}}
+	c := newPayloadCipher(store, "key-1", nil)
+
+	data := frame.NewDataFrame()
+	data.SetCarriage(0x33, []byte("noise"))
+	encrypted, err := c.encrypt(data)
+	assert.NoError(t, err)
+
+	// the encrypted carriage can not be moved to another tag
+	moved := frame.NewDataFrame()
+	moved.SetCarriage(0x34, encrypted.GetCarriage())
+	moved.GetMetaFrame().SetEncrypted(true)
+	moved.GetMetaFrame().SetMetadata(frame.EncryptionKeyIDMetadataKey, "key-1")
+	_, err = c.decrypt(moved)
+	assert.True(t, errors.Is(err, frame.ErrInvalidCiphertext))
+}
@@ -0,0 +1,153 @@
+package core
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lucas-clemente/quic-go"
+)
+
+// ErrorCode is the code of QUIC application error which closes the connection
+// between Bhojpur Service-Client and Bhojpur Service-Processor.
+type ErrorCode uint64
+
+// The registry of error codes.
+const (
+	// ErrorCodeClientAbort means the client closes the connection.
+	ErrorCodeClientAbort ErrorCode = 0x00
+	// ErrorCodeUnknown means an unexpected error occurred when reading frames.
+	ErrorCodeUnknown ErrorCode = 0xC0
+	// ErrorCodeClosed means the connection is closed by the network.
+	ErrorCodeClosed ErrorCode = 0xC1
+	// ErrorCodeRejected means the server rejects the client, e.g., the authentication
+	// fails or the stream function is not defined in the workflow.
+	ErrorCodeRejected ErrorCode = 0xCC
+	// ErrorCodeIllegalClientType means the type of client is unknown.
+	ErrorCodeIllegalClientType ErrorCode = 0xCD
+	// ErrorCodeDataFrame means the server fails to handle a DataFrame.
+	ErrorCodeDataFrame ErrorCode = 0xCE
+	// ErrorCodeFrameHandler means a frame handler of the server returns an error.
+	ErrorCodeFrameHandler ErrorCode = 0xCF
+	// ErrorCodeGoaway means the client fails to read frames and closes the connection.
+	ErrorCodeGoaway ErrorCode = 0xD0
+)
+
+func (e ErrorCode) String() string {
+	switch e {
+	case ErrorCodeClientAbort:
+		return "ClientAbort"
+	case ErrorCodeUnknown:
+		return "Unknown"
+	case ErrorCodeClosed:
+		return "Closed"
+	case ErrorCodeRejected:
+		return "Rejected"
+	case ErrorCodeIllegalClientType:
+		return "IllegalClientType"
+	case ErrorCodeDataFrame:
+		return "DataFrame"
+	case ErrorCodeFrameHandler:
+		return "FrameHandler"
+	case ErrorCodeGoaway:
+		return "Goaway"
+	default:
+		return fmt.Sprintf("ErrorCode(%#x)", uint64(e))
+	}
+}
+
+// Retryable returns if the client should reconnect after the connection is closed with
+// the code. The client is not retried when it closes the connection itself, or the server
+// rejects it, which fails again until the config is changed.
+func (e ErrorCode) Retryable() bool {
+	switch e {
+	case ErrorCodeClientAbort, ErrorCodeRejected, ErrorCodeIllegalClientType, ErrorCodeFrameHandler:
+		return false
+	default:
+		return true
+	}
+}
+
+// Error is the error closes the connection, it's matched by code with errors.Is, e.g.,
+// errors.Is(err, core.ErrRejected).
+type Error struct {
+	Code    ErrorCode
+	Message string
+	// Remote is true if the error is from the peer.
+	Remote bool
+}
+
+// Errors of the registered codes, which are used as targets of errors.Is.
+var (
+	ErrClientAbort       = &Error{Code: ErrorCodeClientAbort}
+	ErrUnknown           = &Error{Code: ErrorCodeUnknown}
+	ErrClosed            = &Error{Code: ErrorCodeClosed}
+	ErrRejected          = &Error{Code: ErrorCodeRejected}
+	ErrIllegalClientType = &Error{Code: ErrorCodeIllegalClientType}
+	ErrDataFrame         = &Error{Code: ErrorCodeDataFrame}
+	ErrFrameHandler      = &Error{Code: ErrorCodeFrameHandler}
+	ErrGoaway            = &Error{Code: ErrorCodeGoaway}
+)
+
+// NewError creates an Error with the code.
+func NewError(code ErrorCode, msg string) *Error {
+	return &Error{Code: code, Message: msg}
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s(%#x)", e.Code, uint64(e.Code))
+	}
+	return fmt.Sprintf("%s(%#x): %s", e.Code, uint64(e.Code), e.Message)
+}
+
+// Is matches the errors by code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Retryable returns if the client should reconnect.
+func (e *Error) Retryable() bool {
+	return e.Code.Retryable()
+}
+
+// ErrorFrom converts the QUIC application error to Error, nil if err is not an application error.
+func ErrorFrom(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	var ae *quic.ApplicationError
+	if errors.As(err, &ae) {
+		return &Error{Code: ErrorCode(ae.ErrorCode), Message: ae.ErrorMessage, Remote: ae.Remote}
+	}
+	return nil
+}
+
+// errorCodeOf returns the code of err, or the code if err is not an Error.
+func errorCodeOf(err error, code ErrorCode) ErrorCode {
+	if e := ErrorFrom(err); e != nil {
+		return e.Code
+	}
+	return code
+}
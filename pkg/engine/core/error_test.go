@@ -0,0 +1,65 @@
+package core
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lucas-clemente/quic-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorFrom(t *testing.T) {
+	err := ErrorFrom(&quic.ApplicationError{Remote: true, ErrorCode: quic.ApplicationErrorCode(ErrorCodeRejected), ErrorMessage: "illegal"})
+	assert.Equal(t, ErrorCodeRejected, err.Code)
+	assert.Equal(t, "illegal", err.Message)
+	assert.True(t, err.Remote)
+	assert.False(t, err.Retryable())
+	assert.True(t, errors.Is(err, ErrRejected))
+	assert.False(t, errors.Is(err, ErrDataFrame))
+	assert.Equal(t, "Rejected(0xcc): illegal", err.Error())
+
+	wrapped := fmt.Errorf("connect: %w", NewError(ErrorCodeClosed, "timeout"))
+	assert.True(t, errors.Is(wrapped, ErrClosed))
+	assert.Equal(t, ErrorCodeClosed, ErrorFrom(wrapped).Code)
+
+	assert.Nil(t, ErrorFrom(errors.New("EOF")))
+}
+
+func TestErrorCodeRetryable(t *testing.T) {
+	for code, retryable := range map[ErrorCode]bool{
+		ErrorCodeClientAbort:       false,
+		ErrorCodeUnknown:           true,
+		ErrorCodeClosed:            true,
+		ErrorCodeRejected:          false,
+		ErrorCodeIllegalClientType: false,
+		ErrorCodeDataFrame:         true,
+		ErrorCodeFrameHandler:      false,
+		ErrorCodeGoaway:            true,
+	} {
+		assert.Equal(t, retryable, code.Retryable(), code.String())
+	}
+	assert.Equal(t, "ErrorCode(0x1)", ErrorCode(0x01).String())
+	assert.Equal(t, ErrorCodeDataFrame, errorCodeOf(ErrDataFrame, ErrorCodeUnknown))
+	assert.Equal(t, ErrorCodeUnknown, errorCodeOf(errors.New("x"), ErrorCodeUnknown))
+}
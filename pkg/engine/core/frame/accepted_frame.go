@@ -23,7 +23,12 @@ package frame
 import "github.com/bhojpur/service/pkg/engine/codec"
 
 // AcceptedFrame is a Bhojpur Service encoded bytes, Tag is a fixed value TYPE_ID_ACCEPTED_FRAME
-type AcceptedFrame struct{}
+type AcceptedFrame struct {
+	// Compression is the payload compression algorithm accepted by the server.
+	Compression Compression
+	// Lanes is true if the server accepts the streams of priority lanes.
+	Lanes bool
+}
 
 // NewAcceptedFrame creates a new AcceptedFrame with a given TagID of user's data
 func NewAcceptedFrame() *AcceptedFrame {
@@ -38,17 +43,39 @@ func (m *AcceptedFrame) Type() Type {
 // Encode to Bhojpur Service encoded bytes.
 func (m *AcceptedFrame) Encode() []byte {
 	accepted := codec.NewNodePacketEncoder(int(byte(m.Type())))
-	accepted.AddBytes(nil)
+	if m.Compression == CompressionNone && !m.Lanes {
+		accepted.AddBytes(nil)
+	}
+	if m.Compression != CompressionNone {
+		compression := codec.NewPrimitivePacketEncoder(int(byte(TagOfAcceptedCompression)))
+		compression.SetBytesValue([]byte{byte(m.Compression)})
+		accepted.AddPrimitivePacket(compression)
+	}
+	if m.Lanes {
+		lanes := codec.NewPrimitivePacketEncoder(int(byte(TagOfAcceptedLanes)))
+		lanes.SetBoolValue(true)
+		accepted.AddPrimitivePacket(lanes)
+	}
 
 	return accepted.Encode()
 }
 
 // DecodeToAcceptedFrame decodes Bhojpur Service encoded bytes to AcceptedFrame.
 func DecodeToAcceptedFrame(buf []byte) (*AcceptedFrame, error) {
-	//nodeBlock := codec.NodePacket{}
-	_, _, err := codec.DecodeNodePacket(buf)
+	node, _, err := codec.DecodeNodePacket(buf)
 	if err != nil {
 		return nil, err
 	}
-	return &AcceptedFrame{}, nil
+	accepted := &AcceptedFrame{}
+	for _, v := range node.PrimitivePackets {
+		switch Type(v.SeqID()) {
+		case TagOfAcceptedCompression:
+			if buf := v.ToBytes(); len(buf) > 0 {
+				accepted.Compression = Compression(buf[0])
+			}
+		case TagOfAcceptedLanes:
+			accepted.Lanes, _ = v.ToBool()
+		}
+	}
+	return accepted, nil
 }
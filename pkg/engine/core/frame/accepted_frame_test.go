@@ -37,3 +37,26 @@ func TestAcceptedFrameDecode(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []byte{0x80 | byte(TagOfAcceptedFrame), 0x00}, ping.Encode())
 }
+
+func TestAcceptedFrameCompression(t *testing.T) {
+	f := &AcceptedFrame{Compression: CompressionGzip}
+	buf := f.Encode()
+	assert.Equal(t, []byte{0x80 | byte(TagOfAcceptedFrame), 0x03, byte(TagOfAcceptedCompression), 0x01, byte(CompressionGzip)}, buf)
+
+	accepted, err := DecodeToAcceptedFrame(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, CompressionGzip, accepted.Compression)
+}
+
+func TestAcceptedFrameLanes(t *testing.T) {
+	f := &AcceptedFrame{Compression: CompressionZstd, Lanes: true}
+	accepted, err := DecodeToAcceptedFrame(f.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, CompressionZstd, accepted.Compression)
+	assert.True(t, accepted.Lanes)
+
+	// a baseline server does not advertise lanes
+	accepted, err = DecodeToAcceptedFrame(NewAcceptedFrame().Encode())
+	assert.NoError(t, err)
+	assert.False(t, accepted.Lanes)
+}
@@ -0,0 +1,157 @@
+package frame
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is the algorithm used to compress the carriage of PayloadFrame.
+type Compression byte
+
+const (
+	// CompressionNone means the carriage is not compressed.
+	CompressionNone Compression = 0x00
+	// CompressionZstd compresses the carriage by zstd.
+	CompressionZstd Compression = 0x01
+	// CompressionSnappy compresses the carriage by snappy.
+	CompressionSnappy Compression = 0x02
+	// CompressionGzip compresses the carriage by gzip.
+	CompressionGzip Compression = 0x03
+)
+
+// MaxDecompressedSize is the size limit of a decompressed carriage, so a small compressed
+// payload can't exhaust the memory of the processor.
+const MaxDecompressedSize = 16 * 1024 * 1024
+
+// ErrDecompressedTooLarge is returned when the decompressed carriage exceeds MaxDecompressedSize.
+var ErrDecompressedTooLarge = errors.New("decompressed carriage exceeds the size limit")
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxDecompressedSize))
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionZstd:
+		return "zstd"
+	case CompressionSnappy:
+		return "snappy"
+	case CompressionGzip:
+		return "gzip"
+	default:
+		return "unknown"
+	}
+}
+
+// IsSupported returns if the compression algorithm is supported.
+func (c Compression) IsSupported() bool {
+	return c.String() != "unknown"
+}
+
+// ParseCompression parses the compression name, one of none, zstd, snappy or gzip.
+func ParseCompression(name string) (Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return CompressionNone, nil
+	case "zstd":
+		return CompressionZstd, nil
+	case "snappy":
+		return CompressionSnappy, nil
+	case "gzip":
+		return CompressionGzip, nil
+	}
+	return CompressionNone, fmt.Errorf("unknown compression: %q", name)
+}
+
+// Compress compresses the buffer.
+func (c Compression) Compress(buf []byte) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return buf, nil
+	case CompressionZstd:
+		return zstdEncoder.EncodeAll(buf, make([]byte, 0, len(buf))), nil
+	case CompressionSnappy:
+		return snappy.Encode(nil, buf), nil
+	case CompressionGzip:
+		var b bytes.Buffer
+		w := gzip.NewWriter(&b)
+		if _, err := w.Write(buf); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported compression: %#x", byte(c))
+}
+
+// Decompress decompresses the buffer, ErrDecompressedTooLarge is returned when the result
+// exceeds MaxDecompressedSize.
+func (c Compression) Decompress(buf []byte) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return buf, nil
+	case CompressionZstd:
+		out, err := zstdDecoder.DecodeAll(buf, nil)
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) || len(out) > MaxDecompressedSize {
+			return nil, ErrDecompressedTooLarge
+		}
+		return out, err
+	case CompressionSnappy:
+		n, err := snappy.DecodedLen(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n > MaxDecompressedSize {
+			return nil, ErrDecompressedTooLarge
+		}
+		return snappy.Decode(nil, buf)
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		out, err := ioutil.ReadAll(io.LimitReader(r, MaxDecompressedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(out) > MaxDecompressedSize {
+			return nil, ErrDecompressedTooLarge
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported compression: %#x", byte(c))
+}
@@ -0,0 +1,91 @@
+package frame
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompression(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"noise":42.5,"from":"localhost"}`), 32)
+	for _, c := range []Compression{CompressionZstd, CompressionSnappy, CompressionGzip} {
+		compressed, err := c.Compress(data)
+		assert.NoError(t, err, c.String())
+		assert.Less(t, len(compressed), len(data), c.String())
+
+		decompressed, err := c.Decompress(compressed)
+		assert.NoError(t, err, c.String())
+		assert.Equal(t, data, decompressed, c.String())
+
+		parsed, err := ParseCompression(c.String())
+		assert.NoError(t, err)
+		assert.Equal(t, c, parsed)
+	}
+
+	_, err := ParseCompression("lz4")
+	assert.Error(t, err)
+	assert.False(t, Compression(0x10).IsSupported())
+}
+
+func TestDecompressTooLarge(t *testing.T) {
+	bomb := make([]byte, MaxDecompressedSize+1)
+	for _, c := range []Compression{CompressionZstd, CompressionSnappy, CompressionGzip} {
+		compressed, err := c.Compress(bomb)
+		assert.NoError(t, err, c.String())
+		assert.Less(t, len(compressed), len(bomb), c.String())
+
+		_, err = c.Decompress(compressed)
+		assert.Equal(t, ErrDecompressedTooLarge, err, c.String())
+	}
+}
+
+func TestDataFrameCompress(t *testing.T) {
+	data := bytes.Repeat([]byte("bhojpur"), 100)
+	d := NewDataFrame()
+	d.SetCarriage(0x15, data)
+
+	// below threshold
+	same, err := d.Compress(CompressionZstd, 1024)
+	assert.NoError(t, err)
+	assert.True(t, same == d)
+
+	compressed, err := d.Compress(CompressionZstd, 256)
+	assert.NoError(t, err)
+	assert.Equal(t, CompressionZstd, compressed.Compression())
+	assert.Equal(t, CompressionNone, d.Compression())
+	assert.Less(t, len(compressed.GetCarriage()), len(data))
+
+	// the compression flag is carried by the MetaFrame
+	decoded, err := DecodeToDataFrame(compressed.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, CompressionZstd, decoded.Compression())
+	assert.Equal(t, d.TransactionID(), decoded.TransactionID())
+	assert.EqualValues(t, 0x15, decoded.GetDataTag())
+
+	decompressed, err := decoded.Decompress()
+	assert.NoError(t, err)
+	assert.Equal(t, CompressionNone, decompressed.Compression())
+	assert.Equal(t, data, decompressed.GetCarriage())
+	assert.Equal(t, d.Encode(), decompressed.Encode())
+}
@@ -80,6 +80,72 @@ func (d *DataFrame) GetDataTag() byte {
 	return d.payloadFrame.Tag
 }
 
+// Compression returns the compression algorithm of user's data, CompressionNone if
+// it is not compressed.
+func (d *DataFrame) Compression() Compression {
+	return d.metaFrame.Compression()
+}
+
+// Priority returns the priority of `DataFrame`, 0 if it's not set.
+func (d *DataFrame) Priority() uint8 {
+	return d.metaFrame.Priority()
+}
+
+// SetPriority sets the priority of `DataFrame`, which decides the lane it's transferred on.
+func (d *DataFrame) SetPriority(priority uint8) {
+	d.metaFrame.SetPriority(priority)
+}
+
+// Lane returns the lane of `DataFrame` by its priority.
+func (d *DataFrame) Lane() Lane {
+	return LaneOf(d.Priority())
+}
+
+// Encrypted returns if the carriage of `DataFrame` is encrypted.
+func (d *DataFrame) Encrypted() bool {
+	return d.metaFrame.Encrypted()
+}
+
+// Compress returns a `DataFrame` whose carriage is compressed by c, the carriage shorter than
+// threshold is kept as it is. d itself is returned when it needs no compression.
+func (d *DataFrame) Compress(c Compression, threshold int) (*DataFrame, error) {
+	if c == CompressionNone || d.Compression() != CompressionNone || d.Encrypted() || len(d.GetCarriage()) < threshold {
+		return d, nil
+	}
+	buf, err := c.Compress(d.GetCarriage())
+	if err != nil {
+		return nil, err
+	}
+	meta := d.metaFrame.clone()
+	meta.SetCompression(c)
+	return &DataFrame{
+		metaFrame:    meta,
+		payloadFrame: NewPayloadFrame(d.Tag()).SetCarriage(buf),
+	}, nil
+}
+
+// Decompress returns a `DataFrame` whose carriage is decompressed, d itself is returned
+// when it is not compressed.
+func (d *DataFrame) Decompress() (*DataFrame, error) {
+	c := d.Compression()
+	if c == CompressionNone {
+		return d, nil
+	}
+	if d.Encrypted() {
+		return nil, ErrEncryptedCarriage
+	}
+	buf, err := c.Decompress(d.GetCarriage())
+	if err != nil {
+		return nil, err
+	}
+	meta := d.metaFrame.clone()
+	meta.SetCompression(CompressionNone)
+	return &DataFrame{
+		metaFrame:    meta,
+		payloadFrame: NewPayloadFrame(d.Tag()).SetCarriage(buf),
+	}, nil
+}
+
 // Encode return Bhojpur Service encoded bytes of `DataFrame`
 func (d *DataFrame) Encode() []byte {
 	data := codec.NewNodePacketEncoder(int(byte(d.Type())))
@@ -102,19 +168,12 @@ func DecodeToDataFrame(buf []byte) (*DataFrame, error) {
 
 	//metaBlock := packet.NodePackets[byte(TagOfMetaFrame)]
 	metaBlock := packet.NodePackets[0]
-	meta, err := DecodeToMetaFrame(metaBlock.GetValBuf())
-	if err != nil {
-		return nil, err
-	}
-	data.metaFrame = meta
+	data.metaFrame = decodeMetaFrame(&metaBlock)
 
 	//payloadBlock := packet.NodePackets[int(byte(TagOfPayloadFrame))]
+	// the decoded node is used directly, the carriage may be any binary data
 	payloadBlock := packet.NodePackets[1]
-	payload, err := DecodeToPayloadFrame(payloadBlock.GetValBuf())
-	if err != nil {
-		return nil, err
-	}
-	data.payloadFrame = payload
+	data.payloadFrame = decodePayloadFrame(&payloadBlock)
 
 	return data, nil
 }
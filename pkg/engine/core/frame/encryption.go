@@ -0,0 +1,115 @@
+package frame
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EncryptionKeyIDMetadataKey is the metadata key of the ID of the key which encrypts the carriage.
+const EncryptionKeyIDMetadataKey = "encryption-key-id"
+
+var (
+	// ErrEncryptedCarriage is returned when the encrypted carriage is accessed without decryption.
+	ErrEncryptedCarriage = errors.New("carriage is encrypted")
+	// ErrInvalidCiphertext is returned when the encrypted carriage can not be opened.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// NewAEAD creates an AES-GCM AEAD with the key, which is 16, 24 or 32 bytes long.
+func NewAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptionKeyID returns the ID of the key which encrypts the carriage, empty string if
+// the carriage is not encrypted.
+func (d *DataFrame) EncryptionKeyID() string {
+	if !d.Encrypted() {
+		return ""
+	}
+	return d.metaFrame.Metadata(EncryptionKeyIDMetadataKey)
+}
+
+// Encrypt returns a `DataFrame` whose carriage is sealed by aead, the keyID is carried
+// in the metadata for the receiver to look up the key. The tag and keyID are authenticated,
+// so the carriage can not be moved to another tag. d itself is returned when it's encrypted.
+func (d *DataFrame) Encrypt(keyID string, aead cipher.AEAD) (*DataFrame, error) {
+	if d.Encrypted() {
+		return d, nil
+	}
+	if keyID == "" {
+		return nil, errors.New("encryption key id is empty")
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	// nonce + ciphertext
+	buf := aead.Seal(nonce, nonce, d.GetCarriage(), additionalData(d.Tag(), keyID))
+
+	meta := d.metaFrame.clone()
+	meta.SetEncrypted(true)
+	meta.SetMetadata(EncryptionKeyIDMetadataKey, keyID)
+	return &DataFrame{
+		metaFrame:    meta,
+		payloadFrame: NewPayloadFrame(d.Tag()).SetCarriage(buf),
+	}, nil
+}
+
+// Decrypt returns a `DataFrame` whose carriage is opened by aead, d itself is returned
+// when it's not encrypted.
+func (d *DataFrame) Decrypt(aead cipher.AEAD) (*DataFrame, error) {
+	if !d.Encrypted() {
+		return d, nil
+	}
+	keyID := d.EncryptionKeyID()
+	buf := d.GetCarriage()
+	if len(buf) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, ciphertext := buf[:aead.NonceSize()], buf[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(d.Tag(), keyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: key id=%s, %v", ErrInvalidCiphertext, keyID, err)
+	}
+
+	meta := d.metaFrame.clone()
+	meta.SetEncrypted(false)
+	meta.SetMetadata(EncryptionKeyIDMetadataKey, "")
+	return &DataFrame{
+		metaFrame:    meta,
+		payloadFrame: NewPayloadFrame(d.Tag()).SetCarriage(plaintext),
+	}, nil
+}
+
+// additionalData is the authenticated data of encrypted carriage: tag + keyID.
+func additionalData(tag byte, keyID string) []byte {
+	return append([]byte{tag}, keyID...)
+}
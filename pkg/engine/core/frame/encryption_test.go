@@ -0,0 +1,87 @@
+package frame
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataFrameEncrypt(t *testing.T) {
+	aead, err := NewAEAD([]byte("0123456789abcdef"))
+	assert.NoError(t, err)
+
+	data := bytes.Repeat([]byte("bhojpur"), 100)
+	d := NewDataFrame()
+	d.SetCarriage(0x15, data)
+	d.GetMetaFrame().SetMetadata("region", "in")
+
+	compressed, err := d.Compress(CompressionSnappy, 0)
+	assert.NoError(t, err)
+	encrypted, err := compressed.Encrypt("key-1", aead)
+	assert.NoError(t, err)
+	assert.True(t, encrypted.Encrypted())
+	assert.False(t, compressed.Encrypted())
+
+	// the processor reads tag and metadata only
+	decoded, err := DecodeToDataFrame(encrypted.Encode())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0x15, decoded.GetDataTag())
+	assert.Equal(t, "key-1", decoded.EncryptionKeyID())
+	assert.Equal(t, "in", decoded.GetMetaFrame().Metadata("region"))
+	assert.Equal(t, CompressionSnappy, decoded.Compression())
+	_, err = decoded.Decompress()
+	assert.ErrorIs(t, err, ErrEncryptedCarriage)
+
+	decrypted, err := decoded.Decrypt(aead)
+	assert.NoError(t, err)
+	assert.Equal(t, "", decrypted.EncryptionKeyID())
+	assert.Equal(t, "", decrypted.GetMetaFrame().Metadata(EncryptionKeyIDMetadataKey))
+	decompressed, err := decrypted.Decompress()
+	assert.NoError(t, err)
+	assert.Equal(t, data, decompressed.GetCarriage())
+	assert.Equal(t, "in", decompressed.GetMetaFrame().Metadata("region"))
+
+	// the wrong key
+	other, err := NewAEAD([]byte("fedcba9876543210"))
+	assert.NoError(t, err)
+	_, err = decoded.Decrypt(other)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestMetaFrameMetadata(t *testing.T) {
+	m := NewMetaFrame()
+	m.SetTransactionID("1234")
+	m.SetMetadata("b", "2")
+	m.SetMetadata("a", "1")
+	assert.Equal(t, []string{"a", "b"}, m.MetadataKeys())
+
+	meta, err := DecodeToMetaFrame(m.Encode())
+	assert.NoError(t, err)
+	assert.EqualValues(t, "1234", meta.TransactionID())
+	assert.Equal(t, "1", meta.Metadata("a"))
+	assert.Equal(t, "2", meta.Metadata("b"))
+
+	meta.SetMetadata("a", "")
+	assert.Equal(t, []string{"b"}, meta.MetadataKeys())
+}
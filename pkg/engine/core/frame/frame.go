@@ -37,6 +37,11 @@ const (
 	TagOfMetadata      Type = 0x03
 	TagOfTransactionID Type = 0x01
 	TagOfIssuer        Type = 0x02
+	TagOfMetaFlags     Type = 0x04
+	TagOfCompression   Type = 0x05
+	// Metadata of MetaFrame
+	TagOfMetadataKey   Type = 0x01
+	TagOfMetadataValue Type = 0x02
 	// PayloadFrame of DataFrame
 	TagOfPayloadFrame Type = 0x2E
 
@@ -49,11 +54,18 @@ const (
 	TagOfHandshakeAuthType        Type = 0x04
 	TagOfHandshakeAuthPayload     Type = 0x05
 	TagOfHandshakeObserveDataTags Type = 0x06
+	TagOfHandshakeCompression     Type = 0x07
 
 	TagOfPingFrame     Type = 0x3C
 	TagOfPongFrame     Type = 0x3B
 	TagOfAcceptedFrame Type = 0x3A
 	TagOfRejectedFrame Type = 0x39
+	TagOfLaneFrame     Type = 0x38
+	// AcceptedFrame
+	TagOfAcceptedCompression Type = 0x01
+	TagOfAcceptedLanes       Type = 0x02
+	// LaneFrame
+	TagOfLane Type = 0x01
 )
 
 // Type represents the type of frame.
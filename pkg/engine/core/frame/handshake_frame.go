@@ -32,6 +32,8 @@ type HandshakeFrame struct {
 	ClientType byte
 	// ObserveDataTags are the client data tag list.
 	ObserveDataTags []byte
+	// Compression is the payload compression algorithm requested by the client.
+	Compression Compression
 	// auth
 	authType    byte
 	authPayload []byte
@@ -83,6 +85,12 @@ func (h *HandshakeFrame) Encode() []byte {
 	handshake.AddPrimitivePacket(appIDBlock)
 	handshake.AddPrimitivePacket(authTypeBlock)
 	handshake.AddPrimitivePacket(authPayloadBlock)
+	// compression, omitted when the client does not request it
+	if h.Compression != CompressionNone {
+		compressionBlock := codec.NewPrimitivePacketEncoder(int(byte(TagOfHandshakeCompression)))
+		compressionBlock.SetBytesValue([]byte{byte(h.Compression)})
+		handshake.AddPrimitivePacket(compressionBlock)
+	}
 
 	return handshake.Encode()
 }
@@ -131,6 +139,15 @@ func DecodeToHandshakeFrame(buf []byte) (*HandshakeFrame, error) {
 	authPayload := authPayloadBlock.ToBytes()
 	handshake.authPayload = authPayload
 
+	// optional fields
+	for _, block := range node.PrimitivePackets[6:] {
+		if Type(block.SeqID()) == TagOfHandshakeCompression {
+			if buf := block.ToBytes(); len(buf) > 0 {
+				handshake.Compression = Compression(buf[0])
+			}
+		}
+	}
+
 	return handshake, nil
 }
 
@@ -47,3 +47,18 @@ func TestHandshakeFrameEncode(t *testing.T) {
 	assert.EqualValues(t, expectedName, Handshake.Name)
 	assert.EqualValues(t, expectedType, Handshake.ClientType)
 }
+
+func TestHandshakeFrameCompression(t *testing.T) {
+	m := NewHandshakeFrame("1234", 0xD3, []byte{0x01}, "", 0x0, nil)
+	m.Compression = CompressionZstd
+
+	handshake, err := DecodeToHandshakeFrame(m.Encode())
+	assert.NoError(t, err)
+	assert.EqualValues(t, "1234", handshake.Name)
+	assert.Equal(t, CompressionZstd, handshake.Compression)
+
+	// the client which does not request compression
+	handshake, err = DecodeToHandshakeFrame(NewHandshakeFrame("1234", 0xD3, nil, "", 0x0, nil).Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, CompressionNone, handshake.Compression)
+}
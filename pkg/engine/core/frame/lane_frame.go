@@ -0,0 +1,104 @@
+package frame
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+
+	"github.com/bhojpur/service/pkg/engine/codec"
+)
+
+// Lane is the priority class of DataFrames, each lane is transferred on its own QUIC stream,
+// so the high-priority frames are not blocked by the bulk frames of the normal lane.
+type Lane byte
+
+const (
+	// LaneNormal transfers the frames without priority, it's the stream of handshake.
+	LaneNormal Lane = 0x00
+	// LaneHigh transfers the frames whose priority is above 0.
+	LaneHigh Lane = 0x01
+)
+
+// Lanes are the additional lanes opened by the client besides LaneNormal.
+var Lanes = []Lane{LaneHigh}
+
+func (l Lane) String() string {
+	switch l {
+	case LaneNormal:
+		return "normal"
+	case LaneHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("lane(%d)", byte(l))
+	}
+}
+
+// LaneOf returns the lane of the priority.
+func LaneOf(priority uint8) Lane {
+	if priority > 0 {
+		return LaneHigh
+	}
+	return LaneNormal
+}
+
+// LaneFrame is the first frame written on a new QUIC stream by the client, it declares
+// the stream is the lane of a priority class in both directions.
+type LaneFrame struct {
+	Lane Lane
+}
+
+// NewLaneFrame creates a new LaneFrame.
+func NewLaneFrame(lane Lane) *LaneFrame {
+	return &LaneFrame{Lane: lane}
+}
+
+// Type gets the type of Frame.
+func (m *LaneFrame) Type() Type {
+	return TagOfLaneFrame
+}
+
+// Encode to Bhojpur Service encoded bytes.
+func (m *LaneFrame) Encode() []byte {
+	lane := codec.NewPrimitivePacketEncoder(int(byte(TagOfLane)))
+	lane.SetBytesValue([]byte{byte(m.Lane)})
+
+	node := codec.NewNodePacketEncoder(int(byte(m.Type())))
+	node.AddPrimitivePacket(lane)
+
+	return node.Encode()
+}
+
+// DecodeToLaneFrame decodes Bhojpur Service encoded bytes to LaneFrame.
+func DecodeToLaneFrame(buf []byte) (*LaneFrame, error) {
+	node, _, err := codec.DecodeNodePacket(buf)
+	if err != nil {
+		return nil, err
+	}
+	f := &LaneFrame{}
+	for _, v := range node.PrimitivePackets {
+		if Type(v.SeqID()) == TagOfLane {
+			if buf := v.ToBytes(); len(buf) > 0 {
+				f.Lane = Lane(buf[0])
+			}
+		}
+	}
+	return f, nil
+}
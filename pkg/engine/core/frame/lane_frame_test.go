@@ -0,0 +1,57 @@
+package frame
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLaneFrameEncode(t *testing.T) {
+	f := NewLaneFrame(LaneHigh)
+	assert.Equal(t, []byte{0x80 | byte(TagOfLaneFrame), 0x03, byte(TagOfLane), 0x01, byte(LaneHigh)}, f.Encode())
+}
+
+func TestLaneFrameDecode(t *testing.T) {
+	buf := []byte{0x80 | byte(TagOfLaneFrame), 0x03, byte(TagOfLane), 0x01, byte(LaneHigh)}
+	f, err := DecodeToLaneFrame(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, LaneHigh, f.Lane)
+}
+
+func TestDataFramePriority(t *testing.T) {
+	d := NewDataFrame()
+	d.SetCarriage(0x33, []byte("alarm"))
+	assert.EqualValues(t, 0, d.Priority())
+	assert.Equal(t, LaneNormal, d.Lane())
+
+	d.SetPriority(9)
+	decoded, err := DecodeToDataFrame(d.Encode())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 9, decoded.Priority())
+	assert.Equal(t, "9", decoded.GetMetaFrame().Metadata(PriorityMetadataKey))
+	assert.Equal(t, LaneHigh, decoded.Lane())
+
+	decoded.SetPriority(0)
+	assert.Equal(t, LaneNormal, decoded.Lane())
+	assert.Empty(t, decoded.GetMetaFrame().MetadataKeys())
+}
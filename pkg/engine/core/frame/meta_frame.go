@@ -21,18 +21,37 @@ package frame
 // THE SOFTWARE.
 
 import (
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/bhojpur/service/pkg/engine/codec"
+	"github.com/bhojpur/service/pkg/metadata"
 )
 
+// PriorityMetadataKey is the metadata key of the priority of DataFrame.
+const PriorityMetadataKey = metadata.PriorityMetadataKey
+
+// IssuerMetadataKey is the metadata key of the name of the client which sends the DataFrame,
+// it's set by Bhojpur Service-Processor when the DataFrame is routed.
+const IssuerMetadataKey = "issuer"
+
 // MetaFrame is a Bhojpur Service encoded bytes, SeqID is a fixed value of TYPE_ID_TRANSACTION.
 // used for describes metadata for a DataFrame.
 type MetaFrame struct {
-	tid string
+	tid         string
+	flags       byte
+	compression Compression
+	metadata    map[string]string
 }
 
+const (
+	// FlagCompressed marks the carriage of PayloadFrame is compressed.
+	FlagCompressed byte = 0x01
+	// FlagEncrypted marks the carriage of PayloadFrame is encrypted.
+	FlagEncrypted byte = 0x02
+)
+
 // NewMetaFrame creates a new MetaFrame instance.
 func NewMetaFrame() *MetaFrame {
 	return &MetaFrame{
@@ -50,14 +69,138 @@ func (m *MetaFrame) TransactionID() string {
 	return m.tid
 }
 
+// Compression returns the compression algorithm of the carriage, CompressionNone
+// if the carriage is not compressed.
+func (m *MetaFrame) Compression() Compression {
+	if m.flags&FlagCompressed == 0 {
+		return CompressionNone
+	}
+	return m.compression
+}
+
+// SetCompression marks the carriage is compressed by c.
+func (m *MetaFrame) SetCompression(c Compression) {
+	if c == CompressionNone {
+		m.flags &^= FlagCompressed
+	} else {
+		m.flags |= FlagCompressed
+	}
+	m.compression = c
+}
+
+// Encrypted returns if the carriage is encrypted.
+func (m *MetaFrame) Encrypted() bool {
+	return m.flags&FlagEncrypted != 0
+}
+
+// SetEncrypted marks the carriage is encrypted or not.
+func (m *MetaFrame) SetEncrypted(encrypted bool) {
+	if encrypted {
+		m.flags |= FlagEncrypted
+	} else {
+		m.flags &^= FlagEncrypted
+	}
+}
+
+// Metadata returns the value of metadata key, empty string if it's not set.
+func (m *MetaFrame) Metadata(key string) string {
+	return m.metadata[key]
+}
+
+// SetMetadata sets the metadata, which is readable by Bhojpur Service-Processor
+// even if the carriage is encrypted. An empty value removes the key.
+func (m *MetaFrame) SetMetadata(key, value string) {
+	if value == "" {
+		delete(m.metadata, key)
+		return
+	}
+	if m.metadata == nil {
+		m.metadata = make(map[string]string)
+	}
+	m.metadata[key] = value
+}
+
+// Priority returns the priority carried in the metadata, 0 if it's not set or invalid.
+func (m *MetaFrame) Priority() uint8 {
+	priority, _, err := metadata.TryGetPriority(m.metadata)
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
+// SetPriority sets the priority in the metadata, 0 removes it.
+func (m *MetaFrame) SetPriority(priority uint8) {
+	if priority == 0 {
+		m.SetMetadata(PriorityMetadataKey, "")
+		return
+	}
+	m.SetMetadata(PriorityMetadataKey, strconv.Itoa(int(priority)))
+}
+
+// Issuer returns the name of the client which sends the DataFrame, empty string if it's unknown.
+func (m *MetaFrame) Issuer() string {
+	return m.Metadata(IssuerMetadataKey)
+}
+
+// SetIssuer sets the name of the client which sends the DataFrame.
+func (m *MetaFrame) SetIssuer(issuer string) {
+	m.SetMetadata(IssuerMetadataKey, issuer)
+}
+
+// MetadataKeys returns the sorted keys of metadata.
+func (m *MetaFrame) MetadataKeys() []string {
+	keys := make([]string, 0, len(m.metadata))
+	for k := range m.metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// clone returns a copy of MetaFrame which does not share the metadata.
+func (m *MetaFrame) clone() *MetaFrame {
+	meta := *m
+	meta.metadata = nil
+	for k, v := range m.metadata {
+		meta.SetMetadata(k, v)
+	}
+	return &meta
+}
+
 // Encode implements Frame.Encode method.
 func (m *MetaFrame) Encode() []byte {
 	meta := codec.NewNodePacketEncoder(int(byte(TagOfMetaFrame)))
 
 	transactionID := codec.NewPrimitivePacketEncoder(int(byte(TagOfTransactionID)))
 	transactionID.SetStringValue(m.tid)
-
 	meta.AddPrimitivePacket(transactionID)
+
+	// the optional fields are encoded only when they are set, so the frame keeps
+	// compatible with the clients which only know the transaction ID.
+	if m.flags != 0 {
+		flags := codec.NewPrimitivePacketEncoder(int(byte(TagOfMetaFlags)))
+		flags.SetBytesValue([]byte{m.flags})
+		meta.AddPrimitivePacket(flags)
+	}
+	if m.compression != CompressionNone {
+		compression := codec.NewPrimitivePacketEncoder(int(byte(TagOfCompression)))
+		compression.SetBytesValue([]byte{byte(m.compression)})
+		meta.AddPrimitivePacket(compression)
+	}
+	if len(m.metadata) > 0 {
+		metadata := codec.NewNodePacketEncoder(int(byte(TagOfMetadata)))
+		for _, k := range m.MetadataKeys() {
+			key := codec.NewPrimitivePacketEncoder(int(byte(TagOfMetadataKey)))
+			key.SetStringValue(k)
+			metadata.AddPrimitivePacket(key)
+			value := codec.NewPrimitivePacketEncoder(int(byte(TagOfMetadataValue)))
+			value.SetStringValue(m.metadata[k])
+			metadata.AddPrimitivePacket(value)
+		}
+		meta.AddNodePacket(metadata)
+	}
+
 	return meta.Encode()
 }
 
@@ -68,13 +211,44 @@ func DecodeToMetaFrame(buf []byte) (*MetaFrame, error) {
 		return nil, err
 	}
 
+	return decodeMetaFrame(nodeBlock), nil
+}
+
+// decodeMetaFrame builds a MetaFrame from the decoded node packet.
+func decodeMetaFrame(nodeBlock *codec.NodePacket) *MetaFrame {
 	meta := &MetaFrame{}
 	meta.tid = string(nodeBlock.GetValBuf())
 	for _, v := range nodeBlock.PrimitivePackets {
-		val, _ := v.ToUTF8String()
-		meta.tid = val
-		break
+		switch Type(v.SeqID()) {
+		case TagOfTransactionID:
+			val, _ := v.ToUTF8String()
+			meta.tid = val
+		case TagOfMetaFlags:
+			if buf := v.ToBytes(); len(buf) > 0 {
+				meta.flags = buf[0]
+			}
+		case TagOfCompression:
+			if buf := v.ToBytes(); len(buf) > 0 {
+				meta.compression = Compression(buf[0])
+			}
+		}
+	}
+	for _, n := range nodeBlock.NodePackets {
+		if Type(n.SeqID()) != TagOfMetadata {
+			continue
+		}
+		// the metadata is encoded as key and value pairs
+		var key string
+		for _, v := range n.PrimitivePackets {
+			switch Type(v.SeqID()) {
+			case TagOfMetadataKey:
+				key, _ = v.ToUTF8String()
+			case TagOfMetadataValue:
+				value, _ := v.ToUTF8String()
+				meta.SetMetadata(key, value)
+			}
+		}
 	}
 
-	return meta, nil
+	return meta
 }
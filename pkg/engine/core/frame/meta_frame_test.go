@@ -40,3 +40,31 @@ func TestMetaFrameDecode(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, "1234", meta.TransactionID())
 }
+
+func TestMetaFrameCompression(t *testing.T) {
+	m := NewMetaFrame()
+	m.SetTransactionID("1234")
+	m.SetCompression(CompressionSnappy)
+	assert.Equal(t, []byte{
+		0x80 | byte(TagOfMetaFrame), 0x0C,
+		byte(TagOfTransactionID), 0x04, 0x31, 0x32, 0x33, 0x34,
+		byte(TagOfMetaFlags), 0x01, FlagCompressed,
+		byte(TagOfCompression), 0x01, byte(CompressionSnappy),
+	}, m.Encode())
+
+	meta, err := DecodeToMetaFrame(m.Encode())
+	assert.NoError(t, err)
+	assert.EqualValues(t, "1234", meta.TransactionID())
+	assert.Equal(t, CompressionSnappy, meta.Compression())
+}
+
+func TestMetaFrameIssuer(t *testing.T) {
+	m := NewMetaFrame()
+	assert.Equal(t, "", m.Issuer())
+	m.SetIssuer("source")
+
+	meta, err := DecodeToMetaFrame(m.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, "source", meta.Issuer())
+	assert.Equal(t, []string{IssuerMetadataKey}, meta.MetadataKeys())
+}
@@ -63,6 +63,11 @@ func DecodeToPayloadFrame(buf []byte) (*PayloadFrame, error) {
 		return nil, err
 	}
 
+	return decodePayloadFrame(nodeBlock), nil
+}
+
+// decodePayloadFrame builds a PayloadFrame from the decoded node packet.
+func decodePayloadFrame(nodeBlock *codec.NodePacket) *PayloadFrame {
 	payload := &PayloadFrame{}
 	payload.Tag = nodeBlock.SeqID()
 	payload.Carriage = nodeBlock.GetValBuf()
@@ -72,5 +77,5 @@ func DecodeToPayloadFrame(buf []byte) (*PayloadFrame, error) {
 		break
 	}
 
-	return payload, nil
+	return payload
 }
@@ -21,11 +21,52 @@ package core
 // THE SOFTWARE.
 
 import (
+	"context"
+
 	"github.com/bhojpur/service/pkg/engine/core/frame"
 )
 
 // AsyncHandler is the request-response mode (asnyc)
 type AsyncHandler func([]byte) (byte, []byte)
 
+// Handler is the request-response mode with the context and metadata of data, it returns
+// zero or several outputs, which keep the TransactionID and priority of msg. The context is
+// canceled when the stream function is closed. The outputs are dropped if an error is returned.
+type Handler func(ctx context.Context, msg *Message) ([]*frame.PayloadFrame, error)
+
+// Handler adapts AsyncHandler to Handler, the response is sent only when it's not empty.
+func (fn AsyncHandler) Handler() Handler {
+	if fn == nil {
+		return nil
+	}
+	return func(_ context.Context, msg *Message) ([]*frame.PayloadFrame, error) {
+		tag, resp := fn(msg.Payload)
+		if len(resp) == 0 {
+			return nil, nil
+		}
+		return []*frame.PayloadFrame{Output(tag, resp)}, nil
+	}
+}
+
 // PipeHandler is the bidirectional stream mode (blocking).
 type PipeHandler func(in <-chan []byte, out chan<- *frame.PayloadFrame)
+
+// MessagePipeHandler is the bidirectional stream mode with the metadata of data (blocking).
+type MessagePipeHandler func(in <-chan *Message, out chan<- *frame.PayloadFrame)
+
+// MessagePipeHandler adapts PipeHandler to MessagePipeHandler, which receives the payload of data only.
+func (fn PipeHandler) MessagePipeHandler() MessagePipeHandler {
+	if fn == nil {
+		return nil
+	}
+	return func(in <-chan *Message, out chan<- *frame.PayloadFrame) {
+		payloads := make(chan []byte)
+		go func() {
+			defer close(payloads)
+			for msg := range in {
+				payloads <- msg.Payload
+			}
+		}()
+		fn(payloads, out)
+	}
+}
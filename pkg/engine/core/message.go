@@ -0,0 +1,63 @@
+package core
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+)
+
+// Message is the data received by the Handler of stream function.
+type Message struct {
+	// Tag is the data tag of payload.
+	Tag byte
+	// Payload is the data, which is decrypted and decompressed.
+	Payload []byte
+	// TransactionID is the ID of transaction which the data belongs to.
+	TransactionID string
+	// Issuer is the name of the source or stream function which sends the data.
+	Issuer string
+	// Priority is the priority of data, 0 is the default.
+	Priority uint8
+	// Metadata is a copy of the metadata of data.
+	Metadata map[string]string
+}
+
+// NewMessage creates a Message from the DataFrame.
+func NewMessage(f *frame.DataFrame) *Message {
+	meta := f.GetMetaFrame()
+	msg := &Message{
+		Tag:           f.GetDataTag(),
+		Payload:       f.GetCarriage(),
+		TransactionID: f.TransactionID(),
+		Issuer:        meta.Issuer(),
+		Priority:      meta.Priority(),
+		Metadata:      make(map[string]string),
+	}
+	for _, k := range meta.MetadataKeys() {
+		msg.Metadata[k] = meta.Metadata(k)
+	}
+	return msg
+}
+
+// Output returns a PayloadFrame of the tag and data, for the Handler to return.
+func Output(tag byte, data []byte) *frame.PayloadFrame {
+	return frame.NewPayloadFrame(tag).SetCarriage(data)
+}
@@ -0,0 +1,93 @@
+package core
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessage(t *testing.T) {
+	f := frame.NewDataFrame()
+	f.SetCarriage(0x33, []byte("data"))
+	f.SetTransactionID("1234")
+	f.SetPriority(9)
+	f.GetMetaFrame().SetIssuer("source")
+	f.GetMetaFrame().SetMetadata("device", "d1")
+
+	msg := NewMessage(f)
+	assert.Equal(t, byte(0x33), msg.Tag)
+	assert.Equal(t, []byte("data"), msg.Payload)
+	assert.Equal(t, "1234", msg.TransactionID)
+	assert.Equal(t, "source", msg.Issuer)
+	assert.Equal(t, uint8(9), msg.Priority)
+	assert.Equal(t, "d1", msg.Metadata["device"])
+
+	// the metadata of message is a copy
+	msg.Metadata["device"] = "d2"
+	assert.Equal(t, "d1", f.GetMetaFrame().Metadata("device"))
+}
+
+func TestAsyncHandlerAdapter(t *testing.T) {
+	var fn AsyncHandler
+	assert.Nil(t, fn.Handler())
+
+	fn = func(data []byte) (byte, []byte) {
+		if len(data) == 0 {
+			return 0x34, nil
+		}
+		return 0x34, append(data, '!')
+	}
+	handler := fn.Handler()
+
+	outputs, err := handler(context.Background(), &Message{Tag: 0x33, Payload: []byte("hi")})
+	assert.NoError(t, err)
+	assert.Equal(t, []*frame.PayloadFrame{Output(0x34, []byte("hi!"))}, outputs)
+
+	// the empty response is not sent
+	outputs, err = handler(context.Background(), &Message{Tag: 0x33})
+	assert.NoError(t, err)
+	assert.Empty(t, outputs)
+}
+
+func TestPipeHandlerAdapter(t *testing.T) {
+	var fn PipeHandler
+	assert.Nil(t, fn.MessagePipeHandler())
+
+	fn = func(in <-chan []byte, out chan<- *frame.PayloadFrame) {
+		for data := range in {
+			out <- Output(0x34, data)
+		}
+		close(out)
+	}
+	in := make(chan *Message)
+	out := make(chan *frame.PayloadFrame)
+	go fn.MessagePipeHandler()(in, out)
+
+	in <- &Message{Tag: 0x33, Payload: []byte("hi")}
+	assert.Equal(t, Output(0x34, []byte("hi")), <-out)
+	close(in)
+	_, ok := <-out
+	assert.False(t, ok)
+}
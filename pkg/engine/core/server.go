@@ -42,6 +42,9 @@ const (
 	DefaultListenAddr = "0.0.0.0:9140"
 )
 
+// errLaneRejected is returned when a lane is opened on a connection which is not authenticated.
+var errLaneRejected = errors.New("lane of unauthenticated connection is rejected")
+
 type ServerOption func(*ServerOptions)
 
 // type FrameHandler func(store store.Store, stream quic.Stream, session quic.Session,
@@ -148,15 +151,15 @@ func (s *Server) Serve(ctx context.Context, conn net.PacketConn) error {
 					}
 					break
 				}
-				// TODO: ？
-				defer stream.Close()
-
 				logger.Infof("%s❤️4/ [stream:%d] created, connID=%s", ServerLogPrefix, stream.StreamID(), connID)
-				// process frames on stream
-				c := newContext(connID, stream)
-				defer c.Clean()
-				s.handleSession(c)
-				logger.Infof("%s❤️5/ [stream:%d] handleSession DONE", ServerLogPrefix, stream.StreamID())
+				// process frames on stream, the streams of priority lanes are handled concurrently
+				go func(stream quic.Stream) {
+					defer stream.Close()
+					c := newContext(connID, sess, stream)
+					defer c.Clean()
+					s.handleSession(c)
+					logger.Infof("%s❤️5/ [stream:%d] handleSession DONE", ServerLogPrefix, stream.StreamID())
+				}(stream)
 			}
 		}(sctx, session)
 	}
@@ -194,8 +197,8 @@ func (s *Server) handleSession(c *Context) {
 		f, err := fs.ReadFrame()
 		if err != nil {
 			// if client close connection, will get ApplicationError with code = 0x00
-			if e, ok := err.(*quic.ApplicationError); ok {
-				if e.ErrorCode == 0x00 {
+			if e := ErrorFrom(err); e != nil {
+				if e.Code == ErrorCodeClientAbort {
 					// client abort
 					logger.Infof("%sclient close the connection", ServerLogPrefix)
 					break
@@ -208,13 +211,13 @@ func (s *Server) handleSession(c *Context) {
 				// if client close the connection, net.ErrClosed will be raise
 				// by quic-go IdleTimeoutError after connection's KeepAlive config.
 				logger.Warnf("%s [ERR] net.ErrClosed on [handleSession] %v", ServerLogPrefix, net.ErrClosed)
-				c.CloseWithError(0xC1, "net.ErrClosed")
+				c.CloseWithError(ErrorCodeClosed, "net.ErrClosed")
 				break
 			}
 			// any error occurred, we should close the session
 			// after this, session.AcceptStream() will raise the error
 			// which specific in session.CloseWithError()
-			c.CloseWithError(0xC0, err.Error())
+			c.CloseWithError(ErrorCodeUnknown, err.Error())
 			logger.Warnf("%ssession.Close()", ServerLogPrefix)
 			break
 		}
@@ -228,22 +231,26 @@ func (s *Server) handleSession(c *Context) {
 		// before frame handlers
 		for _, handler := range s.beforeHandlers {
 			if err := handler(c); err != nil {
-				logger.Errorf("%safterFrameHandler err: %s", ServerLogPrefix, err)
-				c.CloseWithError(0xCC, err.Error())
+				logger.Errorf("%sbeforeFrameHandler err: %s", ServerLogPrefix, err)
+				c.CloseWithError(errorCodeOf(err, ErrorCodeFrameHandler), err.Error())
 				return
 			}
 		}
 		// main handler
 		if err := s.mainFrameHandler(c); err != nil {
 			logger.Errorf("%smainFrameHandler err: %s", ServerLogPrefix, err)
-			c.CloseWithError(0xCC, err.Error())
+			if errors.Is(err, errLaneRejected) {
+				// only the stream of lane is closed
+				return
+			}
+			c.CloseWithError(errorCodeOf(err, ErrorCodeUnknown), err.Error())
 			return
 		}
 		// after frame handler
 		for _, handler := range s.afterHandlers {
 			if err := handler(c); err != nil {
 				logger.Errorf("%safterFrameHandler err: %s", ServerLogPrefix, err)
-				c.CloseWithError(0xCC, err.Error())
+				c.CloseWithError(errorCodeOf(err, ErrorCodeFrameHandler), err.Error())
 				return
 			}
 		}
@@ -258,14 +265,18 @@ func (s *Server) mainFrameHandler(c *Context) error {
 	case frame.TagOfHandshakeFrame:
 		if err := s.handleHandshakeFrame(c); err != nil {
 			logger.Errorf("%shandleHandshakeFrame err: %s", ServerLogPrefix, err)
-			c.CloseWithError(0xCC, err.Error())
+			c.CloseWithError(errorCodeOf(err, ErrorCodeRejected), err.Error())
 			// break
 		}
 	// case frame.TagOfPingFrame:
 	// 	s.handlePingFrame(mainStream, session, f.(*frame.PingFrame))
+	case frame.TagOfLaneFrame:
+		if err := s.handleLaneFrame(c); err != nil {
+			return err
+		}
 	case frame.TagOfDataFrame:
 		if err := s.handleDataFrame(c); err != nil {
-			c.CloseWithError(0xCC, "DataFrame")
+			c.CloseWithError(ErrorCodeDataFrame, err.Error())
 		} else {
 			s.dispatchToDownstreams(c.Frame.(*frame.DataFrame))
 		}
@@ -284,8 +295,7 @@ func (s *Server) handleHandshakeFrame(c *Context) error {
 	logger.Infof("%sClientType=%# x is %s, CredentialType=%s", ServerLogPrefix, f.ClientType, ClientType(f.ClientType), auth.AuthType(f.AuthType()))
 	// authenticate
 	if !s.authenticate(f) {
-		err := fmt.Errorf("handshake authentication fails, client credential type is %s", auth.AuthType(f.AuthType()))
-		return err
+		return NewError(ErrorCodeRejected, fmt.Sprintf("handshake authentication fails, client credential type is %s", auth.AuthType(f.AuthType())))
 	}
 
 	// route
@@ -306,10 +316,9 @@ func (s *Server) handleHandshakeFrame(c *Context) error {
 	clientType := ClientType(f.ClientType)
 	name := f.Name
 	stream := c.Stream
+	var observed []byte
 	switch clientType {
 	case ClientTypeSource:
-		s.connector.Add(connID, stream)
-		s.connector.LinkApp(connID, appID, name, nil)
 	case ClientTypeStreamFunction:
 		// when Stream Function connects, it will provide its name to the server. The server will
 		// check, if this client has required permissions to connect with.
@@ -317,29 +326,52 @@ func (s *Server) handleHandshakeFrame(c *Context) error {
 			// unexpected client connected, close the connection
 			s.connector.Remove(connID)
 			// Bhojpur Service: stream function
-			err := fmt.Errorf("handshake router validation failed, illegal Stream Function[%s]", f.Name)
-			c.CloseWithError(0xCC, err.Error())
-			// break
-			return err
+			return NewError(ErrorCodeRejected, fmt.Sprintf("handshake router validation failed, illegal Stream Function[%s]", f.Name))
 		}
-
-		s.connector.Add(connID, stream)
-		// link connection to stream function
-		s.connector.LinkApp(connID, appID, name, f.ObserveDataTags)
+		observed = f.ObserveDataTags
 	case ClientTypeUpstreamProcessor:
-		s.connector.Add(connID, stream)
-		s.connector.LinkApp(connID, appID, name, nil)
 	default:
 		// unknown client type
 		s.connector.Remove(connID)
 		logger.Errorf("%sClientType=%# x, ilegal!", ServerLogPrefix, f.ClientType)
-		c.CloseWithError(0xCD, "Unknown ClientType, illegal!")
-		return errors.New("engine.server: Unknown ClientType, illegal")
+		return NewError(ErrorCodeIllegalClientType, "Unknown ClientType, illegal!")
+	}
+
+	// payload compression
+	compression := frame.CompressionNone
+	if f.Compression.IsSupported() {
+		compression = f.Compression
+	}
+	s.connector.SetCompression(connID, compression)
+	s.connector.Add(connID, stream)
+	// link connection to the app, the tags observed by stream function are linked as well, before the
+	// client is accepted, so the lanes it opens then belong to an authenticated connection
+	s.connector.LinkApp(connID, appID, name, observed)
+	// accept the client, the compression and lanes are ignored by the client which does not support them
+	if _, err := stream.Write((&frame.AcceptedFrame{Compression: compression, Lanes: true}).Encode()); err != nil {
+		s.connector.Remove(connID)
+		return err
 	}
 	logger.Printf("%s❤️  <%s> [%s::%s](%s) is connected!", ServerLogPrefix, clientType, appID, name, connID)
 	return nil
 }
 
+// handle LaneFrame, the stream is the lane of a priority class of the connection. The lane of a
+// connection which is not authenticated by its handshake is rejected, and its stream is closed.
+func (s *Server) handleLaneFrame(c *Context) error {
+	f := c.Frame.(*frame.LaneFrame)
+	logger.Debugf("%sGOT LaneFrame: connID=%s, lane=%s", ServerLogPrefix, c.ConnID, f.Lane)
+	if _, ok := s.connector.App(c.ConnID); !ok {
+		if stream, ok := c.Stream.(quic.Stream); ok {
+			stream.CancelRead(quic.StreamErrorCode(ErrorCodeRejected))
+		}
+		c.Stream.Close()
+		return fmt.Errorf("%w: connID=%s, lane=%s", errLaneRejected, c.ConnID, f.Lane)
+	}
+	s.connector.AddLane(c.ConnID, f.Lane, c.Stream)
+	return nil
+}
+
 // will reuse quic-go's keep-alive feature
 // func (s *Server) handlePingFrame(stream quic.Stream, session quic.Session, f *frame.PingFrame) error {
 // 	logger.Infof("%s------> GOT ❤️ PingFrame : %# x", ServerLogPrefix, f)
@@ -348,8 +380,7 @@ func (s *Server) handleHandshakeFrame(c *Context) error {
 
 func (s *Server) handleDataFrame(c *Context) error {
 	// counter +1
-	atomic.AddInt64(&s.counterOfDataFrame, 1)
-	// currentIssuer := f.GetIssuer()
+	counter := atomic.AddInt64(&s.counterOfDataFrame, 1)
 	fromID := c.ConnID
 	from, ok := s.connector.AppName(fromID)
 	if !ok {
@@ -358,6 +389,8 @@ func (s *Server) handleDataFrame(c *Context) error {
 	}
 
 	f := c.Frame.(*frame.DataFrame)
+	// the receivers know where the data comes from
+	f.GetMetaFrame().SetIssuer(from)
 
 	// route
 	appID, _ := s.connector.AppID(fromID)
@@ -377,12 +410,16 @@ func (s *Server) handleDataFrame(c *Context) error {
 	for _, to := range routes {
 		toIDs := s.connector.GetConnIDs(appID, to, f.GetDataTag())
 		for _, toID := range toIDs {
-			logger.Debugf("%shandleDataFrame tag=%#x tid=%s, counter=%d, from=[%s](%s), to=[%s](%s)", ServerLogPrefix, f.Tag(), f.TransactionID(), s.counterOfDataFrame, from, fromID, to, toID)
+			logger.Debugf("%shandleDataFrame tag=%#x tid=%s, counter=%d, from=[%s](%s), to=[%s](%s)", ServerLogPrefix, f.Tag(), f.TransactionID(), counter, from, fromID, to, toID)
 
 			// write data frame to stream
 			logger.Infof("%swrite data: [%s](%s) --> [%s](%s)", ServerLogPrefix, from, fromID, to, toID)
 			if err := s.connector.Write(f, toID); err != nil {
 				logger.Errorf("%swrite data: [%s](%s) --> [%s](%s), err=%v", ServerLogPrefix, from, fromID, to, toID, err)
+				// the carriage is a compression bomb, the frame fails rather than each target
+				if errors.Is(err, frame.ErrDecompressedTooLarge) {
+					return err
+				}
 				continue
 			}
 		}
@@ -398,7 +435,7 @@ func (s *Server) StatsFunctions() map[string]io.ReadWriteCloser {
 
 // StatsCounter returns how many DataFrames pass through server.
 func (s *Server) StatsCounter() int64 {
-	return s.counterOfDataFrame
+	return atomic.LoadInt64(&s.counterOfDataFrame)
 }
 
 // Downstreams return all the downstream servers.
@@ -0,0 +1,62 @@
+package core
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryStream is a stream in memory.
+type memoryStream struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (s *memoryStream) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestHandleLaneFrame(t *testing.T) {
+	s := &Server{connector: newConnector()}
+	lane := &memoryStream{}
+	c := &Context{ConnID: "conn", Stream: lane, Frame: frame.NewLaneFrame(frame.LaneHigh)}
+
+	// the lane of a connection which is not authenticated is rejected
+	err := s.handleLaneFrame(c)
+	assert.True(t, errors.Is(err, errLaneRejected))
+	assert.True(t, lane.closed)
+	_, ok := s.connector.(*connector).lanes.Load(laneKey("conn", frame.LaneHigh))
+	assert.False(t, ok)
+
+	s.connector.LinkApp("conn", "app", "source", nil)
+	lane = &memoryStream{}
+	c.Stream = lane
+	assert.NoError(t, s.handleLaneFrame(c))
+	assert.False(t, lane.closed)
+	_, ok = s.connector.(*connector).lanes.Load(laneKey("conn", frame.LaneHigh))
+	assert.True(t, ok)
+}
@@ -57,6 +57,8 @@ func ParseFrame(stream io.Reader) (frame.Frame, error) {
 		return frame.DecodeToAcceptedFrame(buf)
 	case 0x80 | byte(frame.TagOfRejectedFrame):
 		return frame.DecodeToRejectedFrame(buf)
+	case 0x80 | byte(frame.TagOfLaneFrame):
+		return frame.DecodeToLaneFrame(buf)
 	default:
 		return nil, fmt.Errorf("unknown frame type, buf[0]=%#x", buf[0])
 	}
@@ -0,0 +1,124 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"hash/fnv"
+	"runtime"
+	"sync"
+
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+)
+
+// dispatcher invokes the handler of stream function for the incoming DataFrames. By default,
+// every DataFrame is handled in a new goroutine. When the concurrency is limited, the frames
+// wait in a bounded queue, and dispatch blocks when the queue is full, which stops reading the
+// QUIC stream, so the flow control of QUIC applies the backpressure to the Processor.
+type dispatcher struct {
+	// queues are consumed by the workers, there is one queue shared by all the workers when the
+	// frames are not ordered, otherwise each worker has its own queue.
+	queues  []chan *frame.DataFrame
+	orderBy func(*frame.DataFrame) string
+	handle  func(*frame.DataFrame)
+	done    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+}
+
+// newDispatcher creates a dispatcher which invokes handle by the options.
+func newDispatcher(options *Options, handle func(*frame.DataFrame)) *dispatcher {
+	d := &dispatcher{
+		handle: handle,
+		done:   make(chan struct{}),
+	}
+
+	workers := options.MaxInFlight
+	switch {
+	case options.Sequential:
+		workers = 1
+		d.orderBy = func(*frame.DataFrame) string { return "" }
+	case options.OrderKey != "":
+		key := options.OrderKey
+		d.orderBy = func(f *frame.DataFrame) string { return f.GetMetaFrame().Metadata(key) }
+		if workers <= 0 {
+			workers = runtime.NumCPU()
+		}
+	}
+	if workers <= 0 {
+		// unlimited
+		return d
+	}
+
+	queues := 1
+	if d.orderBy != nil {
+		queues = workers
+	}
+	d.queues = make([]chan *frame.DataFrame, queues)
+	for i := range d.queues {
+		d.queues[i] = make(chan *frame.DataFrame, options.QueueSize)
+	}
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go d.work(d.queues[i%queues])
+	}
+	return d
+}
+
+// dispatch passes the frame to a worker, it blocks if the queue of the worker is full.
+func (d *dispatcher) dispatch(f *frame.DataFrame) {
+	if d.queues == nil {
+		go d.handle(f)
+		return
+	}
+
+	queue := d.queues[0]
+	if d.orderBy != nil && len(d.queues) > 1 {
+		// the frames of the same key always go to the same worker
+		h := fnv.New32a()
+		h.Write([]byte(d.orderBy(f)))
+		queue = d.queues[h.Sum32()%uint32(len(d.queues))]
+	}
+	select {
+	case queue <- f:
+	case <-d.done:
+	}
+}
+
+func (d *dispatcher) work(queue chan *frame.DataFrame) {
+	defer d.wg.Done()
+	for {
+		select {
+		case f := <-queue:
+			d.handle(f)
+		case <-d.done:
+			return
+		}
+	}
+}
+
+// close stops the workers, the frames in the queues are discarded, it waits for the handlers
+// in progress.
+func (d *dispatcher) close() {
+	d.once.Do(func() {
+		close(d.done)
+	})
+	d.wg.Wait()
+}
@@ -0,0 +1,162 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFrame(i int, key string) *frame.DataFrame {
+	f := frame.NewDataFrame()
+	f.SetCarriage(0x33, []byte(strconv.Itoa(i)))
+	if key != "" {
+		f.GetMetaFrame().SetMetadata("device", key)
+	}
+	return f
+}
+
+func TestDispatcherUnlimited(t *testing.T) {
+	var wg sync.WaitGroup
+	var count int32
+	d := newDispatcher(NewOptions(), func(f *frame.DataFrame) {
+		atomic.AddInt32(&count, 1)
+		wg.Done()
+	})
+	defer d.close()
+
+	wg.Add(100)
+	for i := 0; i < 100; i++ {
+		d.dispatch(newTestFrame(i, ""))
+	}
+	wg.Wait()
+	assert.Equal(t, int32(100), count)
+}
+
+func TestDispatcherMaxInFlight(t *testing.T) {
+	var wg sync.WaitGroup
+	var inFlight, max int32
+	d := newDispatcher(NewOptions(WithMaxInFlight(3), WithQueueSize(10)), func(f *frame.DataFrame) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		wg.Done()
+	})
+	defer d.close()
+
+	wg.Add(50)
+	for i := 0; i < 50; i++ {
+		d.dispatch(newTestFrame(i, ""))
+	}
+	wg.Wait()
+	assert.Equal(t, int32(3), max)
+}
+
+func TestDispatcherSequential(t *testing.T) {
+	var wg sync.WaitGroup
+	var handled []string
+	d := newDispatcher(NewOptions(WithSequential(), WithMaxInFlight(8)), func(f *frame.DataFrame) {
+		handled = append(handled, string(f.GetCarriage()))
+		wg.Done()
+	})
+	defer d.close()
+
+	var expected []string
+	wg.Add(100)
+	for i := 0; i < 100; i++ {
+		d.dispatch(newTestFrame(i, ""))
+		expected = append(expected, strconv.Itoa(i))
+	}
+	wg.Wait()
+	assert.Equal(t, expected, handled)
+}
+
+func TestDispatcherOrderKey(t *testing.T) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		handled = make(map[string][]string)
+	)
+	d := newDispatcher(NewOptions(WithOrderKey("device"), WithMaxInFlight(4), WithQueueSize(2)), func(f *frame.DataFrame) {
+		mu.Lock()
+		key := f.GetMetaFrame().Metadata("device")
+		handled[key] = append(handled[key], string(f.GetCarriage()))
+		mu.Unlock()
+		wg.Done()
+	})
+	defer d.close()
+
+	keys := []string{"a", "b", "c", "d", "e", ""}
+	expected := make(map[string][]string)
+	wg.Add(120)
+	for i := 0; i < 120; i++ {
+		key := keys[i%len(keys)]
+		d.dispatch(newTestFrame(i, key))
+		expected[key] = append(expected[key], strconv.Itoa(i))
+	}
+	wg.Wait()
+	assert.Equal(t, expected, handled)
+}
+
+func TestDispatcherBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	d := newDispatcher(NewOptions(WithMaxInFlight(1), WithQueueSize(1)), func(f *frame.DataFrame) {
+		<-release
+	})
+
+	// one frame is in progress, one is in the queue
+	d.dispatch(newTestFrame(0, ""))
+	d.dispatch(newTestFrame(1, ""))
+
+	dispatched := make(chan struct{})
+	go func() {
+		d.dispatch(newTestFrame(2, ""))
+		close(dispatched)
+	}()
+	select {
+	case <-dispatched:
+		t.Fatal("dispatch should block when the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-dispatched:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch should continue when the queue is available")
+	}
+	d.close()
+
+	// dispatch does not block after close
+	d.dispatch(newTestFrame(3, ""))
+}
@@ -21,6 +21,7 @@ package logger
 // THE SOFTWARE.
 
 import (
+	"fmt"
 	"os"
 	"strings"
 
@@ -34,6 +35,28 @@ func EnableDebug() {
 	logger = Default(true)
 }
 
+// SetLevel sets the level of the default logger.
+func SetLevel(lvl log.Level) {
+	l := Default()
+	l.SetLevel(lvl)
+	logger = l
+}
+
+// ParseLevel parses the level name, one of debug, info, warn or error.
+func ParseLevel(name string) (log.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return log.DebugLevel, nil
+	case "info":
+		return log.InfoLevel, nil
+	case "warn":
+		return log.WarnLevel, nil
+	case "error":
+		return log.ErrorLevel, nil
+	}
+	return log.NoLevel, fmt.Errorf("unknown log level: %q", name)
+}
+
 // Printf prints a formated message without a specified level.
 func Printf(format string, v ...interface{}) {
 	logger.Printf(format, v...)
@@ -74,16 +97,8 @@ func logFormat() string {
 }
 
 func logLevel() log.Level {
-	envLevel := strings.ToLower(os.Getenv("BHOJPUR_SERVICE_LOG_LEVEL"))
-	level := log.ErrorLevel
-	switch envLevel {
-	case "debug":
-		return log.DebugLevel
-	case "info":
-		return log.InfoLevel
-	case "warn":
-		return log.WarnLevel
-	case "error":
+	level, err := ParseLevel(os.Getenv("BHOJPUR_SERVICE_LOG_LEVEL"))
+	if err != nil {
 		return log.ErrorLevel
 	}
 	return level
@@ -23,6 +23,7 @@ package logger
 import (
 	stdlog "log"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/bhojpur/service/pkg/engine/core/log"
@@ -42,6 +43,7 @@ type zapLogger struct {
 	encoding    string
 	opts        []zap.Option
 	logger      *zap.Logger
+	mu          sync.Mutex
 	instance    *zap.SugaredLogger
 	output      string
 	errorOutput string
@@ -151,16 +153,18 @@ func (z *zapLogger) Infof(template string, args ...interface{}) {
 }
 
 // Warnf logs a message at WarnLevel
-func (z zapLogger) Warnf(template string, args ...interface{}) {
+func (z *zapLogger) Warnf(template string, args ...interface{}) {
 	z.Instance().Warnf(template, args...)
 }
 
 // Errorf logs a message at ErrorLevel
-func (z zapLogger) Errorf(template string, args ...interface{}) {
+func (z *zapLogger) Errorf(template string, args ...interface{}) {
 	z.Instance().Errorf(template, args...)
 }
 
 func (z *zapLogger) Instance() *zap.SugaredLogger {
+	z.mu.Lock()
+	defer z.mu.Unlock()
 	if z.instance == nil {
 		// zap
 		encoderConfig := zapcore.EncoderConfig{
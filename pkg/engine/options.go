@@ -26,7 +26,9 @@ import (
 	pkgauth "github.com/bhojpur/service/pkg/engine/auth"
 	engine "github.com/bhojpur/service/pkg/engine/core"
 	"github.com/bhojpur/service/pkg/engine/core/auth"
+	"github.com/bhojpur/service/pkg/engine/core/frame"
 	"github.com/bhojpur/service/pkg/engine/core/log"
+	"github.com/bhojpur/service/pkg/secretstores"
 	"github.com/lucas-clemente/quic-go"
 )
 
@@ -44,11 +46,17 @@ type Options struct {
 	// ProcessorListenAddr     string // Processor endpoint address
 	ProcessorWorkflowConfig string // Processor workflow file
 	MeshConfigURL           string // meshConfigURL is the URL of EdgeMesh config
+	AdminAddr               string // AdminAddr is the listen address of the admin API
 	ServerOptions           []engine.ServerOption
 	ClientOptions           []engine.ClientOption
 	QuicConfig              *quic.Config
 	TLSConfig               *tls.Config
 	Logger                  log.Logger
+	// the handler concurrency of stream function
+	MaxInFlight int    // the max number of handlers in progress, 0 is unlimited
+	OrderKey    string // the metadata key, the frames with the same value are handled in order
+	Sequential  bool   // the frames are handled one by one in order
+	QueueSize   int    // the number of frames waiting for the handlers
 }
 
 // WithProcessorAddr return a new options with ProcessorAddr set to addr.
@@ -74,6 +82,13 @@ func WithMeshConfigURL(url string) Option {
 	}
 }
 
+// WithAdminAddr sets the listen address of the admin API of Bhojpur Service-Processor.
+func WithAdminAddr(addr string) Option {
+	return func(o *Options) {
+		o.AdminAddr = addr
+	}
+}
+
 func WithTLSConfig(tc *tls.Config) Option {
 	return func(o *Options) {
 		o.TLSConfig = tc
@@ -133,6 +148,74 @@ func WithObserveDataTags(tags ...byte) Option {
 	}
 }
 
+// WithCompression sets the payload compression of client: zstd, snappy or gzip. It's
+// negotiated with the processor, the payload is uncompressed if the processor does not support it.
+func WithCompression(c frame.Compression) Option {
+	return func(o *Options) {
+		o.ClientOptions = append(
+			o.ClientOptions,
+			engine.WithCompression(c),
+		)
+	}
+}
+
+// WithCompressionThreshold sets the minimal payload size to be compressed.
+func WithCompressionThreshold(n int) Option {
+	return func(o *Options) {
+		o.ClientOptions = append(
+			o.ClientOptions,
+			engine.WithCompressionThreshold(n),
+		)
+	}
+}
+
+// WithEncryption encrypts the payload of tags end-to-end by the AEAD key which is looked up
+// by keyID from the secret store, all of the payload is encrypted if tags is empty. The
+// Bhojpur Service-Processor routes the data by tag and metadata without reading the payload.
+func WithEncryption(store secretstores.SecretStore, keyID string, tags ...byte) Option {
+	return func(o *Options) {
+		o.ClientOptions = append(
+			o.ClientOptions,
+			engine.WithEncryption(store, keyID, tags...),
+		)
+	}
+}
+
+// WithMaxInFlight limits the number of handlers of stream function in progress, the incoming
+// frames wait in the queue set by WithQueueSize. It's unlimited by default, which handles every
+// frame in a new goroutine, so the order of outputs is random.
+func WithMaxInFlight(n int) Option {
+	return func(o *Options) {
+		o.MaxInFlight = n
+	}
+}
+
+// WithOrderKey handles the frames with the same value of metadata key in the order of arrival,
+// and the frames of different values concurrently, the frames without the key are in order as
+// well. The concurrency is set by WithMaxInFlight, or the number of CPUs if it's unlimited.
+func WithOrderKey(key string) Option {
+	return func(o *Options) {
+		o.OrderKey = key
+	}
+}
+
+// WithSequential handles the frames one by one in the order of arrival, so are the outputs.
+func WithSequential() Option {
+	return func(o *Options) {
+		o.Sequential = true
+	}
+}
+
+// WithQueueSize sets the number of frames waiting for the handlers of stream function when the
+// concurrency is limited, it's per worker with WithOrderKey. When the queue is full, the stream
+// function stops reading from the Processor, so the backpressure is applied by the flow control
+// of QUIC. The default size is 0, which reads the next frame only when a handler is available.
+func WithQueueSize(n int) Option {
+	return func(o *Options) {
+		o.QueueSize = n
+	}
+}
+
 // WithLogger sets the client logger
 func WithLogger(logger log.Logger) Option {
 	return func(o *Options) {
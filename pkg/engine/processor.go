@@ -78,6 +78,7 @@ type processor struct {
 	server               *engine.Server
 	client               *engine.Client
 	downstreamProcessors []Processor
+	admin                *adminServer
 }
 
 var _ Processor = &processor{}
@@ -92,7 +93,7 @@ func NewProcessorWithOptions(name string, opts ...Option) Processor {
 }
 
 // NewProcessor create a Bhojpur Service-Processor instance from config files.
-func NewProcessor(conf string) (Processor, error) {
+func NewProcessor(conf string, opts ...Option) (Processor, error) {
 	config, err := config.ParseWorkflowConfig(conf)
 	if err != nil {
 		logger.Errorf("%s[ERR] %v", processorLogPrefix, err)
@@ -101,7 +102,7 @@ func NewProcessor(conf string) (Processor, error) {
 	// listening address
 	listenAddr := fmt.Sprintf("%s:%d", config.Host, config.Port)
 
-	options := NewOptions()
+	options := NewOptions(opts...)
 	options.ProcessorAddr = listenAddr
 	processor := createProcessorServer(config.Name, options)
 	// processor workflow
@@ -125,13 +126,24 @@ func NewDownstreamProcessor(name string, opts ...Option) Processor {
 /*************** Server ONLY ***************/
 // createProcessorServer create a Bhojpur Service-Processor instance as a server engine.
 func createProcessorServer(name string, options *Options) *processor {
+	serverOptions := options.ServerOptions
+	if options.TLSConfig != nil {
+		serverOptions = append(serverOptions, engine.WithServerTLSConfig(options.TLSConfig))
+	}
+	if options.QuicConfig != nil {
+		serverOptions = append(serverOptions, engine.WithServerQuicConfig(options.QuicConfig))
+	}
 	// create underlying QUIC server
-	srv := engine.NewServer(name, options.ServerOptions...)
+	srv := engine.NewServer(name, serverOptions...)
 	z := &processor{
 		server: srv,
 		name:   name,
 		addr:   options.ProcessorAddr,
 	}
+	// admin API
+	if options.AdminAddr != "" {
+		z.admin = newAdminServer(options.AdminAddr, z)
+	}
 	// initialize
 	z.init()
 	return z
@@ -203,6 +215,14 @@ func (z *processor) ListenAndServe() error {
 			}(dsProcessor)
 		}
 	}
+	// admin API
+	if z.admin != nil {
+		go func() {
+			if err := z.admin.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Errorf("%sadmin API: %v", processorLogPrefix, err)
+			}
+		}()
+	}
 	return z.server.ListenAndServe(context.Background(), z.addr)
 }
 
@@ -237,6 +257,12 @@ func (z *processor) Addr() string {
 
 // Close will close a connection. If processor is Server, close the server. If processor is Client, close the client.
 func (z *processor) Close() error {
+	if z.admin != nil {
+		if err := z.admin.Close(); err != nil {
+			logger.Errorf("%s Close(): %v", processorLogPrefix, err)
+			return err
+		}
+	}
 	if z.server != nil {
 		if err := z.server.Close(); err != nil {
 			logger.Errorf("%s Close(): %v", processorLogPrefix, err)
@@ -45,6 +45,15 @@ type Source interface {
 	WriteWithTag(tag uint8, data []byte) error
 }
 
+// PrioritySource is a Source which writes the data with priority, the Source created by NewSource
+// implements it, e.g., source.(PrioritySource).WriteWithPriority(0x33, 9, alarm).
+type PrioritySource interface {
+	Source
+	// WriteWithPriority will write data with specified tag and priority, the data with
+	// priority above 0 is transferred on the high-priority lane.
+	WriteWithPriority(tag uint8, priority uint8, data []byte) error
+}
+
 // Bhojpur Service Data-Source
 type dataSource struct {
 	name              string
@@ -53,7 +62,10 @@ type dataSource struct {
 	tag               uint8
 }
 
-var _ Source = &dataSource{}
+var (
+	_ PrioritySource     = &dataSource{}
+	_ ErrorHandlerSetter = &dataSource{}
+)
 
 // NewSource create a Bhojpur Service Data-Source
 func NewSource(name string, opts ...Option) Source {
@@ -77,6 +89,11 @@ func (s *dataSource) SetDataTag(tag uint8) {
 	s.tag = tag
 }
 
+// SetErrorHandler set the handler which is invoked when the connection is closed by an error.
+func (s *dataSource) SetErrorHandler(fn func(err error)) {
+	s.client.SetErrorHandler(fn)
+}
+
 // Close will close the connection to Bhojpur Service-Processor.
 func (s *dataSource) Close() error {
 	if err := s.client.Close(); err != nil {
@@ -98,8 +115,14 @@ func (s *dataSource) Connect() error {
 
 // WriteWithTag will write data with specified tag, default transactionID is epoch time.
 func (s *dataSource) WriteWithTag(tag uint8, data []byte) error {
-	s.client.Logger().Debugf("%sWriteWithTag: len(data)=%d, data=%# x", sourceLogPrefix, len(data), frame.Shortly(data))
+	return s.WriteWithPriority(tag, 0, data)
+}
+
+// WriteWithPriority will write data with specified tag and priority.
+func (s *dataSource) WriteWithPriority(tag uint8, priority uint8, data []byte) error {
+	s.client.Logger().Debugf("%sWriteWithPriority: priority=%d, len(data)=%d, data=%# x", sourceLogPrefix, priority, len(data), frame.Shortly(data))
 	frame := frame.NewDataFrame()
 	frame.SetCarriage(byte(tag), data)
+	frame.SetPriority(priority)
 	return s.client.WriteFrame(frame)
 }
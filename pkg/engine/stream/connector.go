@@ -0,0 +1,265 @@
+package stream
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/bhojpur/service/pkg/bindings"
+	"github.com/bhojpur/service/pkg/pubsub"
+	"github.com/bhojpur/service/pkg/reactive"
+	"github.com/bhojpur/service/pkg/state"
+	"github.com/bhojpur/service/pkg/utils/retry"
+)
+
+// ConnectorOption is a function that applies an option of the sinks and sources of components.
+type ConnectorOption func(o *connectorOptions)
+
+type connectorOptions struct {
+	retry       retry.Config
+	marshaller  Marshaller
+	metadata    map[string]string
+	contentType *string
+}
+
+// WithConnectorRetry sets how the failed writes of a sink, and the failed subscriptions of a source,
+// are retried, the default is 3 retries with exponential back off.
+func WithConnectorRetry(config retry.Config) ConnectorOption {
+	return func(o *connectorOptions) {
+		o.retry = config
+	}
+}
+
+// WithConnectorMarshaller sets how the items are marshalled by a sink, the default sends []byte
+// as it is, and marshals the other items to JSON.
+func WithConnectorMarshaller(marshaller Marshaller) ConnectorOption {
+	return func(o *connectorOptions) {
+		o.marshaller = marshaller
+	}
+}
+
+// WithConnectorMetadata sets the metadata of the requests to the component.
+func WithConnectorMetadata(metadata map[string]string) ConnectorOption {
+	return func(o *connectorOptions) {
+		o.metadata = metadata
+	}
+}
+
+// WithConnectorContentType sets the content type of the published messages.
+func WithConnectorContentType(contentType string) ConnectorOption {
+	return func(o *connectorOptions) {
+		o.contentType = &contentType
+	}
+}
+
+func parseConnectorOptions(opts ...ConnectorOption) *connectorOptions {
+	o := &connectorOptions{
+		retry: retry.Config{
+			Policy:              retry.PolicyExponential,
+			InitialInterval:     100 * time.Millisecond,
+			RandomizationFactor: backoff.DefaultRandomizationFactor,
+			Multiplier:          backoff.DefaultMultiplier,
+			MaxInterval:         5 * time.Second,
+			MaxElapsedTime:      time.Minute,
+			MaxRetries:          3,
+		},
+		marshaller: marshalItem,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func marshalItem(i interface{}) ([]byte, error) {
+	if buf, ok := i.([]byte); ok {
+		return buf, nil
+	}
+	return json.Marshal(i)
+}
+
+// sink writes each item emitted by the Stream by write, which is retried by the retry config, and
+// emits the item when it is written, or an error when the retries are exhausted.
+func (s *StreamImpl) sink(o *connectorOptions, write func(ctx context.Context, i interface{}, data []byte) error) Stream {
+	f := func(ctx context.Context, next chan reactive.Item) {
+		defer close(next)
+		observe := s.Observe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-observe:
+				if !ok {
+					return
+				}
+				if item.Error() {
+					if !item.SendContext(ctx, next) {
+						return
+					}
+					continue
+				}
+				data, err := o.marshaller(item.V)
+				if err == nil {
+					err = backoff.Retry(func() error {
+						return write(ctx, item.V, data)
+					}, o.retry.NewBackOffWithContext(ctx))
+				}
+				if err != nil {
+					item = reactive.Error(err)
+				}
+				if !item.SendContext(ctx, next) {
+					return
+				}
+			}
+		}
+	}
+	return CreateObservable(s.ctx, f)
+}
+
+// ToStateStore saves each item emitted by an Observable to store by the key of item, and emits the saved items.
+func (s *StreamImpl) ToStateStore(store state.Store, key func(interface{}) string, opts ...ConnectorOption) Stream {
+	if store == nil {
+		return s.thrown(errors.New("state store must not be nil"))
+	}
+	o := parseConnectorOptions(opts...)
+	return s.sink(o, func(_ context.Context, i interface{}, data []byte) error {
+		err := store.Set(&state.SetRequest{Key: key(i), Value: data, Metadata: o.metadata})
+		if err != nil {
+			return fmt.Errorf("save state %s: %w", key(i), err)
+		}
+		return nil
+	})
+}
+
+// ToPubSub publishes each item emitted by an Observable to topic, and emits the published items.
+func (s *StreamImpl) ToPubSub(ps pubsub.PubSub, topic string, opts ...ConnectorOption) Stream {
+	if ps == nil {
+		return s.thrown(errors.New("pubsub must not be nil"))
+	}
+	o := parseConnectorOptions(opts...)
+	return s.sink(o, func(_ context.Context, _ interface{}, data []byte) error {
+		err := ps.Publish(&pubsub.PublishRequest{Data: data, Topic: topic, Metadata: o.metadata, ContentType: o.contentType})
+		if err != nil {
+			return fmt.Errorf("publish to %s: %w", topic, err)
+		}
+		return nil
+	})
+}
+
+// ToBinding invokes operation of binding with each item emitted by an Observable, and emits the items.
+func (s *StreamImpl) ToBinding(binding bindings.OutputBinding, operation bindings.OperationKind, opts ...ConnectorOption) Stream {
+	if binding == nil {
+		return s.thrown(errors.New("output binding must not be nil"))
+	}
+	o := parseConnectorOptions(opts...)
+	return s.sink(o, func(_ context.Context, _ interface{}, data []byte) error {
+		_, err := binding.Invoke(&bindings.InvokeRequest{Data: data, Metadata: o.metadata, Operation: operation})
+		if err != nil {
+			return fmt.Errorf("invoke %s: %w", operation, err)
+		}
+		return nil
+	})
+}
+
+// source emits the data received by subscribe until ctx is done. The subscription is retried by the retry
+// config, an error is emitted and the Stream completes when the retries are exhausted. The received data
+// is acknowledged when it's taken by the downstream, and rejected after ctx is done, so the component
+// delivers it again.
+func source(ctx context.Context, o *connectorOptions, subscribe func(receive func(data []byte) error) error) Stream {
+	f := func(ctx context.Context, next chan reactive.Item) {
+		var mu sync.RWMutex
+		closed := false
+		defer func() {
+			mu.Lock()
+			closed = true
+			close(next)
+			mu.Unlock()
+		}()
+
+		receive := func(data []byte) error {
+			mu.RLock()
+			defer mu.RUnlock()
+			if closed {
+				return context.Canceled
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case next <- Of(data):
+				return nil
+			}
+		}
+
+		// subscribe may block while the data is received
+		errc := make(chan error, 1)
+		go func() {
+			errc <- backoff.Retry(func() error {
+				return subscribe(receive)
+			}, o.retry.NewBackOffWithContext(ctx))
+		}()
+		select {
+		case <-ctx.Done():
+		case err := <-errc:
+			if err != nil && ctx.Err() == nil {
+				reactive.Error(err).SendContext(ctx, next)
+				return
+			}
+			<-ctx.Done()
+		}
+	}
+	return CreateObservable(ctx, f)
+}
+
+// FromPubSub creates a new Reactive Stream of the data of the messages of topic, until ctx is done.
+func (fac *factoryImpl) FromPubSub(ctx context.Context, ps pubsub.PubSub, topic string, opts ...ConnectorOption) Stream {
+	o := parseConnectorOptions(opts...)
+	return source(ctx, o, func(receive func(data []byte) error) error {
+		err := ps.Subscribe(pubsub.SubscribeRequest{Topic: topic, Metadata: o.metadata}, func(_ context.Context, msg *pubsub.NewMessage) error {
+			return receive(msg.Data)
+		})
+		if err != nil {
+			return fmt.Errorf("subscribe to %s: %w", topic, err)
+		}
+		return nil
+	})
+}
+
+// FromInputBinding creates a new Reactive Stream of the data read from binding, until ctx is done.
+func (fac *factoryImpl) FromInputBinding(ctx context.Context, binding bindings.InputBinding, opts ...ConnectorOption) Stream {
+	o := parseConnectorOptions(opts...)
+	return source(ctx, o, func(receive func(data []byte) error) error {
+		err := binding.Read(func(resp *bindings.ReadResponse) ([]byte, error) {
+			return nil, receive(resp.Data)
+		})
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("read binding: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,204 @@
+package stream
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/service/pkg/bindings"
+	"github.com/bhojpur/service/pkg/pubsub"
+	"github.com/bhojpur/service/pkg/reactive"
+	"github.com/bhojpur/service/pkg/utils/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePubSub is a pubsub.PubSub in memory, the first failures publications fail.
+type fakePubSub struct {
+	mu        sync.Mutex
+	failures  int
+	published []*pubsub.PublishRequest
+	handlers  chan pubsub.Handler
+}
+
+func newFakePubSub() *fakePubSub {
+	return &fakePubSub{handlers: make(chan pubsub.Handler, 1)}
+}
+
+func (p *fakePubSub) Init(metadata pubsub.Metadata) error { return nil }
+func (p *fakePubSub) Features() []pubsub.Feature          { return nil }
+func (p *fakePubSub) Close() error                        { return nil }
+
+func (p *fakePubSub) Publish(req *pubsub.PublishRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker is down")
+	}
+	p.published = append(p.published, req)
+	return nil
+}
+
+func (p *fakePubSub) Subscribe(req pubsub.SubscribeRequest, handler pubsub.Handler) error {
+	p.handlers <- handler
+	return nil
+}
+
+// fakeBinding is an input and output binding in memory.
+type fakeBinding struct {
+	mu       sync.Mutex
+	invoked  []*bindings.InvokeRequest
+	failures int
+	reads    [][]byte
+	results  chan error
+}
+
+func (b *fakeBinding) Init(metadata bindings.Metadata) error { return nil }
+func (b *fakeBinding) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{bindings.CreateOperation}
+}
+
+func (b *fakeBinding) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.invoked = append(b.invoked, req)
+	return &bindings.InvokeResponse{}, nil
+}
+
+func (b *fakeBinding) Read(handler func(*bindings.ReadResponse) ([]byte, error)) error {
+	b.mu.Lock()
+	if b.failures > 0 {
+		b.failures--
+		b.mu.Unlock()
+		return errors.New("connection refused")
+	}
+	b.mu.Unlock()
+	for _, data := range b.reads {
+		_, err := handler(&bindings.ReadResponse{Data: data})
+		b.results <- err
+	}
+	return nil
+}
+
+func fastRetry(maxRetries int64) ConnectorOption {
+	return WithConnectorRetry(retry.Config{Policy: retry.PolicyConstant, Duration: time.Millisecond, MaxRetries: maxRetries})
+}
+
+type reading struct {
+	Device string  `json:"device"`
+	Value  float64 `json:"value"`
+}
+
+func Test_ToStateStore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newMemoryStore()
+
+	st := toStream(reactive.Just(reading{"a", 1}, reading{"b", 2})()).
+		ToStateStore(store, func(i interface{}) string { return i.(reading).Device })
+	reactive.Assert(ctx, t, st, reactive.HasItems(reading{"a", 1}, reading{"b", 2}), reactive.HasNoError())
+	assert.Equal(t, `{"device":"a","value":1}`, string(store.items["a"]))
+	assert.Equal(t, `{"device":"b","value":2}`, string(store.items["b"]))
+}
+
+func Test_ToStateStore_RetriesExhausted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newMemoryStore()
+	store.failed = true
+
+	// an error is emitted for each item, as the Stream continues on error
+	st := toStream(reactive.Just("a", "b")()).
+		ToStateStore(store, func(i interface{}) string { return i.(string) }, fastRetry(2))
+	reactive.Assert(ctx, t, st, reactive.IsEmpty(), reactive.HasAnError())
+	assert.Empty(t, store.items)
+}
+
+func Test_ToPubSub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ps := newFakePubSub()
+	ps.failures = 2
+
+	st := toStream(reactive.Just(reading{"a", 1}, "b")()).
+		ToPubSub(ps, "readings", fastRetry(3), WithConnectorMetadata(map[string]string{"ttlInSeconds": "60"}))
+	reactive.Assert(ctx, t, st, reactive.HasItems(reading{"a", 1}, "b"), reactive.HasNoError())
+	assert.Len(t, ps.published, 2)
+	assert.Equal(t, "readings", ps.published[0].Topic)
+	assert.Equal(t, []byte(`{"device":"a","value":1}`), ps.published[0].Data)
+	assert.Equal(t, []byte(`"b"`), ps.published[1].Data)
+	assert.Equal(t, "60", ps.published[1].Metadata["ttlInSeconds"])
+}
+
+func Test_ToBinding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	binding := &fakeBinding{}
+
+	st := toStream(reactive.Just(1, 2)()).ToBinding(binding, bindings.CreateOperation)
+	reactive.Assert(ctx, t, st, reactive.HasItems(1, 2))
+	assert.Len(t, binding.invoked, 2)
+	assert.Equal(t, bindings.CreateOperation, binding.invoked[1].Operation)
+	assert.Equal(t, []byte("2"), binding.invoked[1].Data)
+}
+
+func Test_FromPubSub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ps := newFakePubSub()
+
+	observe := NewFactory().FromPubSub(ctx, ps, "readings").Observe()
+	handler := <-ps.handlers
+	go func() {
+		assert.NoError(t, handler(ctx, &pubsub.NewMessage{Topic: "readings", Data: []byte("a")}))
+	}()
+	assert.Equal(t, []byte("a"), (<-observe).V)
+
+	cancel()
+	for range observe {
+	}
+	// the messages after the Stream completes are rejected
+	assert.Error(t, handler(context.Background(), &pubsub.NewMessage{Topic: "readings", Data: []byte("b")}))
+}
+
+func Test_FromInputBinding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	binding := &fakeBinding{failures: 1, reads: [][]byte{[]byte("a"), []byte("b")}, results: make(chan error, 2)}
+
+	observe := NewFactory().FromInputBinding(ctx, binding, fastRetry(1)).Observe()
+	assert.Equal(t, []byte("a"), (<-observe).V)
+	assert.Equal(t, []byte("b"), (<-observe).V)
+	assert.NoError(t, <-binding.results)
+	assert.NoError(t, <-binding.results)
+}
+
+func Test_FromInputBinding_RetriesExhausted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	binding := &fakeBinding{failures: 3}
+
+	st := NewFactory().FromInputBinding(ctx, binding, fastRetry(1))
+	reactive.Assert(ctx, t, st, reactive.IsEmpty(), reactive.HasAnError())
+}
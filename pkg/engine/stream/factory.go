@@ -23,6 +23,8 @@ package stream
 import (
 	"context"
 
+	"github.com/bhojpur/service/pkg/bindings"
+	"github.com/bhojpur/service/pkg/pubsub"
 	"github.com/bhojpur/service/pkg/reactive"
 )
 
@@ -33,6 +35,12 @@ type Factory interface {
 
 	// FromItems creates a new Stream from items.
 	FromItems(ctx context.Context, items []interface{}) Stream
+
+	// FromPubSub creates a new Stream of the data of the messages of topic, until ctx is done.
+	FromPubSub(ctx context.Context, ps pubsub.PubSub, topic string, opts ...ConnectorOption) Stream
+
+	// FromInputBinding creates a new Stream of the data read from binding, until ctx is done.
+	FromInputBinding(ctx context.Context, binding bindings.InputBinding, opts ...ConnectorOption) Stream
 }
 
 type factoryImpl struct {
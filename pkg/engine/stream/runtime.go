@@ -22,36 +22,181 @@ package stream
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 
 	svcsvr "github.com/bhojpur/service/pkg/engine"
+	engine "github.com/bhojpur/service/pkg/engine/core"
 	"github.com/bhojpur/service/pkg/engine/core/frame"
 	"github.com/bhojpur/service/pkg/engine/logger"
+	"github.com/bhojpur/service/pkg/reactive"
 )
 
 // Runtime is the Reactive Stream serverless runtime engine.
 type Runtime struct {
 	rawBytesChan chan interface{}
+	taggedChans  map[byte]chan interface{}
 	sfn          svcsvr.StreamFunction
 	stream       Stream
+	rxHandler    func(rxstream Stream) Stream
+	monitor      *reactive.Monitor
+
+	dispatchOnce sync.Once
+	sendMu       sync.Mutex
+	requestsMu   sync.Mutex
+	requestID    uint64
+	requests     []*rawByteRequest
+}
+
+// rawByteRequest is a data of RawByteHandler, which gets the first output emitted before its end.
+type rawByteRequest struct {
+	id     uint64
+	output *frame.PayloadFrame
+	done   chan *frame.PayloadFrame
+}
+
+// requestEnd marks the end of a data of RawByteHandler in the Reactive Stream, it's sent after the data as
+// an error, so the operators pass it on in order with the outputs, and the Reactive Handler doesn't see it.
+type requestEnd struct {
+	id uint64
+}
+
+func (e requestEnd) Error() string {
+	return fmt.Sprintf("end of request %d", e.id)
+}
+
+// isRequestEnd returns if err marks the end of a data of RawByteHandler.
+func isRequestEnd(err error) bool {
+	var end requestEnd
+	return errors.As(err, &end)
+}
+
+// RuntimeOption is a function that applies a Runtime option.
+type RuntimeOption func(r *Runtime)
+
+// WithOperatorMonitor instruments the operators of the Reactive Streams of the Runtime, their metrics and
+// graph are dumped by OperatorGraph.
+func WithOperatorMonitor(monitor *reactive.Monitor) RuntimeOption {
+	return func(r *Runtime) {
+		r.monitor = monitor
+	}
 }
 
 // NewRuntime creates a new Reactive Stream serverless runtime engine instance.
-func NewRuntime(sfn svcsvr.StreamFunction) *Runtime {
-	return &Runtime{
+func NewRuntime(sfn svcsvr.StreamFunction, opts ...RuntimeOption) *Runtime {
+	r := &Runtime{
 		rawBytesChan: make(chan interface{}),
+		taggedChans:  make(map[byte]chan interface{}),
 		sfn:          sfn,
 	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// OperatorGraph returns the live graph of the operators of the Reactive Streams, with their metrics. The
+// Runtime must be created with WithOperatorMonitor.
+func (r *Runtime) OperatorGraph() reactive.OperatorGraph {
+	if r.monitor == nil {
+		return reactive.OperatorGraph{}
+	}
+	return r.monitor.Graph()
+}
+
+// streamContext returns the context of the Reactive Streams, which instruments their operators.
+func (r *Runtime) streamContext() context.Context {
+	ctx := context.Background()
+	if r.monitor != nil {
+		ctx = reactive.ContextWithMonitor(ctx, r.monitor)
+	}
+	return ctx
+}
+
+// IsolatedHandler is the Stream Handler which correlates the outputs with their input, for
+// SetContextHandler: the data is processed by a Reactive Stream of its own, created by calling the
+// Reactive Handler of Pipe again, and the outputs are the frame.PayloadFrame items it emits until it
+// completes, zero or several. So the concurrent data don't get the outputs of each other, but the state
+// of operators, e.g., Scan, Distinct or WindowWithCount, is not kept from one data to another, and the
+// side effects of the Reactive Handler run for each data; RawByteHandler and PipeHandler share one
+// Reactive Stream. The operators of these Reactive Streams are not instrumented by the monitor.
+func (r *Runtime) IsolatedHandler(ctx context.Context, msg *engine.Message) ([]*frame.PayloadFrame, error) {
+	if r.rxHandler == nil {
+		return nil, errors.New("the Reactive Handler is not set by Pipe")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	in := make(chan interface{}, 1)
+	in <- msg.Payload
+	close(in)
+	st := r.rxHandler(NewFactory().FromChannel(ctx, in))
+
+	var outputs []*frame.PayloadFrame
+	for item := range st.Observe() {
+		if item.Error() {
+			logger.Errorf("[Reactive IsolatedHandler] Handler got an error, err=%v", item.E)
+			continue
+		}
+
+		if item.V == nil {
+			logger.Warnf("[Reactive IsolatedHandler] the returned data is nil.")
+			continue
+		}
+
+		res, ok := (item.V).(frame.PayloadFrame)
+		if !ok {
+			logger.Warnf("[Reactive IsolatedHandler] the data is not a frame.PayloadFrame, won't send it to Bhojpur Service-Processor.")
+			continue
+		}
+
+		logger.Infof("[Reactive IsolatedHandler] Send data with [tag=%#x] to Bhojpur Service-Processor.", res.Tag)
+		outputs = append(outputs, &res)
+	}
+	return outputs, nil
 }
 
-// RawByteHandler is the Stream Handler for RawBytes.
+// RawByteHandler is the Stream Handler for RawBytes, the data of the concurrent calls share the Reactive
+// Stream of Pipe. Every data is followed by the mark of its end, so it gets the first frame.PayloadFrame
+// emitted after the end of the previous data and before its own end, or none when it's filtered out, e.g.,
+// by Filter. The end is passed on as an error, so the Reactive Handler must not recover from the errors,
+// e.g., by OnErrorReturn, and the outputs held by an operator, e.g., BufferWithCount, go to a later data.
 func (r *Runtime) RawByteHandler(req []byte) (byte, []byte) {
-	go func() {
-		r.rawBytesChan <- req
-	}()
+	r.dispatchOnce.Do(func() {
+		go r.dispatch()
+	})
+
+	// the data and its end are sent together, so the outputs of the concurrent data come one after another
+	r.sendMu.Lock()
+	r.requestsMu.Lock()
+	r.requestID++
+	request := &rawByteRequest{id: r.requestID, done: make(chan *frame.PayloadFrame, 1)}
+	r.requests = append(r.requests, request)
+	r.requestsMu.Unlock()
+	r.rawBytesChan <- req
+	r.rawBytesChan <- requestEnd{id: request.id}
+	r.sendMu.Unlock()
+
+	res := <-request.done
+	if res == nil {
+		// return empty data when the data has no output.
+		return 0x0, nil
+	}
+	logger.Infof("[RawByteHandler] Send data with [tag=%#x] to Bhojpur Service-Processor.", res.Tag)
+	return res.Tag, res.Carriage
+}
 
-	// observe the data from Reactive Stream.
+// dispatch observes the data from Reactive Stream, and passes the outputs to the first pending data of
+// RawByteHandler, until its end.
+func (r *Runtime) dispatch() {
 	for item := range r.stream.Observe() {
 		if item.Error() {
+			var end requestEnd
+			if errors.As(item.E, &end) {
+				r.finish(end.id)
+				continue
+			}
 			logger.Errorf("[Reactive Handler] Handler got an error, err=%v", item.E)
 			continue
 		}
@@ -67,12 +212,38 @@ func (r *Runtime) RawByteHandler(req []byte) (byte, []byte) {
 			continue
 		}
 
-		logger.Infof("[RawByteHandler] Send data with [tag=%#x] to Bhojpur Service-Processor.", res.Tag)
-		return res.Tag, res.Carriage
+		r.requestsMu.Lock()
+		switch {
+		case len(r.requests) == 0:
+			logger.Warnf("[Reactive Handler] no data is waiting for the output with [tag=%#x], drop it.", res.Tag)
+		case r.requests[0].output != nil:
+			logger.Warnf("[Reactive Handler] the data has an output already, drop the output with [tag=%#x].", res.Tag)
+		default:
+			r.requests[0].output = &res
+		}
+		r.requestsMu.Unlock()
+	}
+
+	// the Reactive Stream is completed, the pending data get no output.
+	r.requestsMu.Lock()
+	defer r.requestsMu.Unlock()
+	for _, request := range r.requests {
+		request.done <- request.output
 	}
+	r.requests = nil
+}
 
-	// return empty data by default, the new data from Reactive Stream will be returned in `Pipe` function.
-	return 0x0, nil
+// finish returns the output of the data of id to RawByteHandler.
+func (r *Runtime) finish(id uint64) {
+	r.requestsMu.Lock()
+	defer r.requestsMu.Unlock()
+	if len(r.requests) == 0 || r.requests[0].id != id {
+		logger.Errorf("[Reactive Handler] the end of request %d is out of order.", id)
+		return
+	}
+	request := r.requests[0]
+	r.requests = r.requests[1:]
+	request.done <- request.output
 }
 
 // PipeHandler processes data sequentially.
@@ -104,12 +275,42 @@ func (r *Runtime) PipeHandler(in <-chan []byte, out chan<- *frame.PayloadFrame)
 	}
 }
 
+// MessagePipeHandler processes data sequentially, the data of a tag is sent to its Tagged Stream, if
+// there is one, or to the Reactive Stream of Pipe.
+func (r *Runtime) MessagePipeHandler(in <-chan *engine.Message, out chan<- *frame.PayloadFrame) {
+	payloads := make(chan []byte)
+	go func() {
+		defer close(payloads)
+		for msg := range in {
+			if ch, ok := r.taggedChans[msg.Tag]; ok {
+				ch <- msg.Payload
+				continue
+			}
+			payloads <- msg.Payload
+		}
+	}()
+	r.PipeHandler(payloads, out)
+}
+
+// Tagged creates a Reactive Stream of the data of tag, it must be called in the Reactive Handler of Pipe,
+// and the stream function must set MessagePipeHandler as its pipe handler. The Tagged Streams of several
+// tags observed by the same stream function can be combined, e.g., by JoinByKey.
+func (r *Runtime) Tagged(tag byte) Stream {
+	ch, ok := r.taggedChans[tag]
+	if !ok {
+		ch = make(chan interface{})
+		r.taggedChans[tag] = ch
+	}
+	return NewFactory().FromChannel(r.streamContext(), ch)
+}
+
 // Pipe the Reactive Handler with Reactive Stream.
 func (r *Runtime) Pipe(rxHandler func(rxstream Stream) Stream) {
 	fac := NewFactory()
 	// create a Reactive Stream from raw bytes channel.
-	rxstream := fac.FromChannel(context.Background(), r.rawBytesChan)
+	rxstream := fac.FromChannel(r.streamContext(), r.rawBytesChan)
 
 	// run Reactive Handler and get a new Reactive Stream.
+	r.rxHandler = rxHandler
 	r.stream = rxHandler(rxstream)
 }
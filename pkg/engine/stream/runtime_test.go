@@ -0,0 +1,156 @@
+package stream
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	engine "github.com/bhojpur/service/pkg/engine/core"
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+	"github.com/bhojpur/service/pkg/reactive"
+	"github.com/stretchr/testify/assert"
+)
+
+// oddSquares passes the squares of the odd numbers back to the Processor.
+func oddSquares(st Stream) Stream {
+	return st.
+		Map(func(_ context.Context, i interface{}) (interface{}, error) {
+			return strconv.Atoi(string(i.([]byte)))
+		}).
+		Filter(func(i interface{}) bool {
+			return i.(int)%2 == 1
+		}).
+		Map(func(_ context.Context, i interface{}) (interface{}, error) {
+			return []byte(strconv.Itoa(i.(int) * i.(int))), nil
+		}).
+		PipeBackToProcessor(0x11)
+}
+
+func Test_Runtime_IsolatedHandler_Concurrent(t *testing.T) {
+	rt := NewRuntime(nil)
+	rt.Pipe(oddSquares)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			outputs, err := rt.IsolatedHandler(context.Background(), &engine.Message{Payload: []byte(strconv.Itoa(i))})
+			assert.NoError(t, err)
+			if i%2 == 0 {
+				// the filtered out data doesn't block, nor get the output of another data
+				assert.Empty(t, outputs)
+				return
+			}
+			assert.Equal(t, []*frame.PayloadFrame{{Tag: 0x11, Carriage: []byte(strconv.Itoa(i * i))}}, outputs)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func Test_Runtime_RawByteHandler_SharedStream(t *testing.T) {
+	rt := NewRuntime(nil)
+	rt.Pipe(func(st Stream) Stream {
+		// the running total is kept from one data to another
+		return st.
+			Scan(func(_ context.Context, acc interface{}, i interface{}) (interface{}, error) {
+				n, err := strconv.Atoi(string(i.([]byte)))
+				if acc == nil {
+					return n, err
+				}
+				return acc.(int) + n, err
+			}).
+			Map(func(_ context.Context, i interface{}) (interface{}, error) {
+				return []byte(strconv.Itoa(i.(int))), nil
+			}).
+			PipeBackToProcessor(0x13)
+	})
+
+	for i, total := range []string{"1", "3", "6"} {
+		tag, resp := rt.RawByteHandler([]byte(strconv.Itoa(i + 1)))
+		assert.Equal(t, byte(0x13), tag)
+		assert.Equal(t, total, string(resp))
+	}
+}
+
+func Test_Runtime_RawByteHandler_Concurrent(t *testing.T) {
+	rt := NewRuntime(nil)
+	rt.Pipe(oddSquares)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tag, resp := rt.RawByteHandler([]byte(strconv.Itoa(i)))
+			if i%2 == 0 {
+				// the filtered out data doesn't block, nor get the output of another data
+				assert.Equal(t, byte(0x0), tag)
+				assert.Nil(t, resp)
+				return
+			}
+			assert.Equal(t, byte(0x11), tag)
+			assert.Equal(t, strconv.Itoa(i*i), string(resp))
+		}(i)
+	}
+	wg.Wait()
+}
+
+func Test_Runtime_RawByteHandler_Filter(t *testing.T) {
+	rt := NewRuntime(nil)
+	rt.Pipe(oddSquares)
+
+	tag, resp := rt.RawByteHandler([]byte("2"))
+	assert.Equal(t, byte(0x0), tag)
+	assert.Nil(t, resp)
+	tag, resp = rt.RawByteHandler([]byte("3"))
+	assert.Equal(t, byte(0x11), tag)
+	assert.Equal(t, "9", string(resp))
+}
+
+func Test_Runtime_IsolatedHandler_Outputs(t *testing.T) {
+	rt := NewRuntime(nil)
+	rt.Pipe(func(st Stream) Stream {
+		return st.
+			FlatMap(func(item reactive.Item) reactive.Observable {
+				return reactive.Just(string(item.V.([]byte)), string(item.V.([]byte))+"!")()
+			}).
+			Map(func(_ context.Context, i interface{}) (interface{}, error) {
+				return []byte(i.(string)), nil
+			}).
+			PipeBackToProcessor(0x12)
+	})
+
+	outputs, err := rt.IsolatedHandler(context.Background(), &engine.Message{Payload: []byte("hi")})
+	assert.NoError(t, err)
+	assert.Equal(t, []*frame.PayloadFrame{
+		{Tag: 0x12, Carriage: []byte("hi")},
+		{Tag: 0x12, Carriage: []byte("hi!")},
+	}, outputs)
+}
+
+func Test_Runtime_IsolatedHandler_NoPipe(t *testing.T) {
+	_, err := NewRuntime(nil).IsolatedHandler(context.Background(), &engine.Message{Payload: []byte("1")})
+	assert.Error(t, err)
+}
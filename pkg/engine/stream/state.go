@@ -0,0 +1,288 @@
+package stream
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bhojpur/service/pkg/reactive"
+	"github.com/bhojpur/service/pkg/state"
+)
+
+// StateFunc computes the new state of the key of item from its current state, and the output to
+// emit, nil output emits nothing.
+type StateFunc func(ctx context.Context, current interface{}, item interface{}) (newState interface{}, output interface{}, err error)
+
+// KeyedStateOption is a function that applies a KeyedState option.
+type KeyedStateOption func(ks *KeyedState)
+
+// WithCheckpointInterval checkpoints the state every interval, the default is 1 second.
+func WithCheckpointInterval(interval time.Duration) KeyedStateOption {
+	return func(ks *KeyedState) {
+		ks.interval = interval
+	}
+}
+
+// WithCheckpointEvery checkpoints the state every n items as well.
+func WithCheckpointEvery(n int) KeyedStateOption {
+	return func(ks *KeyedState) {
+		ks.every = n
+	}
+}
+
+// WithStateCodec sets how the state is saved in the store, the default is JSON.
+func WithStateCodec(marshaller Marshaller, unmarshaller Unmarshaller) KeyedStateOption {
+	return func(ks *KeyedState) {
+		ks.marshaller = marshaller
+		ks.unmarshaller = unmarshaller
+	}
+}
+
+// KeyedState keeps the state of an operator by key, the state is loaded from the store when a key
+// is seen first, and saved to the store by checkpoints, so a restarted stream function resumes
+// from the last checkpoint.
+type KeyedState struct {
+	store        state.Store
+	name         string
+	key          func(interface{}) string
+	factory      func() interface{}
+	interval     time.Duration
+	every        int
+	marshaller   Marshaller
+	unmarshaller Unmarshaller
+
+	mu      sync.Mutex
+	values  map[string]interface{}
+	dirty   map[string]bool
+	pending int
+}
+
+// NewKeyedState creates a KeyedState in store, the state of item is keyed by key, and created by
+// factory when it's not in the store. The name is the prefix of keys in the store, which must be
+// unique for each operator. The factory must return a pointer, e.g., &average{}, as the saved state
+// is unmarshalled into it. The state of a key is kept in memory until it's checkpointed, then it's
+// loaded from the store again when the key is seen next.
+func NewKeyedState(store state.Store, name string, key func(interface{}) string, factory func() interface{}, opts ...KeyedStateOption) *KeyedState {
+	ks := &KeyedState{
+		store:        store,
+		name:         name,
+		key:          key,
+		factory:      factory,
+		interval:     time.Second,
+		marshaller:   json.Marshal,
+		unmarshaller: json.Unmarshal,
+		values:       make(map[string]interface{}),
+		dirty:        make(map[string]bool),
+	}
+	for _, o := range opts {
+		o(ks)
+	}
+	return ks
+}
+
+func (ks *KeyedState) storeKey(key string) string {
+	return fmt.Sprintf("%s||%s", ks.name, key)
+}
+
+// Get returns the state of key.
+func (ks *KeyedState) Get(key string) (interface{}, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	return ks.get(key)
+}
+
+func (ks *KeyedState) get(key string) (interface{}, error) {
+	if v, ok := ks.values[key]; ok {
+		return v, nil
+	}
+	resp, err := ks.store.Get(&state.GetRequest{Key: ks.storeKey(key)})
+	if err != nil {
+		return nil, fmt.Errorf("get state %s: %w", key, err)
+	}
+	v := ks.factory()
+	if resp != nil && len(resp.Data) > 0 {
+		if err := ks.unmarshaller(resp.Data, v); err != nil {
+			return nil, fmt.Errorf("unmarshal state %s: %w", key, err)
+		}
+	}
+	ks.values[key] = v
+	return v, nil
+}
+
+// Set sets the state of key, which is saved by the next checkpoint.
+func (ks *KeyedState) Set(key string, value interface{}) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.values[key] = value
+	ks.dirty[key] = true
+}
+
+// Checkpoint saves the state changed since the last checkpoint to the store.
+func (ks *KeyedState) Checkpoint() error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	return ks.checkpoint()
+}
+
+func (ks *KeyedState) checkpoint() error {
+	ks.pending = 0
+	defer ks.evict()
+	if len(ks.dirty) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(ks.dirty))
+	for key := range ks.dirty {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	reqs := make([]state.SetRequest, 0, len(keys))
+	for _, key := range keys {
+		buf, err := ks.marshaller(ks.values[key])
+		if err != nil {
+			return fmt.Errorf("marshal state %s: %w", key, err)
+		}
+		reqs = append(reqs, state.SetRequest{Key: ks.storeKey(key), Value: buf})
+	}
+	if err := ks.store.BulkSet(reqs); err != nil {
+		// the state is kept dirty, and saved by the next checkpoint
+		return fmt.Errorf("checkpoint state: %w", err)
+	}
+	ks.dirty = make(map[string]bool)
+	return nil
+}
+
+// evict drops the state which is saved in the store, so the keys which are not seen anymore don't
+// stay in memory.
+func (ks *KeyedState) evict() {
+	for key := range ks.values {
+		if !ks.dirty[key] {
+			delete(ks.values, key)
+		}
+	}
+}
+
+// apply applies fn to the item with the state of its key, and checkpoints if it's due.
+func (ks *KeyedState) apply(ctx context.Context, fn StateFunc, item interface{}) (interface{}, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	key := ks.key(item)
+	current, err := ks.get(key)
+	if err != nil {
+		return nil, err
+	}
+	newState, output, err := fn(ctx, current, item)
+	if err != nil {
+		return nil, err
+	}
+	ks.values[key] = newState
+	ks.dirty[key] = true
+	ks.pending++
+	return output, nil
+}
+
+// due returns if a checkpoint is due by count.
+func (ks *KeyedState) due() bool {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	return ks.every > 0 && ks.pending >= ks.every
+}
+
+// ScanWithState applies a StateFunc to each item with the state of its key in keyed, and emits the outputs.
+// The state is checkpointed only between items, after the outputs of all of the previous items are taken by
+// the downstream, and when the Stream completes. Taken is not delivered: a checkpoint doesn't wait for
+// PipeBackToProcessor to write the outputs, so the outputs taken but not written before a crash are not
+// emitted again by the restarted stream function. The checkpoint interval is measured by the Scheduler of
+// reactive.WithScheduler in opts.
+// Cannot be run in parallel.
+func (s *StreamImpl) ScanWithState(keyed *KeyedState, apply StateFunc, opts ...reactive.Option) Stream {
+	if keyed == nil {
+		return s.thrown(errors.New("keyed state must not be nil"))
+	}
+
+	f := func(ctx context.Context, next chan reactive.Item) {
+		defer close(next)
+		observe := s.Observe()
+
+		scheduler := reactive.SchedulerOf(opts...)
+		var timer reactive.SchedulerTimer
+		var tick <-chan time.Time
+		if keyed.interval > 0 {
+			timer = scheduler.NewTimer(keyed.interval)
+			defer func() {
+				timer.Stop()
+			}()
+			tick = timer.C()
+		}
+		checkpoint := func() bool {
+			if err := keyed.Checkpoint(); err != nil {
+				return reactive.Error(err).SendContext(ctx, next)
+			}
+			return true
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+				ok := checkpoint()
+				// the next timer is created before the fired one is stopped, which acknowledges it to a
+				// reactive.TestScheduler
+				fired := timer
+				timer = scheduler.NewTimer(keyed.interval)
+				tick = timer.C()
+				fired.Stop()
+				if !ok {
+					return
+				}
+			case item, ok := <-observe:
+				if !ok {
+					checkpoint()
+					return
+				}
+				if item.Error() {
+					item.SendContext(ctx, next)
+					continue
+				}
+				output, err := keyed.apply(ctx, apply, item.V)
+				if err != nil {
+					if !reactive.Error(err).SendContext(ctx, next) {
+						return
+					}
+					continue
+				}
+				if output != nil && !Of(output).SendContext(ctx, next) {
+					return
+				}
+				if keyed.due() && !checkpoint() {
+					return
+				}
+			}
+		}
+	}
+	return CreateObservable(s.ctx, f, opts...)
+}
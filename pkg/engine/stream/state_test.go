@@ -0,0 +1,219 @@
+package stream
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/service/pkg/reactive"
+	"github.com/bhojpur/service/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryStore is a state.Store in memory.
+type memoryStore struct {
+	state.DefaultBulkStore
+	mu     sync.Mutex
+	items  map[string][]byte
+	sets   int
+	failed bool
+}
+
+func newMemoryStore() *memoryStore {
+	s := &memoryStore{items: make(map[string][]byte)}
+	s.DefaultBulkStore = state.NewDefaultBulkStore(s)
+	return s
+}
+
+func (s *memoryStore) Init(metadata state.Metadata) error { return nil }
+func (s *memoryStore) Features() []state.Feature          { return nil }
+func (s *memoryStore) Ping() error                        { return nil }
+
+func (s *memoryStore) Delete(req *state.DeleteRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, req.Key)
+	return nil
+}
+
+func (s *memoryStore) Get(req *state.GetRequest) (*state.GetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &state.GetResponse{Data: s.items[req.Key]}, nil
+}
+
+func (s *memoryStore) Set(req *state.SetRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failed {
+		return errors.New("store is down")
+	}
+	s.items[req.Key] = req.Value.([]byte)
+	s.sets++
+	return nil
+}
+
+type average struct {
+	Count int     `json:"count"`
+	Sum   float64 `json:"sum"`
+}
+
+type deviceReading struct {
+	device string
+	value  float64
+}
+
+func runningAverage(_ context.Context, current interface{}, item interface{}) (interface{}, interface{}, error) {
+	avg := current.(*average)
+	avg.Count++
+	avg.Sum += item.(deviceReading).value
+	return avg, avg.Sum / float64(avg.Count), nil
+}
+
+func newAverageState(store state.Store, opts ...KeyedStateOption) *KeyedState {
+	return NewKeyedState(store, "avg", func(i interface{}) string {
+		return i.(deviceReading).device
+	}, func() interface{} {
+		return &average{}
+	}, opts...)
+}
+
+func Test_ScanWithState(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newMemoryStore()
+
+	st := toStream(reactive.Just(deviceReading{"a", 1}, deviceReading{"b", 10}, deviceReading{"a", 3})()).
+		ScanWithState(newAverageState(store), runningAverage)
+	reactive.Assert(ctx, t, st, reactive.HasItems(1.0, 10.0, 2.0), reactive.HasNoError())
+	assert.Equal(t, `{"count":2,"sum":4}`, string(store.items["avg||a"]))
+	assert.Equal(t, `{"count":1,"sum":10}`, string(store.items["avg||b"]))
+
+	// the restarted stream resumes from the checkpoint
+	st = toStream(reactive.Just(deviceReading{"a", 8}, deviceReading{"c", 5})()).
+		ScanWithState(newAverageState(store), runningAverage)
+	reactive.Assert(ctx, t, st, reactive.HasItems(4.0, 5.0))
+}
+
+func Test_ScanWithState_CheckpointEvery(t *testing.T) {
+	store := newMemoryStore()
+	keyed := newAverageState(store, WithCheckpointEvery(2), WithCheckpointInterval(time.Hour))
+
+	ch := make(chan reactive.Item)
+	observe := toStream(reactive.FromChannel(ch)).ScanWithState(keyed, runningAverage).Observe()
+	ch <- reactive.Of(deviceReading{"a", 1})
+	assert.Equal(t, 1.0, (<-observe).V)
+	ch <- reactive.Of(deviceReading{"a", 3})
+	assert.Equal(t, 2.0, (<-observe).V)
+	ch <- reactive.Of(deviceReading{"a", 5})
+	assert.Equal(t, 3.0, (<-observe).V)
+
+	// the checkpoint is taken after the output of the 2nd item is taken
+	store.mu.Lock()
+	assert.Equal(t, `{"count":2,"sum":4}`, string(store.items["avg||a"]))
+	store.mu.Unlock()
+
+	close(ch)
+	for range observe {
+	}
+	assert.Equal(t, `{"count":3,"sum":9}`, string(store.items["avg||a"]))
+	assert.Equal(t, 2, store.sets)
+}
+
+func Test_ScanWithState_CheckpointInterval(t *testing.T) {
+	store := newMemoryStore()
+	keyed := newAverageState(store, WithCheckpointInterval(10*time.Second))
+	s := reactive.NewTestScheduler(time.Unix(0, 0))
+
+	ch := make(chan reactive.Item)
+	observe := toStream(reactive.FromChannel(ch)).ScanWithState(keyed, runningAverage, reactive.WithScheduler(s)).Observe()
+	ch <- reactive.Of(deviceReading{"a", 1})
+	assert.Equal(t, 1.0, (<-observe).V)
+	s.BlockUntil(1)
+	s.Advance(5 * time.Second)
+	store.mu.Lock()
+	assert.Empty(t, store.items)
+	store.mu.Unlock()
+
+	// the checkpoint is taken once the virtual time advances by the interval
+	s.Advance(5 * time.Second)
+	store.mu.Lock()
+	assert.Equal(t, `{"count":1,"sum":1}`, string(store.items["avg||a"]))
+	store.mu.Unlock()
+
+	close(ch)
+	for range observe {
+	}
+	assert.Equal(t, 1, store.sets)
+	assert.Equal(t, 0, s.Pending())
+}
+
+func Test_ScanWithState_Errors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newMemoryStore()
+	store.failed = true
+	keyed := newAverageState(store)
+
+	errInvalid := errors.New("invalid reading")
+	st := toStream(reactive.Just(deviceReading{"a", 1}, deviceReading{"a", -1})()).
+		ScanWithState(keyed, func(ctx context.Context, current interface{}, item interface{}) (interface{}, interface{}, error) {
+			if item.(deviceReading).value < 0 {
+				return nil, nil, errInvalid
+			}
+			return runningAverage(ctx, current, item)
+		})
+	reactive.Assert(ctx, t, st, reactive.HasItems(1.0), reactive.HasAnError())
+	assert.Empty(t, store.items)
+
+	// the failed checkpoint is retried
+	store.failed = false
+	assert.NoError(t, keyed.Checkpoint())
+	assert.Equal(t, `{"count":1,"sum":1}`, string(store.items["avg||a"]))
+}
+
+func Test_KeyedState_Evict(t *testing.T) {
+	store := newMemoryStore()
+	keyed := newAverageState(store)
+
+	_, err := keyed.apply(context.Background(), runningAverage, deviceReading{"a", 1})
+	assert.NoError(t, err)
+	_, err = keyed.Get("b")
+	assert.NoError(t, err)
+	assert.Len(t, keyed.values, 2)
+
+	// the dirty state is kept when the checkpoint fails
+	store.failed = true
+	assert.Error(t, keyed.Checkpoint())
+	assert.Len(t, keyed.values, 1)
+
+	// the checkpointed state is loaded from the store again
+	store.failed = false
+	assert.NoError(t, keyed.Checkpoint())
+	assert.Empty(t, keyed.values)
+	v, err := keyed.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, &average{Count: 1, Sum: 1}, v)
+}
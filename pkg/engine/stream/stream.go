@@ -24,7 +24,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/bhojpur/service/pkg/bindings"
+	"github.com/bhojpur/service/pkg/pubsub"
 	"github.com/bhojpur/service/pkg/reactive"
+	"github.com/bhojpur/service/pkg/state"
 	"github.com/cenkalti/backoff/v4"
 )
 
@@ -175,6 +178,11 @@ type Stream interface {
 	// The time is extracted using a timeExtractor function.
 	Join(joiner reactive.Func2, right reactive.Observable, timeExtractor func(interface{}) time.Time, windowInMS uint32, opts ...reactive.Option) Stream
 
+	// JoinByKey combines the items emitted by two Streams with the same key, whenever an item from one Stream is
+	// emitted within a time window of an item emitted by the other Stream. The items are kept only until the other
+	// Stream passes them by the window, the join type is set by reactive.WithJoinType.
+	JoinByKey(joiner reactive.Func2, right Stream, key func(interface{}) string, timeExtractor func(interface{}) time.Time, windowInMS uint32, opts ...reactive.Option) Stream
+
 	// Last returns a new Observable which emit only last item.
 	// Cannot be run in parallel.
 	Last(opts ...reactive.Option) Stream
@@ -190,6 +198,10 @@ type Stream interface {
 	// Marshal transforms the items emitted by an Observable by applying a marshalling to each item.
 	Marshal(marshaller Marshaller, opts ...reactive.Option) Stream
 
+	// MatchPattern matches the items emitted by an Observable against a reactive.Pattern by the key of items,
+	// and emits every match as reactive.PatternMatch. The event time of items is extracted by timeExtractor.
+	MatchPattern(pattern *reactive.Pattern, key func(interface{}) string, timeExtractor func(interface{}) time.Time, opts ...reactive.Option) Stream
+
 	// Max determines and emits the maximum-valued item emitted by an Observable according to a comparator.
 	Max(comparator reactive.Comparator, opts ...reactive.Option) Stream
 
@@ -207,6 +219,10 @@ type Stream interface {
 	// OnErrorReturnItem instructs on Observable to emit an item if it encounters an error.
 	OnErrorReturnItem(resume interface{}, opts ...reactive.Option) Stream
 
+	// RateLimit emits the items at the rate of a token bucket of count tokens, refilled by a token every perInMS/count,
+	// the items over the rate are dropped, queued or summarized by reactive.WithOverflowStrategy.
+	RateLimit(count int, perInMS uint32, opts ...reactive.Option) Stream
+
 	// Reduce applies a function to each item emitted by an Observable, sequentially, and emit the final value.
 	Reduce(apply reactive.Func2, opts ...reactive.Option) Stream
 
@@ -230,6 +246,11 @@ type Stream interface {
 	// Cannot be run in parallel.
 	Scan(apply reactive.Func2, opts ...reactive.Option) Stream
 
+	// ScanWithState applies a StateFunc to each item with the state of its key in keyed, and emits the outputs.
+	// The state is loaded from and checkpointed to the state.Store of keyed, so it survives the restart.
+	// Cannot be run in parallel.
+	ScanWithState(keyed *KeyedState, apply StateFunc, opts ...reactive.Option) Stream
+
 	// SequenceEqual emits true if an Observable and the input Observable emit the same items,
 	// in the same order, with the same termination state. Otherwise, it emits false.
 	SequenceEqual(iterable reactive.Iterable, opts ...reactive.Option) Stream
@@ -284,12 +305,24 @@ type Stream interface {
 	// Cannot be run in parallel.
 	TakeWhile(apply reactive.Predicate, opts ...reactive.Option) Stream
 
+	// ThrottleByKey emits at most count items by key in any window of perInMS, the items over the rate are dropped,
+	// queued or summarized by reactive.WithOverflowStrategy.
+	ThrottleByKey(key func(interface{}) string, count int, perInMS uint32, opts ...reactive.Option) Stream
+
+	// ThrottleFirst emits the first item in a window of windowInMS, which starts with the item, the items within the
+	// window are dropped, queued or summarized by reactive.WithOverflowStrategy.
+	ThrottleFirst(windowInMS uint32, opts ...reactive.Option) Stream
+
 	// TimeInterval converts an Observable that emits items into one that emits indications of the amount of time elapsed between those emissions.
 	TimeInterval(opts ...reactive.Option) Stream
 
 	// Timestamp attaches a timestamp to each item emitted by an Observable indicating when it was emitted.
 	Timestamp(opts ...reactive.Option) Stream
 
+	// ToBinding invokes operation of binding with each item emitted by an Observable, and emits the items.
+	// The failed invocations are retried, and an error is emitted when the retries are exhausted.
+	ToBinding(binding bindings.OutputBinding, operation bindings.OperationKind, opts ...ConnectorOption) Stream
+
 	// ToMap convert the sequence of items emitted by an Observable
 	// into a map keyed by a specified key function.
 	// Cannot be run in parallel.
@@ -301,10 +334,18 @@ type Stream interface {
 	// Cannot be run in parallel.
 	ToMapWithValueSelector(keySelector, valueSelector reactive.Func, opts ...reactive.Option) Stream
 
+	// ToPubSub publishes each item emitted by an Observable to topic, and emits the published items.
+	// The failed publications are retried, and an error is emitted when the retries are exhausted.
+	ToPubSub(ps pubsub.PubSub, topic string, opts ...ConnectorOption) Stream
+
 	// ToSlice collects all items from an Observable and emit them in a slice and an optional error.
 	// Cannot be run in parallel.
 	ToSlice(initialCapacity int, opts ...reactive.Option) ([]interface{}, error)
 
+	// ToStateStore saves each item emitted by an Observable to store by the key of item, and emits the saved items.
+	// The failed saves are retried, and an error is emitted when the retries are exhausted.
+	ToStateStore(store state.Store, key func(interface{}) string, opts ...ConnectorOption) Stream
+
 	// Unmarshal transforms the items emitted by an Observable by applying an unmarshalling to each item.
 	Unmarshal(unmarshaller Unmarshaller, factory func() interface{}, opts ...reactive.Option) Stream
 
@@ -312,6 +353,12 @@ type Stream interface {
 	// rather than emitting the items one at a time.
 	WindowWithCount(count int, opts ...reactive.Option) Stream
 
+	// WindowWithEventTime subdivides items from an Observable into the tumbling, sliding or session windows
+	// of their event time, which is extracted by timeExtractor, and emits every window as reactive.EventTimeWindow
+	// once the watermark passes its end. The watermark, allowed lateness, late items and key of windows are set by
+	// reactive.WithWatermarkDelay, reactive.WithAllowedLateness, reactive.WithLateItems and reactive.WithWindowKey.
+	WindowWithEventTime(timeExtractor func(interface{}) time.Time, assigner reactive.WindowAssigner, opts ...reactive.Option) Stream
+
 	// WindowWithTime periodically subdivides items from an Observable into Observables based on timed windows
 	// and emit them rather than emitting the items one at a time.
 	WindowWithTime(milliseconds uint32, opts ...reactive.Option) Stream
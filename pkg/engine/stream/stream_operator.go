@@ -274,6 +274,16 @@ func (s *StreamImpl) Join(joiner reactive.Func2, right reactive.Observable, time
 	return &StreamImpl{ctx: s.ctx, observable: reactive.FromChannel(s.observable.Join(joiner, right, timeExtractor, getRxDuration(windowInMS), opts...).Observe(), opts...)}
 }
 
+// JoinByKey combines the items emitted by two Streams with the same key, whenever an item from one Stream is
+// emitted within a time window of an item emitted by the other Stream.
+func (s *StreamImpl) JoinByKey(joiner reactive.Func2, right Stream, key func(interface{}) string, timeExtractor func(interface{}) time.Time, windowInMS uint32, opts ...reactive.Option) Stream {
+	if right == nil {
+		return s.thrown(errors.New("right stream must not be nil"))
+	}
+	opts = appendContinueOnError(s.ctx, opts...)
+	return &StreamImpl{ctx: s.ctx, observable: reactive.FromChannel(s.observable.JoinByKey(joiner, reactive.FromChannel(right.Observe()), key, timeExtractor, getRxDuration(windowInMS), opts...).Observe(), opts...)}
+}
+
 // GroupBy divides an Observable into a set of Observables that each emit a different group of items from the original Observable, organized by key.
 func (s *StreamImpl) GroupBy(length int, distribution func(reactive.Item) int, opts ...reactive.Option) Stream {
 	opts = appendContinueOnError(s.ctx, opts...)
@@ -330,6 +340,13 @@ func (s *StreamImpl) Unmarshal(unmarshaller Unmarshaller, factory func() interfa
 	}, opts...)
 }
 
+// MatchPattern matches the items emitted by an Observable against a reactive.Pattern by the key of items,
+// and emits every match as reactive.PatternMatch.
+func (s *StreamImpl) MatchPattern(pattern *reactive.Pattern, key func(interface{}) string, timeExtractor func(interface{}) time.Time, opts ...reactive.Option) Stream {
+	opts = appendContinueOnError(s.ctx, opts...)
+	return &StreamImpl{ctx: s.ctx, observable: reactive.FromChannel(s.observable.MatchPattern(pattern, key, timeExtractor, opts...).Observe(), opts...)}
+}
+
 // Max determines and emits the maximum-valued item emitted by an Observable according to a comparator.
 func (s *StreamImpl) Max(comparator reactive.Comparator, opts ...reactive.Option) Stream {
 	opts = appendContinueOnError(s.ctx, opts...)
@@ -362,6 +379,12 @@ func (s *StreamImpl) OnErrorReturnItem(resume interface{}, opts ...reactive.Opti
 	return &StreamImpl{ctx: s.ctx, observable: reactive.FromChannel(s.observable.OnErrorReturnItem(resume, opts...).Observe(), opts...)}
 }
 
+// RateLimit emits the items at the rate of a token bucket of count tokens, refilled by a token every perInMS/count.
+func (s *StreamImpl) RateLimit(count int, perInMS uint32, opts ...reactive.Option) Stream {
+	opts = appendContinueOnError(s.ctx, opts...)
+	return &StreamImpl{ctx: s.ctx, observable: reactive.FromChannel(s.observable.RateLimit(count, getRxDuration(perInMS), opts...).Observe(), opts...)}
+}
+
 // Reduce applies a function to each item emitted by an Observable, sequentially, and emit the final value.
 func (s *StreamImpl) Reduce(apply reactive.Func2, opts ...reactive.Option) Stream {
 	opts = appendContinueOnError(s.ctx, opts...)
@@ -499,6 +522,18 @@ func (s *StreamImpl) TakeWhile(apply reactive.Predicate, opts ...reactive.Option
 	return &StreamImpl{ctx: s.ctx, observable: reactive.FromChannel(s.observable.TakeWhile(apply, opts...).Observe(), opts...)}
 }
 
+// ThrottleByKey emits at most count items by key in any window of perInMS.
+func (s *StreamImpl) ThrottleByKey(key func(interface{}) string, count int, perInMS uint32, opts ...reactive.Option) Stream {
+	opts = appendContinueOnError(s.ctx, opts...)
+	return &StreamImpl{ctx: s.ctx, observable: reactive.FromChannel(s.observable.ThrottleByKey(key, count, getRxDuration(perInMS), opts...).Observe(), opts...)}
+}
+
+// ThrottleFirst emits the first item in a window of windowInMS, which starts with the item.
+func (s *StreamImpl) ThrottleFirst(windowInMS uint32, opts ...reactive.Option) Stream {
+	opts = appendContinueOnError(s.ctx, opts...)
+	return &StreamImpl{ctx: s.ctx, observable: reactive.FromChannel(s.observable.ThrottleFirst(getRxDuration(windowInMS), opts...).Observe(), opts...)}
+}
+
 // TimeInterval converts an Observable that emits items into one that emits indications of the amount of time elapsed between those emissions.
 func (s *StreamImpl) TimeInterval(opts ...reactive.Option) Stream {
 	opts = appendContinueOnError(s.ctx, opts...)
@@ -542,6 +577,13 @@ func (s *StreamImpl) WindowWithCount(count int, opts ...reactive.Option) Stream
 	return &StreamImpl{ctx: s.ctx, observable: reactive.FromChannel(s.observable.WindowWithCount(count, opts...).Observe(), opts...)}
 }
 
+// WindowWithEventTime subdivides items from an Observable into the windows of their event time, and emits
+// every window as reactive.EventTimeWindow once the watermark passes its end.
+func (s *StreamImpl) WindowWithEventTime(timeExtractor func(interface{}) time.Time, assigner reactive.WindowAssigner, opts ...reactive.Option) Stream {
+	opts = appendContinueOnError(s.ctx, opts...)
+	return &StreamImpl{ctx: s.ctx, observable: reactive.FromChannel(s.observable.WindowWithEventTime(timeExtractor, assigner, opts...).Observe(), opts...)}
+}
+
 // WindowWithTime periodically subdivides items from an Observable into Observables based on timed windows
 // and emit them rather than emitting the items one at a time.
 func (s *StreamImpl) WindowWithTime(milliseconds uint32, opts ...reactive.Option) Stream {
@@ -805,6 +847,10 @@ func (s *StreamImpl) PipeBackToProcessor(dataTag byte) Stream {
 				}
 
 				if item.Error() {
+					// the end of a data of RawByteHandler is passed on, the other errors are dropped
+					if isRequestEnd(item.E) && !item.SendContext(ctx, next) {
+						return
+					}
 					continue
 				}
 
@@ -857,6 +903,10 @@ func (s *StreamImpl) SlidingWindowWithCount(windowSize int, slideSize int, handl
 					return
 				}
 				if item.Error() {
+					// the end of a data of RawByteHandler is passed on, the other errors are dropped
+					if isRequestEnd(item.E) && !item.SendContext(ctx, next) {
+						return
+					}
 					continue
 				}
 
@@ -960,6 +1010,10 @@ func (s *StreamImpl) SlidingWindowWithTime(windowTimeInMS uint32, slideTimeInMS
 					return
 				}
 				if item.Error() {
+					// the end of a data of RawByteHandler is passed on, the other errors are dropped
+					if isRequestEnd(item.E) && !item.SendContext(ctx, next) {
+						return
+					}
 					continue
 				} else {
 					mutex.Lock()
@@ -23,6 +23,9 @@ package stream
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -223,3 +226,90 @@ func Test_ContinueOnError(t *testing.T) {
 		reactive.Assert(ctx, t, stream, reactive.HasItems(1, 3), reactive.HasError(errFoo))
 	})
 }
+
+func Test_JoinByKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the readings of noise and temperature are "device:second"
+	key := func(i interface{}) string {
+		return strings.Split(i.(string), ":")[0]
+	}
+	eventTime := func(i interface{}) time.Time {
+		second, _ := strconv.Atoi(strings.Split(i.(string), ":")[1])
+		return time.Unix(int64(second), 0)
+	}
+	joiner := func(_ context.Context, noise interface{}, temperature interface{}) (interface{}, error) {
+		return fmt.Sprintf("%v|%v", noise, temperature), nil
+	}
+	noise := toStream(reactive.Just("a:1", "b:2", "a:5")())
+	temperature := toStream(reactive.Just("a:2", "b:9")())
+	st := noise.JoinByKey(joiner, temperature, key, eventTime, 1000, reactive.WithJoinType(reactive.LeftJoin))
+	reactive.Assert(ctx, t, st, reactive.HasItemsNoOrder("a:1|a:2", "b:2|<nil>", "a:5|<nil>"))
+}
+
+func Test_MatchPattern(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventTime := func(i interface{}) time.Time {
+		return time.Unix(int64(i.(int)), 0)
+	}
+	even := func(i interface{}) bool {
+		return i.(int)%2 == 0
+	}
+	pattern := reactive.Begin("even", even).Times(2).Within(3 * time.Second)
+	st := toStream(reactive.Just(1, 2, 6, 8, 10)()).MatchPattern(pattern, nil, eventTime)
+	reactive.Assert(ctx, t, st, reactive.HasItems(
+		reactive.PatternMatch{Start: time.Unix(6, 0), End: time.Unix(8, 0), Events: map[string][]interface{}{"even": {6, 8}}},
+	))
+}
+
+func Test_OperatorMonitor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	monitor := reactive.NewMonitor()
+
+	st := ConvertObservable(reactive.ContextWithMonitor(ctx, monitor), reactive.Just(1, 2, 3)()).
+		Map(func(_ context.Context, i interface{}) (interface{}, error) {
+			return i.(int) * 10, nil
+		}).
+		Filter(func(i interface{}) bool {
+			return i.(int) > 10
+		})
+	reactive.Assert(ctx, t, st, reactive.HasItems(20, 30))
+
+	graph := monitor.Graph()
+	assert.Len(t, graph.Operators, 2)
+	assert.Equal(t, "Map", graph.Operators[0].Name)
+	assert.Equal(t, "Filter", graph.Operators[1].Name)
+	assert.Equal(t, []int{0}, graph.Operators[1].Inputs)
+	assert.Equal(t, int64(2), graph.Operators[1].ItemsOut)
+}
+
+func Test_ThrottleByKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	device := func(i interface{}) string {
+		return i.(deviceReading).device
+	}
+	st := toStream(reactive.Just(deviceReading{"a", 1}, deviceReading{"a", 2}, deviceReading{"b", 3})()).
+		ThrottleByKey(device, 1, 60000)
+	reactive.Assert(ctx, t, st, reactive.HasItems(deviceReading{"a", 1}, deviceReading{"b", 3}))
+}
+
+func Test_WindowWithEventTime(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventTime := func(i interface{}) time.Time {
+		return time.Unix(int64(i.(int)), 0)
+	}
+	st := toStream(reactive.Just(1, 3, 2, 6, 12)()).WindowWithEventTime(eventTime, reactive.TumblingWindow(5*time.Second), reactive.WithWatermarkDelay(time.Second))
+	reactive.Assert(ctx, t, st, reactive.HasItems(
+		reactive.EventTimeWindow{Start: time.Unix(0, 0), End: time.Unix(5, 0), Items: []interface{}{1, 2, 3}},
+		reactive.EventTimeWindow{Start: time.Unix(5, 0), End: time.Unix(10, 0), Items: []interface{}{6}},
+		reactive.EventTimeWindow{Start: time.Unix(10, 0), End: time.Unix(15, 0), Items: []interface{}{12}},
+	))
+}
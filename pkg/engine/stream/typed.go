@@ -0,0 +1,117 @@
+package stream
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhojpur/service/pkg/reactive"
+)
+
+// TypedStream is a Stream of the items of type T, so the functions of its operators don't need to assert
+// the type of items. It wraps a Stream: FromStream converts a Stream to a TypedStream, and Stream returns
+// the Stream of a TypedStream, with all of the untyped operators.
+type TypedStream[T any] struct {
+	stream Stream
+}
+
+// FromStream converts a Stream to a TypedStream, the items which are not of type T are converted to errors.
+func FromStream[T any](s Stream, opts ...reactive.Option) TypedStream[T] {
+	return typed[T](s.Map(func(_ context.Context, i interface{}) (interface{}, error) {
+		v, ok := i.(T)
+		if !ok && (i != nil || interface{}(v) != nil) {
+			return nil, fmt.Errorf("the item %v is %T, not %s", i, i, typeName[T]())
+		}
+		return v, nil
+	}, opts...))
+}
+
+// typed converts a Stream, whose items are known to be of type T, to a TypedStream.
+func typed[T any](s Stream) TypedStream[T] {
+	return TypedStream[T]{stream: s}
+}
+
+func typeName[T any]() string {
+	return fmt.Sprintf("%T", (*T)(nil))[1:]
+}
+
+// Stream returns the untyped Stream.
+func (s TypedStream[T]) Stream() Stream {
+	return s.stream
+}
+
+// Observe observes the items of the TypedStream, whose values are of type T.
+func (s TypedStream[T]) Observe(opts ...reactive.Option) <-chan reactive.Item {
+	return s.stream.Observe(opts...)
+}
+
+// Filter emits only those items from a TypedStream that pass a predicate test.
+func (s TypedStream[T]) Filter(apply func(item T) bool, opts ...reactive.Option) TypedStream[T] {
+	return typed[T](s.stream.Filter(func(i interface{}) bool {
+		v, _ := i.(T)
+		return apply(v)
+	}, opts...))
+}
+
+// ToSlice collects all items from a TypedStream and emit them in a slice and an optional error.
+func (s TypedStream[T]) ToSlice(initialCapacity int, opts ...reactive.Option) ([]T, error) {
+	items, err := s.stream.ToSlice(initialCapacity, opts...)
+	values := make([]T, 0, len(items))
+	for _, i := range items {
+		v, _ := i.(T)
+		values = append(values, v)
+	}
+	return values, err
+}
+
+// Map transforms the items emitted by a TypedStream by applying a function to each item.
+func Map[T, R any](s TypedStream[T], apply func(ctx context.Context, item T) (R, error), opts ...reactive.Option) TypedStream[R] {
+	return typed[R](s.stream.Map(func(ctx context.Context, i interface{}) (interface{}, error) {
+		v, _ := i.(T)
+		return apply(ctx, v)
+	}, opts...))
+}
+
+// Reduce applies a function to each item emitted by a TypedStream, sequentially, and emit the final value,
+// the first accumulator is the zero value of A.
+func Reduce[T, A any](s TypedStream[T], apply func(ctx context.Context, acc A, item T) (A, error), opts ...reactive.Option) TypedStream[A] {
+	return typed[A](s.stream.Reduce(func(ctx context.Context, acc interface{}, i interface{}) (interface{}, error) {
+		a, _ := acc.(A)
+		v, _ := i.(T)
+		return apply(ctx, a, v)
+	}, opts...))
+}
+
+// Unmarshal converts a Stream of []byte to a TypedStream by applying an unmarshalling to each item.
+func Unmarshal[T any](s Stream, unmarshaller Unmarshaller, opts ...reactive.Option) TypedStream[T] {
+	return typed[T](s.Map(func(_ context.Context, i interface{}) (interface{}, error) {
+		buf, ok := i.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("the item %v is %T, not []byte", i, i)
+		}
+		var v T
+		if err := unmarshaller(buf, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}, opts...))
+}
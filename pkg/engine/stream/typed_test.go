@@ -0,0 +1,77 @@
+package stream
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bhojpur/service/pkg/reactive"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TypedStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	raw := toStream(reactive.Just(`{"device":"a","value":1}`, `{"device":"b","value":12}`, `{"device":"a","value":5}`)()).
+		Map(func(_ context.Context, i interface{}) (interface{}, error) {
+			return []byte(i.(string)), nil
+		})
+	readings := Unmarshal[reading](raw, json.Unmarshal).
+		Filter(func(r reading) bool {
+			return r.Device == "a"
+		})
+	values := Map(readings, func(_ context.Context, r reading) (float64, error) {
+		return r.Value * 2, nil
+	})
+	sum := Reduce(values, func(_ context.Context, acc float64, v float64) (float64, error) {
+		return acc + v, nil
+	})
+
+	// the TypedStream interoperates with the untyped Stream
+	reactive.Assert(ctx, t, sum.Stream(), reactive.HasItems(12.0), reactive.HasNoError())
+}
+
+func Test_TypedStream_ToSlice(t *testing.T) {
+	squares := Map(FromStream[int](toStream(reactive.Just(1, 2, 3)())), func(_ context.Context, i int) (int, error) {
+		return i * i, nil
+	})
+	values, err := squares.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 4, 9}, values)
+}
+
+func Test_TypedStream_Errors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the items which are not int are errors, which are passed through by the typed operators
+	st := FromStream[int](toStream(reactive.Just(1, "2", 3)()))
+	doubled := Map(st, func(_ context.Context, i int) (int, error) {
+		return i * 2, nil
+	})
+	reactive.Assert(ctx, t, doubled.Stream(), reactive.HasItems(2, 6), reactive.HasAnError())
+
+	invalid := Unmarshal[reading](toStream(reactive.Just("not bytes")()), json.Unmarshal)
+	reactive.Assert(ctx, t, invalid.Stream(), reactive.IsEmpty(), reactive.HasAnError())
+}
@@ -48,32 +48,63 @@ type StreamFunction interface {
 	Write(tag byte, carriage []byte) error
 }
 
+// ContextStreamFunction is a StreamFunction whose handler sees the metadata of data, the
+// StreamFunction created by NewStreamFunction implements it, e.g.,
+// sfn.(ContextStreamFunction).SetContextHandler(fn).
+type ContextStreamFunction interface {
+	StreamFunction
+	// SetContextHandler set the handler function, which accept the data with its metadata and
+	// return zero or several outputs
+	SetContextHandler(fn engine.Handler) error
+}
+
+// MessagePipeStreamFunction is a StreamFunction whose pipe handler sees the metadata of data, the
+// StreamFunction created by NewStreamFunction implements it, e.g.,
+// sfn.(MessagePipeStreamFunction).SetMessagePipeHandler(fn).
+type MessagePipeStreamFunction interface {
+	StreamFunction
+	// SetMessagePipeHandler set the pipe handler function, which accept the data with its metadata
+	SetMessagePipeHandler(fn engine.MessagePipeHandler) error
+}
+
 // NewStreamFunction create a stream function.
 func NewStreamFunction(name string, opts ...Option) StreamFunction {
 	options := NewOptions(opts...)
 	client := engine.NewClient(name, engine.ClientTypeStreamFunction, options.ClientOptions...)
+	ctx, cancel := context.WithCancel(context.Background())
 	sfn := &streamFunction{
 		name:              name,
 		processorEndpoint: options.ProcessorAddr,
+		options:           options,
 		client:            client,
 		observeDataTags:   make([]byte, 0),
+		ctx:               ctx,
+		cancel:            cancel,
 	}
 
 	return sfn
 }
 
-var _ StreamFunction = &streamFunction{}
+var (
+	_ ContextStreamFunction     = &streamFunction{}
+	_ MessagePipeStreamFunction = &streamFunction{}
+	_ ErrorHandlerSetter        = &streamFunction{}
+)
 
 // streamFunction implements StreamFunction interface.
 type streamFunction struct {
 	name              string
 	processorEndpoint string
+	options           *Options
 	client            *engine.Client
-	observeDataTags   []byte              // tag list that will be observed
-	fn                engine.AsyncHandler // user's function which will be invoked when data arrived
-	pfn               engine.PipeHandler
-	pIn               chan []byte
+	observeDataTags   []byte         // tag list that will be observed
+	fn                engine.Handler // user's function which will be invoked when data arrived
+	pfn               engine.MessagePipeHandler
+	dispatcher        *dispatcher
+	pIn               chan *engine.Message
 	pOut              chan *frame.PayloadFrame
+	ctx               context.Context // the context of handlers, canceled on Close
+	cancel            context.CancelFunc
 }
 
 // SetObserveDataTags set the data tag list that will be observed.
@@ -85,12 +116,27 @@ func (s *streamFunction) SetObserveDataTags(tag ...byte) {
 
 // SetHandler set the handler function, which accept the raw bytes data and return the tag & response.
 func (s *streamFunction) SetHandler(fn engine.AsyncHandler) error {
+	return s.SetContextHandler(fn.Handler())
+}
+
+// SetContextHandler set the handler function, which accept the data with its metadata and return
+// zero or several outputs.
+func (s *streamFunction) SetContextHandler(fn engine.Handler) error {
 	s.fn = fn
+	if fn != nil && s.dispatcher == nil {
+		s.dispatcher = newDispatcher(s.options, s.handleDataFrame)
+	}
 	s.client.Logger().Debugf("%sSetHandler(%v)", streamFunctionLogPrefix, s.fn)
 	return nil
 }
 
 func (s *streamFunction) SetPipeHandler(fn engine.PipeHandler) error {
+	return s.SetMessagePipeHandler(fn.MessagePipeHandler())
+}
+
+// SetMessagePipeHandler set the pipe handler function, which accept the data with its metadata, e.g.,
+// the tag to tell the data of several observed tags apart.
+func (s *streamFunction) SetMessagePipeHandler(fn engine.MessagePipeHandler) error {
 	s.pfn = fn
 	s.client.Logger().Debugf("%sSetHandler(%v)", streamFunctionLogPrefix, s.fn)
 	return nil
@@ -103,11 +149,11 @@ func (s *streamFunction) Connect() error {
 	// notify underlying network operations, when data with tag we observed arrived, invoke the func
 	s.client.SetDataFrameObserver(func(data *frame.DataFrame) {
 		s.client.Logger().Debugf("%sreceive DataFrame, tag=%# x, carraige=%# x", streamFunctionLogPrefix, data.Tag(), data.GetCarriage())
-		s.onDataFrame(data.GetCarriage(), data.GetMetaFrame())
+		s.onDataFrame(data)
 	})
 
 	if s.pfn != nil {
-		s.pIn = make(chan []byte)
+		s.pIn = make(chan *engine.Message)
 		s.pOut = make(chan *frame.PayloadFrame)
 
 		// handle user's pipe function
@@ -137,8 +183,15 @@ func (s *streamFunction) Connect() error {
 	return err
 }
 
+// SetErrorHandler set the handler which is invoked when the connection is closed by an error.
+func (s *streamFunction) SetErrorHandler(fn func(err error)) {
+	s.client.SetErrorHandler(fn)
+}
+
 // Close will close the connection.
 func (s *streamFunction) Close() error {
+	s.cancel()
+
 	if s.pIn != nil {
 		close(s.pIn)
 	}
@@ -154,39 +207,55 @@ func (s *streamFunction) Close() error {
 		}
 	}
 
+	if s.dispatcher != nil {
+		s.dispatcher.close()
+	}
+
 	return nil
 }
 
 // when DataFrame we observed arrived, invoke the user's function
-func (s *streamFunction) onDataFrame(data []byte, metaFrame *frame.MetaFrame) {
+func (s *streamFunction) onDataFrame(data *frame.DataFrame) {
 	s.client.Logger().Infof("%sonDataFrame ->[%s]", streamFunctionLogPrefix, s.name)
 
 	if s.fn != nil {
-		go func() {
-			s.client.Logger().Debugf("%sexecute-start function: data[%d]=%# x", streamFunctionLogPrefix, len(data), frame.Shortly(data))
-			// invoke serverless
-			tag, resp := s.fn(data)
-			s.client.Logger().Debugf("%sexecute-done function: tag=%#x, resp[%d]=%# x", streamFunctionLogPrefix, tag, len(resp), frame.Shortly(resp))
-			// if resp is not nil, means the user's function has returned something, we should send it to the Processor
-			if len(resp) != 0 {
-				s.client.Logger().Debugf("%sstart WriteFrame(): tag=%#x, data[%d]=%# x", streamFunctionLogPrefix, tag, len(resp), frame.Shortly(resp))
-				// build a DataFrame
-				// TODO: seems we should implement a DeepCopy() of MetaFrame in the future
-				frame := frame.NewDataFrame()
-				// reuse transactionID
-				frame.SetTransactionID(metaFrame.TransactionID())
-				frame.SetCarriage(tag, resp)
-				s.client.WriteFrame(frame)
-			}
-		}()
+		s.dispatcher.dispatch(data)
 	} else if s.pfn != nil {
-		s.client.Logger().Debugf("%spipe function receive: data[%d]=%# x", streamFunctionLogPrefix, len(data), data)
-		s.pIn <- data
+		s.client.Logger().Debugf("%spipe function receive: data[%d]=%# x", streamFunctionLogPrefix, len(data.GetCarriage()), data.GetCarriage())
+		s.pIn <- engine.NewMessage(data)
 	} else {
 		s.client.Logger().Warnf("%sStreamFunction is nil", streamFunctionLogPrefix)
 	}
 }
 
+// handleDataFrame invokes the user's function and sends the outputs to the Processor.
+func (s *streamFunction) handleDataFrame(data *frame.DataFrame) {
+	msg := engine.NewMessage(data)
+	s.client.Logger().Debugf("%sexecute-start function: tid=%s, data[%d]=%# x", streamFunctionLogPrefix, msg.TransactionID, len(msg.Payload), frame.Shortly(msg.Payload))
+	// invoke serverless
+	outputs, err := s.fn(s.ctx, msg)
+	if err != nil {
+		s.client.Logger().Errorf("%sexecute function: tid=%s, err=%v", streamFunctionLogPrefix, msg.TransactionID, err)
+		return
+	}
+	s.client.Logger().Debugf("%sexecute-done function: tid=%s, outputs=%d", streamFunctionLogPrefix, msg.TransactionID, len(outputs))
+	// the outputs of user's function are sent to the Processor
+	for _, output := range outputs {
+		if output == nil {
+			continue
+		}
+		s.client.Logger().Debugf("%sstart WriteFrame(): tag=%#x, data[%d]=%# x", streamFunctionLogPrefix, output.Tag, len(output.Carriage), frame.Shortly(output.Carriage))
+		// build a DataFrame
+		frame := frame.NewDataFrame()
+		// reuse transactionID
+		frame.SetTransactionID(msg.TransactionID)
+		// the result keeps the priority of data
+		frame.SetPriority(msg.Priority)
+		frame.SetCarriage(output.Tag, output.Carriage)
+		s.client.WriteFrame(frame)
+	}
+}
+
 // Send a DataFrame to the Processor.
 func (s *streamFunction) Write(tag byte, carriage []byte) error {
 	frame := frame.NewDataFrame()
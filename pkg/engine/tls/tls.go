@@ -69,6 +69,30 @@ func CreateServerTLSConfig(host string) (*tls.Config, error) {
 	}, nil
 }
 
+// CreateServerTLSConfigFromFiles creates server tls config from the given certificate and key
+// files. The client certificates will be verified when caCertFile is not empty.
+func CreateServerTLSConfigFromFiles(certFile, keyFile, caCertFile string) (*tls.Config, error) {
+	tlsCert, err := loadCertAndKey(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tc := &tls.Config{
+		Certificates: []tls.Certificate{*tlsCert},
+		NextProtos:   []string{"bhojpur"},
+	}
+	if caCertFile != "" {
+		pool, err := loadCACertPool(caCertFile)
+		if err != nil {
+			return nil, err
+		}
+		tc.ClientCAs = pool
+		tc.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tc, nil
+}
+
 // CreateClientTLSConfig creates client TLS config.
 func CreateClientTLSConfig() (*tls.Config, error) {
 	// development mode
@@ -100,15 +124,16 @@ func CreateClientTLSConfig() (*tls.Config, error) {
 }
 
 func getCACertPool() (*x509.CertPool, error) {
-	var err error
-	var caCert []byte
-
 	caCertPath := os.Getenv("BHOJPUR_TLS_CACERT_FILE")
 	if len(caCertPath) == 0 {
 		return nil, errors.New("tls: must provide CA certificate on production mode, you can configure this via environment variables: `BHOJPUR_TLS_CACERT_FILE`")
 	}
 
-	caCert, err = ioutil.ReadFile(caCertPath)
+	return loadCACertPool(caCertPath)
+}
+
+func loadCACertPool(caCertPath string) (*x509.CertPool, error) {
+	caCert, err := ioutil.ReadFile(caCertPath)
 	if err != nil {
 		return nil, err
 	}
@@ -126,22 +151,23 @@ func getCACertPool() (*x509.CertPool, error) {
 }
 
 func getCertAndKey() (*tls.Certificate, error) {
-	var err error
-	var cert, key []byte
-
 	certPath := os.Getenv("BHOJPUR_TLS_CERT_FILE")
 	keyPath := os.Getenv("BHOJPUR_TLS_KEY_FILE")
 	if len(certPath) == 0 || len(keyPath) == 0 {
 		return nil, errors.New("tls: must provide certificate on production mode, you can configure this via environment variables: `BHOJPUR_TLS_CERT_FILE` and `BHOJPUR_TLS_KEY_FILE`")
 	}
 
+	return loadCertAndKey(certPath, keyPath)
+}
+
+func loadCertAndKey(certPath string, keyPath string) (*tls.Certificate, error) {
 	// certificate
-	cert, err = ioutil.ReadFile(certPath)
+	cert, err := ioutil.ReadFile(certPath)
 	if err != nil {
 		return nil, err
 	}
 	// private key
-	key, err = ioutil.ReadFile(keyPath)
+	key, err := ioutil.ReadFile(keyPath)
 	if err != nil {
 		return nil, err
 	}
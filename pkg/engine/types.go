@@ -19,3 +19,12 @@ package engine
 // LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
 // THE SOFTWARE.
+
+// ErrorHandlerSetter is implemented by the Source and the StreamFunction created by NewSource and
+// NewStreamFunction, e.g., sfn.(ErrorHandlerSetter).SetErrorHandler(fn).
+type ErrorHandlerSetter interface {
+	// SetErrorHandler set the handler which is invoked when the connection is closed by an error,
+	// the error is *core.Error, e.g., errors.Is(err, core.ErrRejected) when the authentication fails
+	// or the stream function is not defined in the workflow.
+	SetErrorHandler(fn func(err error))
+}
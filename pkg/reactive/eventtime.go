@@ -0,0 +1,288 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// WindowAssigner assigns the event-time windows of items.
+type WindowAssigner interface {
+	validate() error
+	// assign returns the windows of event time ts, the session windows are merged later.
+	assign(ts int64) []timeSpan
+	isSession() bool
+}
+
+type timeSpan struct {
+	start, end int64
+}
+
+type windowAssigner struct {
+	size, slide, gap int64
+}
+
+// TumblingWindow assigns every item to one fixed-size window, the windows don't overlap.
+func TumblingWindow(size time.Duration) WindowAssigner {
+	return &windowAssigner{size: int64(size), slide: int64(size)}
+}
+
+// SlidingWindow assigns every item to the fixed-size windows which start every slide.
+func SlidingWindow(size, slide time.Duration) WindowAssigner {
+	return &windowAssigner{size: int64(size), slide: int64(slide)}
+}
+
+// SessionWindow assigns the items to a window until there is no item for gap.
+func SessionWindow(gap time.Duration) WindowAssigner {
+	if gap <= 0 {
+		return &windowAssigner{gap: -1}
+	}
+	return &windowAssigner{gap: int64(gap)}
+}
+
+func (a *windowAssigner) validate() error {
+	if a.isSession() {
+		if a.gap < 0 {
+			return IllegalInputError{error: "session gap must be positive"}
+		}
+		return nil
+	}
+	if a.size <= 0 || a.slide <= 0 {
+		return IllegalInputError{error: "window size and slide must be positive"}
+	}
+	return nil
+}
+
+func (a *windowAssigner) isSession() bool {
+	return a.gap != 0
+}
+
+func (a *windowAssigner) assign(ts int64) []timeSpan {
+	if a.isSession() {
+		return []timeSpan{{start: ts, end: ts + a.gap}}
+	}
+	m := ts % a.slide
+	if m < 0 {
+		m += a.slide
+	}
+	// the windows in ascending order of start
+	first := ts - m
+	for first-a.slide > ts-a.size {
+		first -= a.slide
+	}
+	spans := make([]timeSpan, 0, (a.size+a.slide-1)/a.slide)
+	for start := first; start <= ts; start += a.slide {
+		spans = append(spans, timeSpan{start: start, end: start + a.size})
+	}
+	return spans
+}
+
+// EventTimeWindow is a window of items emitted by WindowWithEventTime.
+type EventTimeWindow struct {
+	// Key is the key of items set by WithWindowKey, empty if the windows are not keyed.
+	Key string
+	// Start is the inclusive start of window.
+	Start time.Time
+	// End is the exclusive end of window.
+	End time.Time
+	// Items are the items in the window, in the order of event time.
+	Items []interface{}
+	// Late is true when the window is emitted again with the late items.
+	Late bool
+}
+
+type eventTimeOptions struct {
+	watermarkDelay  time.Duration
+	allowedLateness time.Duration
+	late            chan<- Item
+	key             func(interface{}) string
+	idleTimeout     time.Duration
+}
+
+type timedItem struct {
+	ts int64
+	v  interface{}
+}
+
+type windowState struct {
+	key     string
+	span    timeSpan
+	items   []timedItem
+	fired   bool
+	emitted bool
+}
+
+// eventTimeWindows keeps the windows which the watermark has not passed yet.
+type eventTimeWindows struct {
+	assigner WindowAssigner
+	lateness int64
+	delay    int64
+	maxTs    int64
+	started  bool
+	windows  map[string][]*windowState
+}
+
+func newEventTimeWindows(assigner WindowAssigner, options eventTimeOptions) *eventTimeWindows {
+	return &eventTimeWindows{
+		assigner: assigner,
+		lateness: int64(options.allowedLateness),
+		delay:    int64(options.watermarkDelay),
+		windows:  make(map[string][]*windowState),
+	}
+}
+
+func (w *eventTimeWindows) watermark() int64 {
+	if !w.started {
+		return math.MinInt64
+	}
+	return w.maxTs - w.delay
+}
+
+// idle advances the watermark as if the max event time were ts, when the source is idle.
+func (w *eventTimeWindows) idle(ts int64) {
+	if w.started && ts > w.maxTs {
+		w.maxTs = ts
+	}
+}
+
+// pending returns if there are windows to fire or to purge.
+func (w *eventTimeWindows) pending() bool {
+	return len(w.windows) > 0
+}
+
+// expired returns if the window is beyond the allowed lateness.
+func (w *eventTimeWindows) expired(span timeSpan) bool {
+	return span.end+w.lateness <= w.watermark()
+}
+
+// add adds the item to its windows, it returns false if the item is too late for all of them.
+func (w *eventTimeWindows) add(key string, ts int64, v interface{}) bool {
+	item := timedItem{ts: ts, v: v}
+	accepted := false
+	if w.assigner.isSession() {
+		accepted = w.addToSession(key, w.assigner.assign(ts)[0], item)
+	} else {
+		for _, span := range w.assigner.assign(ts) {
+			if w.expired(span) {
+				continue
+			}
+			accepted = true
+			window := w.find(key, span)
+			window.items = append(window.items, item)
+			window.fired = false
+		}
+	}
+	if accepted && (!w.started || ts > w.maxTs) {
+		w.maxTs, w.started = ts, true
+	}
+	return accepted
+}
+
+func (w *eventTimeWindows) find(key string, span timeSpan) *windowState {
+	for _, window := range w.windows[key] {
+		if window.span == span {
+			return window
+		}
+	}
+	window := &windowState{key: key, span: span}
+	w.windows[key] = append(w.windows[key], window)
+	return window
+}
+
+// addToSession merges the session of item with the overlapped sessions of the key.
+func (w *eventTimeWindows) addToSession(key string, span timeSpan, item timedItem) bool {
+	merged := &windowState{key: key, span: span, items: []timedItem{item}}
+	var rest []*windowState
+	for _, window := range w.windows[key] {
+		if window.span.start < merged.span.end && merged.span.start < window.span.end {
+			if window.span.start < merged.span.start {
+				merged.span.start = window.span.start
+			}
+			if window.span.end > merged.span.end {
+				merged.span.end = window.span.end
+			}
+			merged.items = append(merged.items, window.items...)
+			merged.emitted = merged.emitted || window.emitted
+		} else {
+			rest = append(rest, window)
+		}
+	}
+	if w.expired(merged.span) {
+		return false
+	}
+	w.windows[key] = append(rest, merged)
+	return true
+}
+
+// advance fires the windows which the watermark has passed, all of the windows if flush is
+// true, and purges the windows beyond the allowed lateness.
+func (w *eventTimeWindows) advance(flush bool) []EventTimeWindow {
+	watermark := w.watermark()
+	var fired []*windowState
+	for key, windows := range w.windows {
+		live := windows[:0]
+		for _, window := range windows {
+			if !window.fired && (flush || window.span.end <= watermark) {
+				fired = append(fired, window)
+			}
+			if flush || !w.expired(window.span) {
+				live = append(live, window)
+			}
+		}
+		if len(live) == 0 {
+			delete(w.windows, key)
+		} else {
+			w.windows[key] = live
+		}
+	}
+
+	sort.Slice(fired, func(i, j int) bool {
+		a, b := fired[i], fired[j]
+		if a.span.end != b.span.end {
+			return a.span.end < b.span.end
+		}
+		if a.span.start != b.span.start {
+			return a.span.start < b.span.start
+		}
+		return a.key < b.key
+	})
+	results := make([]EventTimeWindow, 0, len(fired))
+	for _, window := range fired {
+		sort.SliceStable(window.items, func(i, j int) bool {
+			return window.items[i].ts < window.items[j].ts
+		})
+		items := make([]interface{}, len(window.items))
+		for i, item := range window.items {
+			items[i] = item.v
+		}
+		results = append(results, EventTimeWindow{
+			Key:   window.key,
+			Start: time.Unix(0, window.span.start),
+			End:   time.Unix(0, window.span.end),
+			Items: items,
+			Late:  window.emitted,
+		})
+		window.fired, window.emitted = true, true
+	}
+	return results
+}
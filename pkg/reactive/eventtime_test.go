@@ -0,0 +1,191 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+type reading struct {
+	device string
+	second int64
+}
+
+func readingTime(i interface{}) time.Time {
+	return time.Unix(i.(reading).second, 0)
+}
+
+func readingDevice(i interface{}) string {
+	return i.(reading).device
+}
+
+func window(key string, start, end int64, late bool, items ...interface{}) EventTimeWindow {
+	return EventTimeWindow{
+		Key:   key,
+		Start: time.Unix(start, 0),
+		End:   time.Unix(end, 0),
+		Items: items,
+		Late:  late,
+	}
+}
+
+func Test_Observable_WindowWithEventTime_Tumbling(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// out of order, 1 is delayed by 2 seconds
+	obs := testObservable(ctx, reading{"a", 0}, reading{"a", 2}, reading{"a", 1}, reading{"a", 4}, reading{"a", 6}, reading{"a", 13})
+	Assert(ctx, t, obs.WindowWithEventTime(readingTime, TumblingWindow(5*time.Second), WithWatermarkDelay(2*time.Second)),
+		HasItems(
+			window("", 0, 5, false, reading{"a", 0}, reading{"a", 1}, reading{"a", 2}, reading{"a", 4}),
+			window("", 5, 10, false, reading{"a", 6}),
+			window("", 10, 15, false, reading{"a", 13}),
+		),
+		HasNoError(),
+	)
+}
+
+func Test_Observable_WindowWithEventTime_Sliding(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	obs := testObservable(ctx, reading{"a", 1}, reading{"a", 3}, reading{"a", 5})
+	Assert(ctx, t, obs.WindowWithEventTime(readingTime, SlidingWindow(4*time.Second, 2*time.Second)),
+		HasItems(
+			window("", -2, 2, false, reading{"a", 1}),
+			window("", 0, 4, false, reading{"a", 1}, reading{"a", 3}),
+			window("", 2, 6, false, reading{"a", 3}, reading{"a", 5}),
+			window("", 4, 8, false, reading{"a", 5}),
+		),
+	)
+}
+
+func Test_Observable_WindowWithEventTime_Session(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	obs := testObservable(ctx,
+		reading{"a", 0}, reading{"b", 1}, reading{"a", 2},
+		reading{"b", 8}, reading{"a", 10}, reading{"a", 11},
+	)
+	Assert(ctx, t, obs.WindowWithEventTime(readingTime, SessionWindow(3*time.Second), WithWindowKey(readingDevice)),
+		HasItems(
+			window("b", 1, 4, false, reading{"b", 1}),
+			window("a", 0, 5, false, reading{"a", 0}, reading{"a", 2}),
+			window("b", 8, 11, false, reading{"b", 8}),
+			window("a", 10, 14, false, reading{"a", 10}, reading{"a", 11}),
+		),
+	)
+}
+
+func Test_Observable_WindowWithEventTime_SessionMerge(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 3 arrives late and bridges the sessions of 0 and 6
+	obs := testObservable(ctx, reading{"a", 0}, reading{"a", 6}, reading{"a", 3})
+	Assert(ctx, t, obs.WindowWithEventTime(readingTime, SessionWindow(4*time.Second), WithWatermarkDelay(5*time.Second)),
+		HasItems(
+			window("", 0, 10, false, reading{"a", 0}, reading{"a", 3}, reading{"a", 6}),
+		),
+	)
+}
+
+func Test_Observable_WindowWithEventTime_Lateness(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	late := make(chan Item, 10)
+	obs := testObservable(ctx, reading{"a", 1}, reading{"a", 6}, reading{"a", 2}, reading{"a", 12}, reading{"a", 3})
+	Assert(ctx, t, obs.WindowWithEventTime(readingTime, TumblingWindow(5*time.Second),
+		WithAllowedLateness(5*time.Second), WithLateItems(late)),
+		HasItems(
+			window("", 0, 5, false, reading{"a", 1}),
+			// 2 is within the allowed lateness
+			window("", 0, 5, true, reading{"a", 1}, reading{"a", 2}),
+			window("", 5, 10, false, reading{"a", 6}),
+			window("", 10, 15, false, reading{"a", 12}),
+		),
+	)
+	// 3 is too late
+	close(late)
+	var items []interface{}
+	for item := range late {
+		items = append(items, item.V)
+	}
+	assert.Equal(t, []interface{}{reading{"a", 3}}, items)
+}
+
+func Test_Observable_WindowWithEventTime_IdleTimeout(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+	ch := make(chan Item)
+
+	result := timeline(s, FromChannel(ch).WindowWithEventTime(readingTime, TumblingWindow(10*time.Second),
+		WithIdleTimeout(5*time.Second), WithScheduler(s), WithBufferedChannel(16)))
+	s.Send(ch, Of(reading{"a", 1}))
+	s.Send(ch, Of(reading{"a", 3}))
+	// the watermark is 8 after the source is idle for 5 seconds, then 13
+	s.Advance(5 * time.Second)
+	s.Advance(5 * time.Second)
+	s.Send(ch, Of(reading{"a", 14}))
+	s.Advance(time.Second)
+	close(ch)
+	emissions, completed := result()
+	assert.Equal(t, []emission{
+		{10 * time.Second, window("", 0, 10, false, reading{"a", 1}, reading{"a", 3})},
+		{11 * time.Second, window("", 10, 20, false, reading{"a", 14})},
+	}, emissions)
+	assert.Equal(t, 11*time.Second, completed)
+	assert.Equal(t, 0, s.Pending())
+}
+
+func Test_Observable_WindowWithEventTime_Error(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	obs := testObservable(ctx, reading{"a", 1}, errFoo, reading{"a", 6})
+	Assert(ctx, t, obs.WindowWithEventTime(readingTime, TumblingWindow(5*time.Second), WithErrorStrategy(ContinueOnError)),
+		HasItems(
+			window("", 0, 5, false, reading{"a", 1}),
+			window("", 5, 10, false, reading{"a", 6}),
+		),
+		HasError(errFoo),
+	)
+
+	Assert(ctx, t, Empty().WindowWithEventTime(readingTime, SessionWindow(0)),
+		HasAnError())
+	Assert(ctx, t, Empty().WindowWithEventTime(readingTime, SlidingWindow(time.Second, 0)),
+		HasAnError())
+	assert.True(t, errors.As(TumblingWindow(-1).validate(), &IllegalInputError{}))
+}
@@ -25,7 +25,6 @@ import (
 	"math"
 	"sync"
 	"sync/atomic"
-	"time"
 )
 
 // Amb takes several Observables, emit all of the items from only the first of these Observables
@@ -231,10 +230,12 @@ func Interval(interval Duration, opts ...Option) Observable {
 	ctx := option.buildContext(emptyContext)
 
 	go func() {
+		timer := newLoopTimer(option.getScheduler())
+		defer timer.stop()
 		i := 0
 		for {
 			select {
-			case <-time.After(interval.duration()):
+			case <-timer.after(interval.duration()):
 				if !Of(i).SendContext(ctx, next) {
 					return
 				}
@@ -369,11 +370,14 @@ func Timer(d Duration, opts ...Option) Observable {
 	ctx := option.buildContext(emptyContext)
 
 	go func() {
+		timer := option.getScheduler().NewTimer(d.duration())
+		// the timer is stopped once next is closed, so the completion is there once its fire is acknowledged
+		defer timer.Stop()
 		defer close(next)
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(d.duration()):
+		case <-timer.C():
 			return
 		}
 	}()
@@ -0,0 +1,145 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import "math"
+
+type joinEntry struct {
+	key     string
+	ts      int64
+	v       interface{}
+	matched bool
+}
+
+// joinSide keeps the items of one side of JoinByKey, until the other side passes them by window.
+type joinSide struct {
+	queue []*joinEntry
+	byKey map[string][]*joinEntry
+	max   int64
+	done  bool
+	// unmatched is true if the items without a match are emitted.
+	unmatched bool
+}
+
+func newJoinSide(unmatched bool) *joinSide {
+	return &joinSide{
+		byKey:     make(map[string][]*joinEntry),
+		max:       math.MinInt64,
+		unmatched: unmatched,
+	}
+}
+
+type joinPair struct {
+	left, right interface{}
+}
+
+// keyedJoin joins the items of two sides with the same key, whose event time is within window.
+type keyedJoin struct {
+	window      int64
+	left, right *joinSide
+}
+
+func newKeyedJoin(window int64, joinType JoinType) *keyedJoin {
+	return &keyedJoin{
+		window: window,
+		left:   newJoinSide(joinType == LeftJoin || joinType == OuterJoin),
+		right:  newJoinSide(joinType == OuterJoin),
+	}
+}
+
+// add joins the item of a side with the items of the other side, and returns the pairs, including the
+// items without a match which are evicted.
+func (j *keyedJoin) add(left bool, key string, ts int64, v interface{}) []joinPair {
+	side, other := j.right, j.left
+	if left {
+		side, other = j.left, j.right
+	}
+	if ts > side.max {
+		side.max = ts
+	}
+
+	entry := &joinEntry{key: key, ts: ts, v: v}
+	var pairs []joinPair
+	for _, e := range other.byKey[key] {
+		if abs(ts-e.ts) > j.window {
+			continue
+		}
+		e.matched, entry.matched = true, true
+		if left {
+			pairs = append(pairs, joinPair{left: v, right: e.v})
+		} else {
+			pairs = append(pairs, joinPair{left: e.v, right: v})
+		}
+	}
+	side.queue = append(side.queue, entry)
+	side.byKey[key] = append(side.byKey[key], entry)
+	return j.evict(pairs)
+}
+
+// complete marks a side completed, so the items of the other side can't be matched anymore.
+func (j *keyedJoin) complete(left bool) []joinPair {
+	if left {
+		j.left.done = true
+	} else {
+		j.right.done = true
+	}
+	return j.evict(nil)
+}
+
+func (j *keyedJoin) evict(pairs []joinPair) []joinPair {
+	for _, e := range j.left.evict(j.right, j.window) {
+		pairs = append(pairs, joinPair{left: e.v})
+	}
+	for _, e := range j.right.evict(j.left, j.window) {
+		pairs = append(pairs, joinPair{right: e.v})
+	}
+	return pairs
+}
+
+// evict drops the items which are passed by the other side, and returns the items without a match
+// if they are emitted.
+func (s *joinSide) evict(other *joinSide, window int64) []*joinEntry {
+	var unmatched []*joinEntry
+	n := 0
+	for _, e := range s.queue {
+		if !other.done && e.ts+window >= other.max {
+			break
+		}
+		n++
+		if s.unmatched && !e.matched {
+			unmatched = append(unmatched, e)
+		}
+		entries := s.byKey[e.key]
+		for i, k := range entries {
+			if k == e {
+				entries = append(entries[:i], entries[i+1:]...)
+				break
+			}
+		}
+		if len(entries) == 0 {
+			delete(s.byKey, e.key)
+		} else {
+			s.byKey[e.key] = entries
+		}
+	}
+	s.queue = s.queue[n:]
+	return unmatched
+}
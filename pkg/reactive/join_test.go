@@ -0,0 +1,126 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+type joined struct {
+	key    string
+	second int64
+	name   string
+}
+
+func joinedTime(i interface{}) time.Time {
+	return time.Unix(i.(joined).second, 0)
+}
+
+func joinedKey(i interface{}) string {
+	return i.(joined).key
+}
+
+func joinedNames(_ context.Context, left interface{}, right interface{}) (interface{}, error) {
+	name := func(i interface{}) string {
+		if i == nil {
+			return "_"
+		}
+		return i.(joined).name
+	}
+	return name(left) + "-" + name(right), nil
+}
+
+func joinByKeyTest(ctx context.Context, opts ...Option) Observable {
+	noise := testObservable(ctx,
+		joined{"a", 1, "n1"}, joined{"b", 2, "n2"}, joined{"a", 5, "n3"}, joined{"c", 9, "n4"},
+	)
+	temperature := testObservable(ctx,
+		joined{"a", 2, "t1"}, joined{"a", 6, "t2"}, joined{"b", 7, "t3"}, joined{"a", 7, "t4"},
+	)
+	return noise.JoinByKey(joinedNames, temperature, joinedKey, joinedTime, WithDuration(time.Second), opts...)
+}
+
+func Test_Observable_JoinByKey_Inner(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	Assert(ctx, t, joinByKeyTest(ctx), HasItemsNoOrder("n1-t1", "n3-t2"), HasNoError())
+}
+
+func Test_Observable_JoinByKey_Left(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	Assert(ctx, t, joinByKeyTest(ctx, WithJoinType(LeftJoin)),
+		HasItemsNoOrder("n1-t1", "n3-t2", "n2-_", "n4-_"))
+}
+
+func Test_Observable_JoinByKey_Outer(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	Assert(ctx, t, joinByKeyTest(ctx, WithJoinType(OuterJoin)),
+		HasItemsNoOrder("n1-t1", "n3-t2", "n2-_", "n4-_", "_-t3", "_-t4"))
+}
+
+func Test_Observable_JoinByKey_Eviction(t *testing.T) {
+	// the left items are evicted once the right items pass them by window
+	second := int64(time.Second)
+	join := newKeyedJoin(second, LeftJoin)
+	check := func(pairs []joinPair, expected ...joinPair) {
+		t.Helper()
+		if len(pairs) != len(expected) {
+			t.Fatalf("expected %v, got %v", expected, pairs)
+		}
+		for i := range pairs {
+			if pairs[i] != expected[i] {
+				t.Fatalf("expected %v, got %v", expected, pairs)
+			}
+		}
+	}
+	check(join.add(true, "a", 1*second, "l1"))
+	check(join.add(true, "a", 2*second, "l2"))
+	check(join.add(false, "a", 3*second, "r1"), joinPair{left: "l2", right: "r1"}, joinPair{left: "l1"})
+	check(join.add(false, "a", 10*second, "r2"))
+	check(join.complete(false))
+	check(join.add(true, "a", 11*second, "l3"), joinPair{left: "l3", right: "r2"})
+	check(join.complete(true))
+}
+
+func Test_Observable_JoinByKey_Error(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	obs := testObservable(ctx, joined{"a", 1, "n1"}, errFoo).
+		JoinByKey(joinedNames, testObservable(ctx, joined{"a", 1, "t1"}), joinedKey, joinedTime, WithDuration(time.Second),
+			WithErrorStrategy(ContinueOnError))
+	Assert(ctx, t, obs, HasItems("n1-t1"), HasError(errFoo))
+
+	Assert(ctx, t, Empty().JoinByKey(joinedNames, Empty(), nil, joinedTime, WithDuration(time.Second)), HasAnError())
+}
@@ -0,0 +1,459 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Monitor collects the metrics of the operators instrumented by WithMonitor, or created with the context
+// of ContextWithMonitor, and the graph they form, e.g., to find which operator of a stalled pipeline is
+// backed up.
+type Monitor struct {
+	mu        sync.Mutex
+	nextID    int
+	nodes     map[int]*operatorNode
+	producers map[<-chan Item]*operatorNode
+	// completed are the IDs of the operators which emitted all their items, the oldest first
+	completed    []int
+	maxCompleted int
+}
+
+// DefaultMaxCompletedOperators is the number of completed operators retained by a Monitor by default.
+const DefaultMaxCompletedOperators = 256
+
+// MonitorOption is an option of Monitor.
+type MonitorOption func(m *Monitor)
+
+// WithMaxCompletedOperators sets the number of completed operators retained in the graph, the older ones
+// are unregistered, so the operators created for each item, e.g., in FlatMap, don't grow the graph
+// without bound.
+func WithMaxCompletedOperators(n int) MonitorOption {
+	return func(m *Monitor) {
+		m.maxCompleted = n
+	}
+}
+
+// NewMonitor creates a Monitor.
+func NewMonitor(opts ...MonitorOption) *Monitor {
+	m := &Monitor{
+		nodes:        make(map[int]*operatorNode),
+		producers:    make(map[<-chan Item]*operatorNode),
+		maxCompleted: DefaultMaxCompletedOperators,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+type monitorKey struct{}
+
+// ContextWithMonitor returns a context which instruments the operators created with it by WithContext.
+func ContextWithMonitor(ctx context.Context, monitor *Monitor) context.Context {
+	return context.WithValue(ctx, monitorKey{}, monitor)
+}
+
+func monitorFromContext(ctx context.Context) *Monitor {
+	if ctx == nil {
+		return nil
+	}
+	monitor, _ := ctx.Value(monitorKey{}).(*Monitor)
+	return monitor
+}
+
+// OperatorStats is the snapshot of the metrics of an operator.
+type OperatorStats struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Inputs are the IDs of the instrumented operators which the operator observes.
+	Inputs    []int `json:"inputs,omitempty"`
+	ItemsIn   int64 `json:"itemsIn"`
+	ErrorsIn  int64 `json:"errorsIn"`
+	ItemsOut  int64 `json:"itemsOut"`
+	ErrorsOut int64 `json:"errorsOut"`
+	// QueueDepth is the number of items emitted by the operator, which are not taken by the downstream yet.
+	QueueDepth    int `json:"queueDepth"`
+	QueueCapacity int `json:"queueCapacity"`
+	// AvgLatency and MaxLatency are the time the operator takes to process an item, including the time it
+	// waits for the downstream to take its outputs. They are measured by the operators processing the items
+	// one by one, e.g., Map and Filter, but not by the time-based operators, e.g., Debounce.
+	AvgLatency time.Duration `json:"avgLatency"`
+	MaxLatency time.Duration `json:"maxLatency"`
+}
+
+// OperatorGraph is the snapshot of the operators of a Monitor.
+type OperatorGraph struct {
+	Operators []OperatorStats `json:"operators"`
+}
+
+// Graph returns the snapshot of the operators, the running ones and the last completed ones.
+func (m *Monitor) Graph() OperatorGraph {
+	m.mu.Lock()
+	nodes := make([]*operatorNode, 0, len(m.nodes))
+	for _, node := range m.nodes {
+		nodes = append(nodes, node)
+	}
+	m.mu.Unlock()
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+
+	ids := make(map[int]bool, len(nodes))
+	for _, node := range nodes {
+		ids[node.id] = true
+	}
+	graph := OperatorGraph{Operators: make([]OperatorStats, 0, len(nodes))}
+	for _, node := range nodes {
+		stats := node.stats()
+		// the inputs which are unregistered are not in the graph
+		inputs := stats.Inputs[:0]
+		for _, input := range stats.Inputs {
+			if ids[input] {
+				inputs = append(inputs, input)
+			}
+		}
+		if len(inputs) == 0 {
+			inputs = nil
+		}
+		stats.Inputs = inputs
+		graph.Operators = append(graph.Operators, stats)
+	}
+	return graph
+}
+
+// start registers the node again if it's unregistered, when it's observed after completing.
+func (m *Monitor) start(n *operatorNode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n.running++
+	if n.running > 1 {
+		return
+	}
+	m.nodes[n.id] = n
+	for i, id := range m.completed {
+		if id == n.id {
+			m.completed = append(m.completed[:i], m.completed[i+1:]...)
+			break
+		}
+	}
+}
+
+// complete retains the node when it emits all its items, and unregisters the oldest completed node
+// beyond maxCompleted.
+func (m *Monitor) complete(n *operatorNode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n.running--
+	if n.running > 0 {
+		return
+	}
+	m.completed = append(m.completed, n.id)
+	for len(m.completed) > m.maxCompleted {
+		delete(m.nodes, m.completed[0])
+		m.completed = m.completed[1:]
+	}
+}
+
+// JSON encodes the graph to JSON.
+func (g OperatorGraph) JSON() ([]byte, error) {
+	return json.Marshal(g)
+}
+
+// Dot encodes the graph to the DOT language of Graphviz, the operators whose queue is full are red.
+func (g OperatorGraph) Dot() string {
+	var sb strings.Builder
+	sb.WriteString("digraph operators {\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, op := range g.Operators {
+		label := fmt.Sprintf("%s #%d\\nin=%d (%d errors) out=%d (%d errors)\\nqueue=%d/%d latency=%s",
+			op.Name, op.ID, op.ItemsIn, op.ErrorsIn, op.ItemsOut, op.ErrorsOut, op.QueueDepth, op.QueueCapacity, op.AvgLatency)
+		color := ""
+		if op.QueueDepth > 0 && op.QueueDepth >= op.QueueCapacity {
+			color = ", color=red"
+		}
+		fmt.Fprintf(&sb, "  op%d [label=\"%s\"%s];\n", op.ID, label, color)
+	}
+	for _, op := range g.Operators {
+		for _, input := range op.Inputs {
+			fmt.Fprintf(&sb, "  op%d -> op%d;\n", input, op.ID)
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// operatorNode is an instrumented operator.
+type operatorNode struct {
+	monitor  *Monitor
+	id       int
+	name     string
+	capacity int
+
+	itemsIn, errorsIn, itemsOut, errorsOut int64
+	latencyTotal, latencyCount, latencyMax int64
+
+	mu     sync.Mutex
+	inputs map[int]bool
+	queue  chan Item
+	// running is the number of the observations emitting, guarded by the mutex of monitor
+	running int
+}
+
+// newOperatorNode registers the operator of opts to its Monitor, it returns nil if the operator is not monitored.
+func newOperatorNode(opts ...Option) *operatorNode {
+	option := parseOptions(opts...)
+	monitor := option.getMonitor()
+	if monitor == nil {
+		return nil
+	}
+	name := option.getOperatorName()
+	if name == "" {
+		name = operatorName()
+	}
+	capacity := 0
+	if ch := option.buildChannel(); ch != nil {
+		capacity = cap(ch)
+	}
+
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+	node := &operatorNode{
+		monitor:  monitor,
+		id:       monitor.nextID,
+		name:     name,
+		capacity: capacity,
+		inputs:   make(map[int]bool),
+	}
+	monitor.nextID++
+	monitor.nodes[node.id] = node
+	return node
+}
+
+var operatorMethod = regexp.MustCompile(`/reactive\.\(\*(?:Observable|Single|OptionalSingle)Impl\)\.(\w+)(?:\.func\d+)*$`)
+
+// operatorName returns the name of the outermost method of the operator being created, e.g., Marshal
+// rather than Map, which implements it.
+func operatorName() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	name := ""
+	for {
+		frame, more := frames.Next()
+		if m := operatorMethod.FindStringSubmatch(frame.Function); m != nil {
+			name = m[1]
+		} else if name != "" {
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	if name == "" {
+		return "Operator"
+	}
+	return name
+}
+
+// options returns the options of the operator being instrumented, which is not monitored again, observes
+// its upstream through the node, and emits to an unbuffered channel, as its buffer is moved to the output
+// of the node.
+func (n *operatorNode) options(opts ...Option) []Option {
+	options := make([]Option, 0, len(opts)+1)
+	options = append(options, opts...)
+	return append(options, &observerOption{
+		funcOption: newFuncOption(func(options *funcOption) {
+			options.unmonitored = true
+			options.isBuffer = false
+			options.buffer = 0
+		}),
+		node: n,
+	})
+}
+
+// operator measures the latency of the operators.
+func (n *operatorNode) operator(operatorFactory func() operator) func() operator {
+	return func() operator {
+		return &monitoredOperator{operator: operatorFactory(), node: n}
+	}
+}
+
+// output counts the items of iterable emitted by the operator.
+func (n *operatorNode) output(parent context.Context, iterable Iterable, opts ...Option) Iterable {
+	option := parseOptions(opts...)
+	if option.isEagerObservation() {
+		return newChannelIterable(n.emit(option.buildContext(parent), iterable.Observe(), option.buildChannel()))
+	}
+	return newFactoryIterable(func(propagatedOptions ...Option) <-chan Item {
+		option := parseOptions(append(opts, propagatedOptions...)...)
+		return n.emit(option.buildContext(parent), iterable.Observe(propagatedOptions...), option.buildChannel())
+	})
+}
+
+func (n *operatorNode) emit(ctx context.Context, observe <-chan Item, next chan Item) <-chan Item {
+	n.mu.Lock()
+	n.queue = next
+	n.mu.Unlock()
+
+	m := n.monitor
+	m.start(n)
+	m.mu.Lock()
+	m.producers[next] = n
+	m.mu.Unlock()
+
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			delete(m.producers, next)
+			m.mu.Unlock()
+			m.complete(n)
+			close(next)
+		}()
+		for item := range observe {
+			if item.Error() {
+				atomic.AddInt64(&n.errorsOut, 1)
+			} else {
+				atomic.AddInt64(&n.itemsOut, 1)
+			}
+			if !item.SendContext(ctx, next) {
+				return
+			}
+		}
+	}()
+	return next
+}
+
+// input counts the items of observe observed by the operator.
+func (n *operatorNode) input(observe <-chan Item, opts ...Option) <-chan Item {
+	m := n.monitor
+	m.mu.Lock()
+	producer, ok := m.producers[observe]
+	m.mu.Unlock()
+	if ok {
+		n.mu.Lock()
+		n.inputs[producer.id] = true
+		n.mu.Unlock()
+	}
+
+	ctx := parseOptions(opts...).buildContext(nil)
+	next := make(chan Item)
+	go func() {
+		defer close(next)
+		for item := range observe {
+			if item.Error() {
+				atomic.AddInt64(&n.errorsIn, 1)
+			} else {
+				atomic.AddInt64(&n.itemsIn, 1)
+			}
+			if !item.SendContext(ctx, next) {
+				return
+			}
+		}
+	}()
+	return next
+}
+
+func (n *operatorNode) observeLatency(latency time.Duration) {
+	atomic.AddInt64(&n.latencyTotal, int64(latency))
+	atomic.AddInt64(&n.latencyCount, 1)
+	for {
+		max := atomic.LoadInt64(&n.latencyMax)
+		if int64(latency) <= max || atomic.CompareAndSwapInt64(&n.latencyMax, max, int64(latency)) {
+			return
+		}
+	}
+}
+
+func (n *operatorNode) stats() OperatorStats {
+	stats := OperatorStats{
+		ID:            n.id,
+		Name:          n.name,
+		ItemsIn:       atomic.LoadInt64(&n.itemsIn),
+		ErrorsIn:      atomic.LoadInt64(&n.errorsIn),
+		ItemsOut:      atomic.LoadInt64(&n.itemsOut),
+		ErrorsOut:     atomic.LoadInt64(&n.errorsOut),
+		QueueCapacity: n.capacity,
+		MaxLatency:    time.Duration(atomic.LoadInt64(&n.latencyMax)),
+	}
+	if count := atomic.LoadInt64(&n.latencyCount); count > 0 {
+		stats.AvgLatency = time.Duration(atomic.LoadInt64(&n.latencyTotal) / count)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.queue != nil {
+		stats.QueueDepth = len(n.queue)
+	}
+	for input := range n.inputs {
+		stats.Inputs = append(stats.Inputs, input)
+	}
+	sort.Ints(stats.Inputs)
+	return stats
+}
+
+type monitoredOperator struct {
+	operator
+	node *operatorNode
+}
+
+func (op *monitoredOperator) next(ctx context.Context, item Item, dst chan<- Item, operatorOptions operatorOptions) {
+	start := time.Now()
+	op.operator.next(ctx, item, dst, operatorOptions)
+	op.node.observeLatency(time.Since(start))
+}
+
+func (op *monitoredOperator) err(ctx context.Context, item Item, dst chan<- Item, operatorOptions operatorOptions) {
+	start := time.Now()
+	op.operator.err(ctx, item, dst, operatorOptions)
+	op.node.observeLatency(time.Since(start))
+}
+
+// observerOption is the option of an instrumented operator, which tells an Iterable the operator observing it.
+type observerOption struct {
+	*funcOption
+	node *operatorNode
+}
+
+// observe observes iterable, and counts the items if it's observed by an instrumented operator. The
+// observer option is not propagated to the upstream.
+func observe(iterable Iterable, opts ...Option) <-chan Item {
+	var node *operatorNode
+	for i, opt := range opts {
+		if observer, ok := opt.(*observerOption); ok {
+			node = observer.node
+			options := make([]Option, 0, len(opts)-1)
+			options = append(options, opts[:i]...)
+			opts = append(options, opts[i+1:]...)
+			break
+		}
+	}
+	if node == nil {
+		return iterable.Observe(opts...)
+	}
+	return node.input(iterable.Observe(opts...), opts...)
+}
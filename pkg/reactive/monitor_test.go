@@ -0,0 +1,168 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func Test_Monitor_Graph(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	monitor := NewMonitor()
+
+	obs := Just(1, 2, 3, 4)().
+		Map(func(_ context.Context, i interface{}) (interface{}, error) {
+			time.Sleep(time.Millisecond)
+			return i.(int) * 2, nil
+		}, WithMonitor(monitor)).
+		Filter(func(i interface{}) bool {
+			return i.(int) > 4
+		}, WithMonitor(monitor), WithOperatorName("greaterThan4"))
+	Assert(ctx, t, obs, HasItems(6, 8), HasNoError())
+
+	graph := monitor.Graph()
+	require.Len(t, graph.Operators, 2)
+	mapStats, filterStats := graph.Operators[0], graph.Operators[1]
+	assert.Equal(t, "Map", mapStats.Name)
+	assert.Empty(t, mapStats.Inputs)
+	assert.Equal(t, int64(4), mapStats.ItemsIn)
+	assert.Equal(t, int64(4), mapStats.ItemsOut)
+	assert.GreaterOrEqual(t, mapStats.MaxLatency, time.Millisecond)
+	assert.GreaterOrEqual(t, mapStats.MaxLatency, mapStats.AvgLatency)
+	assert.Equal(t, "greaterThan4", filterStats.Name)
+	assert.Equal(t, []int{0}, filterStats.Inputs)
+	assert.Equal(t, int64(4), filterStats.ItemsIn)
+	assert.Equal(t, int64(2), filterStats.ItemsOut)
+}
+
+func Test_Monitor_MaxCompletedOperators(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	monitor := NewMonitor(WithMaxCompletedOperators(2))
+
+	// a running operator is retained, however many operators complete after it
+	ch := make(chan Item)
+	running := FromChannel(ch).Map(func(_ context.Context, i interface{}) (interface{}, error) {
+		return i, nil
+	}, WithMonitor(monitor), WithOperatorName("running"))
+	observe := running.Observe()
+
+	for i := 0; i < 10; i++ {
+		obs := Just(i)().Map(func(_ context.Context, i interface{}) (interface{}, error) {
+			return i, nil
+		}, WithMonitor(monitor))
+		Assert(ctx, t, obs, HasItems(i))
+	}
+
+	graph := monitor.Graph()
+	require.Len(t, graph.Operators, 3)
+	assert.Equal(t, "running", graph.Operators[0].Name)
+	assert.Equal(t, 9, graph.Operators[1].ID)
+	assert.Equal(t, 10, graph.Operators[2].ID)
+
+	close(ch)
+	for range observe {
+	}
+	graph = monitor.Graph()
+	// the running operator completes after the others, so the oldest completed one is unregistered
+	require.Len(t, graph.Operators, 2)
+	assert.Equal(t, "running", graph.Operators[0].Name)
+	assert.Equal(t, 10, graph.Operators[1].ID)
+}
+
+func Test_Monitor_Context(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	monitor := NewMonitor()
+	monitorCtx := ContextWithMonitor(ctx, monitor)
+
+	single := Just(1, 2, errFoo, 3)().
+		Marshal(json.Marshal, WithContext(monitorCtx), WithErrorStrategy(ContinueOnError)).
+		Count(WithContext(monitorCtx), WithErrorStrategy(ContinueOnError))
+	Assert(ctx, t, single, HasItem(int64(3)), HasNoError())
+
+	graph := monitor.Graph()
+	require.Len(t, graph.Operators, 2)
+	assert.Equal(t, "Marshal", graph.Operators[0].Name)
+	assert.Equal(t, int64(1), graph.Operators[0].ErrorsIn)
+	assert.Equal(t, int64(1), graph.Operators[0].ErrorsOut)
+	assert.Equal(t, "Count", graph.Operators[1].Name)
+	assert.Equal(t, []int{0}, graph.Operators[1].Inputs)
+	assert.Equal(t, int64(3), graph.Operators[1].ItemsIn)
+	assert.Equal(t, int64(1), graph.Operators[1].ErrorsIn)
+	assert.Equal(t, int64(1), graph.Operators[1].ItemsOut)
+}
+
+func Test_Monitor_QueueDepth(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	monitor := NewMonitor()
+	ch := make(chan Item)
+	observe := FromChannel(ch).Map(func(_ context.Context, i interface{}) (interface{}, error) {
+		return i, nil
+	}, WithMonitor(monitor), WithBufferedChannel(2)).Observe()
+
+	// the downstream doesn't take the items, so the queue fills up
+	for i := 0; i < 3; i++ {
+		ch <- Of(i)
+	}
+	assert.Eventually(t, func() bool {
+		return monitor.Graph().Operators[0].QueueDepth == 2
+	}, time.Second, time.Millisecond)
+	stats := monitor.Graph().Operators[0]
+	assert.Equal(t, 2, stats.QueueCapacity)
+	assert.Equal(t, int64(3), stats.ItemsIn)
+	assert.Contains(t, monitor.Graph().Dot(), "color=red")
+
+	close(ch)
+	for range observe {
+	}
+	assert.Equal(t, 0, monitor.Graph().Operators[0].QueueDepth)
+}
+
+func Test_OperatorGraph_Encoding(t *testing.T) {
+	graph := OperatorGraph{Operators: []OperatorStats{
+		{ID: 0, Name: "Map", ItemsIn: 2, ItemsOut: 2},
+		{ID: 1, Name: "Filter", Inputs: []int{0}, ItemsIn: 2, ItemsOut: 1, QueueCapacity: 1},
+	}}
+
+	buf, err := graph.JSON()
+	assert.NoError(t, err)
+	var decoded OperatorGraph
+	assert.NoError(t, json.Unmarshal(buf, &decoded))
+	assert.Equal(t, graph, decoded)
+
+	dot := graph.Dot()
+	assert.Contains(t, dot, "digraph operators {")
+	assert.Contains(t, dot, `op1 [label="Filter #1\nin=2 (0 errors) out=1 (0 errors)\nqueue=0/1 latency=0s"];`)
+	assert.Contains(t, dot, "op0 -> op1;")
+	assert.NotContains(t, dot, "color=red")
+}
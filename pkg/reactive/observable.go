@@ -70,15 +70,18 @@ type Observable interface {
 	GroupByDynamic(distribution func(Item) string, opts ...Option) Observable
 	IgnoreElements(opts ...Option) Observable
 	Join(joiner Func2, right Observable, timeExtractor func(interface{}) time.Time, window Duration, opts ...Option) Observable
+	JoinByKey(joiner Func2, right Observable, key func(interface{}) string, timeExtractor func(interface{}) time.Time, window Duration, opts ...Option) Observable
 	Last(opts ...Option) OptionalSingle
 	LastOrDefault(defaultValue interface{}, opts ...Option) Single
 	Map(apply Func, opts ...Option) Observable
 	Marshal(marshaller Marshaller, opts ...Option) Observable
+	MatchPattern(pattern *Pattern, key func(interface{}) string, timeExtractor func(interface{}) time.Time, opts ...Option) Observable
 	Max(comparator Comparator, opts ...Option) OptionalSingle
 	Min(comparator Comparator, opts ...Option) OptionalSingle
 	OnErrorResumeNext(resumeSequence ErrorToObservable, opts ...Option) Observable
 	OnErrorReturn(resumeFunc ErrorFunc, opts ...Option) Observable
 	OnErrorReturnItem(resume interface{}, opts ...Option) Observable
+	RateLimit(count int, per Duration, opts ...Option) Observable
 	Reduce(apply Func2, opts ...Option) OptionalSingle
 	Repeat(count int64, frequency Duration, opts ...Option) Observable
 	Retry(count int, shouldRetry func(error) bool, opts ...Option) Observable
@@ -99,6 +102,8 @@ type Observable interface {
 	TakeLast(nth uint, opts ...Option) Observable
 	TakeUntil(apply Predicate, opts ...Option) Observable
 	TakeWhile(apply Predicate, opts ...Option) Observable
+	ThrottleByKey(key func(interface{}) string, count int, per Duration, opts ...Option) Observable
+	ThrottleFirst(window Duration, opts ...Option) Observable
 	TimeInterval(opts ...Option) Observable
 	Timestamp(opts ...Option) Observable
 	ToMap(keySelector Func, opts ...Option) Single
@@ -106,6 +111,7 @@ type Observable interface {
 	ToSlice(initialCapacity int, opts ...Option) ([]interface{}, error)
 	Unmarshal(unmarshaller Unmarshaller, factory func() interface{}, opts ...Option) Observable
 	WindowWithCount(count int, opts ...Option) Observable
+	WindowWithEventTime(timeExtractor func(interface{}) time.Time, assigner WindowAssigner, opts ...Option) Observable
 	WindowWithTime(timespan Duration, opts ...Option) Observable
 	WindowWithTimeOrCount(timespan Duration, count int, opts ...Option) Observable
 	ProcessFromIterable(iterable Iterable, processor Func2, opts ...Option) Observable
@@ -123,6 +129,10 @@ func defaultErrorFuncOperator(ctx context.Context, item Item, dst chan<- Item, o
 }
 
 func customObservableOperator(parent context.Context, f func(ctx context.Context, next chan Item, option Option, opts ...Option), opts ...Option) Observable {
+	if node := newOperatorNode(opts...); node != nil {
+		return &ObservableImpl{iterable: node.output(parent, customObservableOperator(parent, f, node.options(opts...)...), opts...)}
+	}
+
 	option := parseOptions(opts...)
 	next := option.buildChannel()
 	ctx := option.buildContext(parent)
@@ -149,6 +159,10 @@ type operator interface {
 }
 
 func observable(parent context.Context, iterable Iterable, operatorFactory func() operator, forceSeq, bypassGather bool, opts ...Option) Observable {
+	if node := newOperatorNode(opts...); node != nil {
+		return &ObservableImpl{iterable: node.output(parent, observable(parent, iterable, node.operator(operatorFactory), forceSeq, bypassGather, node.options(opts...)...), opts...)}
+	}
+
 	option := parseOptions(opts...)
 	parallel, _ := option.getPool()
 
@@ -222,6 +236,10 @@ func observable(parent context.Context, iterable Iterable, operatorFactory func(
 }
 
 func single(parent context.Context, iterable Iterable, operatorFactory func() operator, forceSeq, bypassGather bool, opts ...Option) Single {
+	if node := newOperatorNode(opts...); node != nil {
+		return &SingleImpl{iterable: node.output(parent, single(parent, iterable, node.operator(operatorFactory), forceSeq, bypassGather, node.options(opts...)...), opts...)}
+	}
+
 	option := parseOptions(opts...)
 	parallel, _ := option.getPool()
 	next := option.buildChannel()
@@ -252,6 +270,11 @@ func single(parent context.Context, iterable Iterable, operatorFactory func() op
 }
 
 func optionalSingle(parent context.Context, iterable Iterable, operatorFactory func() operator, forceSeq, bypassGather bool, opts ...Option) OptionalSingle {
+	if node := newOperatorNode(opts...); node != nil {
+		inner := optionalSingle(parent, iterable, node.operator(operatorFactory), forceSeq, bypassGather, node.options(opts...)...).(*OptionalSingleImpl)
+		return &OptionalSingleImpl{parent: inner.parent, iterable: node.output(parent, inner, opts...)}
+	}
+
 	option := parseOptions(opts...)
 	ctx := option.buildContext(parent)
 	parallel, _ := option.getPool()
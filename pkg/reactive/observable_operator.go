@@ -489,6 +489,8 @@ func (o *ObservableImpl) BufferWithTime(timespan Duration, opts ...Option) Obser
 
 		go func() {
 			defer close(next)
+			timer := newLoopTimer(option.getScheduler())
+			defer timer.stop()
 			duration := timespan.duration()
 			for {
 				select {
@@ -497,7 +499,7 @@ func (o *ObservableImpl) BufferWithTime(timespan Duration, opts ...Option) Obser
 					return
 				case <-ctx.Done():
 					return
-				case <-time.After(duration):
+				case <-timer.after(duration):
 					checkBuffer()
 				}
 			}
@@ -524,6 +526,7 @@ func (o *ObservableImpl) BufferWithTime(timespan Duration, opts ...Option) Obser
 					buffer = append(buffer, item.V)
 					mutex.Unlock()
 				}
+				handled(option.getScheduler())
 			}
 		}
 	}
@@ -562,6 +565,8 @@ func (o *ObservableImpl) BufferWithTimeOrCount(timespan Duration, count int, opt
 
 		go func() {
 			defer close(next)
+			timer := newLoopTimer(option.getScheduler())
+			defer timer.stop()
 			duration := timespan.duration()
 			for {
 				select {
@@ -572,7 +577,7 @@ func (o *ObservableImpl) BufferWithTimeOrCount(timespan Duration, count int, opt
 					return
 				case <-ctx.Done():
 					return
-				case <-time.After(duration):
+				case <-timer.after(duration):
 					checkBuffer()
 				}
 			}
@@ -605,6 +610,7 @@ func (o *ObservableImpl) BufferWithTimeOrCount(timespan Duration, count int, opt
 						mutex.Unlock()
 					}
 				}
+				handled(option.getScheduler())
 			}
 		}
 	}
@@ -691,6 +697,8 @@ func (o *ObservableImpl) Debounce(timespan Duration, opts ...Option) Observable
 	f := func(ctx context.Context, next chan Item, option Option, opts ...Option) {
 		defer close(next)
 		observe := o.Observe(opts...)
+		timer := newLoopTimer(option.getScheduler())
+		defer timer.stop()
 		var latest interface{}
 
 		for {
@@ -711,7 +719,7 @@ func (o *ObservableImpl) Debounce(timespan Duration, opts ...Option) Observable
 				} else {
 					latest = item.V
 				}
-			case <-time.After(timespan.duration()):
+			case <-timer.after(timespan.duration()):
 				if latest != nil {
 					if !Of(latest).SendContext(ctx, next) {
 						return
@@ -1321,6 +1329,75 @@ func (o *ObservableImpl) Join(joiner Func2, right Observable, timeExtractor func
 	return customObservableOperator(o.parent, f, opts...)
 }
 
+// JoinByKey combines the items emitted by two Observables with the same key, whenever an item from one
+// Observable is emitted within window of an item emitted by the other Observable. The time is extracted
+// using a timeExtractor function, and the items of each Observable are expected in the order of time.
+// The items are kept only until the other Observable passes them by window, the items without a match
+// are emitted paired with nil by WithJoinType(LeftJoin) or WithJoinType(OuterJoin) once they are evicted.
+func (o *ObservableImpl) JoinByKey(joiner Func2, right Observable, key func(interface{}) string, timeExtractor func(interface{}) time.Time, window Duration, opts ...Option) Observable {
+	if joiner == nil || right == nil || key == nil || timeExtractor == nil || window == nil {
+		return Thrown(IllegalInputError{error: "joiner, right, key, timeExtractor and window must not be nil"})
+	}
+
+	f := func(ctx context.Context, next chan Item, option Option, opts ...Option) {
+		defer close(next)
+		join := newKeyedJoin(int64(window.duration()), option.getJoinType())
+		emit := func(pairs []joinPair) bool {
+			for _, pair := range pairs {
+				v, err := joiner(ctx, pair.left, pair.right)
+				if err != nil {
+					Error(err).SendContext(ctx, next)
+					if option.getErrorStrategy() == StopOnError {
+						return false
+					}
+					continue
+				}
+				if !Of(v).SendContext(ctx, next) {
+					return false
+				}
+			}
+			return true
+		}
+
+		lObserve := o.Observe(opts...)
+		rObserve := right.Observe(opts...)
+		for lObserve != nil || rObserve != nil {
+			var item Item
+			var ok, left bool
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok = <-lObserve:
+				left = true
+			case item, ok = <-rObserve:
+			}
+			if !ok {
+				if left {
+					lObserve = nil
+				} else {
+					rObserve = nil
+				}
+				if !emit(join.complete(left)) {
+					return
+				}
+				continue
+			}
+			if item.Error() {
+				item.SendContext(ctx, next)
+				if option.getErrorStrategy() == StopOnError {
+					return
+				}
+				continue
+			}
+			if !emit(join.add(left, key(item.V), timeExtractor(item.V).UnixNano(), item.V)) {
+				return
+			}
+		}
+	}
+
+	return customObservableOperator(o.parent, f, opts...)
+}
+
 // GroupBy divides an Observable into a set of Observables that each emit a different group of items from the original Observable, organized by key.
 func (o *ObservableImpl) GroupBy(length int, distribution func(Item) int, opts ...Option) Observable {
 	option := parseOptions(opts...)
@@ -1535,6 +1612,86 @@ func (o *ObservableImpl) Marshal(marshaller Marshaller, opts ...Option) Observab
 	}, opts...)
 }
 
+// MatchPattern matches the items emitted by an Observable against pattern, by the key of items, and emits
+// every match as PatternMatch. The event time of items is extracted by timeExtractor, the items of a key
+// are expected in the order of event time, and the max event time of items is the watermark which fails
+// the stages out of Within and confirms the trailing NotFollowedBy. While no item arrives, the watermark
+// advances with the time of the Scheduler, so a trailing NotFollowedBy is confirmed once its Within elapses.
+// The pending matches are confirmed when the Observable completes. A match skips past its last item, so the matches of a key don't overlap.
+func (o *ObservableImpl) MatchPattern(pattern *Pattern, key func(interface{}) string, timeExtractor func(interface{}) time.Time, opts ...Option) Observable {
+	if pattern == nil {
+		return Thrown(IllegalInputError{error: "pattern must not be nil"})
+	}
+	if timeExtractor == nil {
+		return Thrown(IllegalInputError{error: "timeExtractor must not be nil"})
+	}
+	stages, trailing, err := pattern.compile()
+	if err != nil {
+		return Thrown(err)
+	}
+
+	f := func(ctx context.Context, next chan Item, option Option, opts ...Option) {
+		defer close(next)
+		matcher := newPatternMatcher(stages, trailing)
+		emit := func(matches []PatternMatch) bool {
+			for _, match := range matches {
+				if !Of(match).SendContext(ctx, next) {
+					return false
+				}
+			}
+			return true
+		}
+
+		// the watermark advances by the time elapsed since the last item until the next waiting run is confirmed
+		scheduler := option.getScheduler()
+		timer := newLoopTimer(scheduler)
+		defer timer.stop()
+		lastArrival := scheduler.Now()
+		var lastTs int64
+
+		observe := o.Observe(opts...)
+		for {
+			var idle <-chan time.Time
+			if deadline, ok := matcher.deadline(); ok {
+				idle = timer.after(time.Duration(deadline-lastTs) - scheduler.Now().Sub(lastArrival))
+			} else {
+				timer.stop()
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-idle:
+				if !emit(matcher.idle(lastTs + int64(now.Sub(lastArrival)))) {
+					return
+				}
+			case item, ok := <-observe:
+				if !ok {
+					emit(matcher.advance(true))
+					return
+				}
+				if item.Error() {
+					item.SendContext(ctx, next)
+					if option.getErrorStrategy() == StopOnError {
+						return
+					}
+					continue
+				}
+				k := ""
+				if key != nil {
+					k = key(item.V)
+				}
+				matches := matcher.add(k, timeExtractor(item.V).UnixNano(), item.V)
+				lastArrival, lastTs = scheduler.Now(), matcher.watermark
+				if !emit(matches) {
+					return
+				}
+			}
+		}
+	}
+
+	return customObservableOperator(o.parent, f, opts...)
+}
+
 // Max determines and emits the maximum-valued item emitted by an Observable according to a comparator.
 func (o *ObservableImpl) Max(comparator Comparator, opts ...Option) OptionalSingle {
 	return optionalSingle(o.parent, o, func() operator {
@@ -1621,7 +1778,7 @@ func (op *minOperator) gatherNext(ctx context.Context, item Item, dst chan<- Ite
 
 // Observe observes an Observable by returning its channel.
 func (o *ObservableImpl) Observe(opts ...Option) <-chan Item {
-	return o.iterable.Observe(opts...)
+	return observe(o.iterable, opts...)
 }
 
 // OnErrorResumeNext instructs an Observable to pass control to another Observable rather than invoking
@@ -1701,6 +1858,21 @@ func (op *onErrorReturnItemOperator) end(_ context.Context, _ chan<- Item) {
 func (op *onErrorReturnItemOperator) gatherNext(_ context.Context, _ Item, _ chan<- Item, _ operatorOptions) {
 }
 
+// RateLimit emits the items emitted by an Observable at the rate of a token bucket of count tokens, which
+// is refilled by a token every per/count, so bursts of count items are allowed. The items over the rate
+// are dropped, queued or summarized by WithOverflowStrategy.
+func (o *ObservableImpl) RateLimit(count int, per Duration, opts ...Option) Observable {
+	if count <= 0 {
+		return Thrown(IllegalInputError{error: "count must be positive"})
+	}
+	if per == nil || per.duration() <= 0 {
+		return Thrown(IllegalInputError{error: "per must be positive"})
+	}
+	return o.throttle(nil, func() limiter {
+		return newTokenBucket(count, per.duration())
+	}, opts...)
+}
+
 // Reduce applies a function to each item emitted by an Observable, sequentially, and emit the final value.
 func (o *ObservableImpl) Reduce(apply Func2, opts ...Option) OptionalSingle {
 	return optionalSingle(o.parent, o, func() operator {
@@ -1755,10 +1927,12 @@ func (o *ObservableImpl) Repeat(count int64, frequency Duration, opts ...Option)
 		}
 	}
 
+	scheduler := parseOptions(opts...).getScheduler()
 	return observable(o.parent, o, func() operator {
 		return &repeatOperator{
 			count:     count,
 			frequency: frequency,
+			scheduler: scheduler,
 			seq:       make([]Item, 0),
 		}
 	}, true, false, opts...)
@@ -1767,6 +1941,7 @@ func (o *ObservableImpl) Repeat(count int64, frequency Duration, opts ...Option)
 type repeatOperator struct {
 	count     int64
 	frequency Duration
+	scheduler Scheduler
 	seq       []Item
 }
 
@@ -1780,6 +1955,8 @@ func (op *repeatOperator) err(ctx context.Context, item Item, dst chan<- Item, o
 }
 
 func (op *repeatOperator) end(ctx context.Context, dst chan<- Item) {
+	timer := newLoopTimer(op.scheduler)
+	defer timer.stop()
 	for {
 		select {
 		default:
@@ -1792,7 +1969,11 @@ func (op *repeatOperator) end(ctx context.Context, dst chan<- Item) {
 			}
 		}
 		if op.frequency != nil {
-			time.Sleep(op.frequency.duration())
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.after(op.frequency.duration()):
+			}
 		}
 		for _, v := range op.seq {
 			v.SendContext(ctx, dst)
@@ -2532,12 +2713,41 @@ func (op *takeWhileOperator) end(_ context.Context, _ chan<- Item) {
 func (op *takeWhileOperator) gatherNext(_ context.Context, _ Item, _ chan<- Item, _ operatorOptions) {
 }
 
+// ThrottleByKey emits at most count items by key in any window of per, e.g., count alerts per minute by
+// device. The items over the rate are dropped, queued or summarized by WithOverflowStrategy.
+func (o *ObservableImpl) ThrottleByKey(key func(interface{}) string, count int, per Duration, opts ...Option) Observable {
+	if key == nil {
+		return Thrown(IllegalInputError{error: "key must not be nil"})
+	}
+	if count <= 0 {
+		return Thrown(IllegalInputError{error: "count must be positive"})
+	}
+	if per == nil || per.duration() <= 0 {
+		return Thrown(IllegalInputError{error: "per must be positive"})
+	}
+	return o.throttle(key, func() limiter {
+		return newSlidingLog(count, per.duration())
+	}, opts...)
+}
+
+// ThrottleFirst emits the first item emitted by an Observable in a window, which starts with the item.
+// The items within the window are dropped, queued or summarized by WithOverflowStrategy.
+func (o *ObservableImpl) ThrottleFirst(window Duration, opts ...Option) Observable {
+	if window == nil || window.duration() <= 0 {
+		return Thrown(IllegalInputError{error: "window must be positive"})
+	}
+	return o.throttle(nil, func() limiter {
+		return newSlidingLog(1, window.duration())
+	}, opts...)
+}
+
 // TimeInterval converts an Observable that emits items into one that emits indications of the amount of time elapsed between those emissions.
 func (o *ObservableImpl) TimeInterval(opts ...Option) Observable {
 	f := func(ctx context.Context, next chan Item, option Option, opts ...Option) {
 		defer close(next)
 		observe := o.Observe(opts...)
-		latest := time.Now().UTC()
+		scheduler := option.getScheduler()
+		latest := scheduler.Now().UTC()
 
 		for {
 			select {
@@ -2555,7 +2765,7 @@ func (o *ObservableImpl) TimeInterval(opts ...Option) Observable {
 						return
 					}
 				} else {
-					now := time.Now().UTC()
+					now := scheduler.Now().UTC()
 					if !Of(now.Sub(latest)).SendContext(ctx, next) {
 						return
 					}
@@ -2570,17 +2780,19 @@ func (o *ObservableImpl) TimeInterval(opts ...Option) Observable {
 
 // Timestamp attaches a timestamp to each item emitted by an Observable indicating when it was emitted.
 func (o *ObservableImpl) Timestamp(opts ...Option) Observable {
+	scheduler := parseOptions(opts...).getScheduler()
 	return observable(o.parent, o, func() operator {
-		return &timestampOperator{}
+		return &timestampOperator{scheduler: scheduler}
 	}, true, false, opts...)
 }
 
 type timestampOperator struct {
+	scheduler Scheduler
 }
 
 func (op *timestampOperator) next(ctx context.Context, item Item, dst chan<- Item, operatorOptions operatorOptions) {
 	Of(TimestampItem{
-		Timestamp: time.Now().UTC(),
+		Timestamp: op.scheduler.Now().UTC(),
 		V:         item.V,
 	}).SendContext(ctx, dst)
 }
@@ -2790,6 +3002,94 @@ func (op *windowWithCountOperator) end(_ context.Context, _ chan<- Item) {
 func (op *windowWithCountOperator) gatherNext(_ context.Context, _ Item, _ chan<- Item, _ operatorOptions) {
 }
 
+// WindowWithEventTime subdivides items from an Observable into the windows of their event time,
+// which is extracted by timeExtractor, and emits every window as EventTimeWindow once the
+// watermark passes its end. The watermark is delayed by WithWatermarkDelay for the out-of-order
+// items, the windows are updated by the late items within WithAllowedLateness, and the items
+// later than that are dropped or sent to WithLateItems. The windows are divided by WithWindowKey.
+// With WithIdleTimeout, the watermark advances with the time of the Scheduler while the source is
+// idle. When the source Observable completes, all of the windows are emitted.
+// Cannot be run in parallel.
+func (o *ObservableImpl) WindowWithEventTime(timeExtractor func(interface{}) time.Time, assigner WindowAssigner, opts ...Option) Observable {
+	if timeExtractor == nil {
+		return Thrown(IllegalInputError{error: "timeExtractor must not be nil"})
+	}
+	if assigner == nil {
+		return Thrown(IllegalInputError{error: "assigner must not be nil"})
+	}
+	if err := assigner.validate(); err != nil {
+		return Thrown(err)
+	}
+
+	f := func(ctx context.Context, next chan Item, option Option, opts ...Option) {
+		defer close(next)
+		eventTime := option.getEventTimeOptions()
+		windows := newEventTimeWindows(assigner, eventTime)
+		emit := func(flush bool) bool {
+			for _, window := range windows.advance(flush) {
+				if !Of(window).SendContext(ctx, next) {
+					return false
+				}
+			}
+			return true
+		}
+
+		// the watermark advances by the time elapsed since the last item when the source is idle
+		scheduler := option.getScheduler()
+		timer := newLoopTimer(scheduler)
+		defer timer.stop()
+		lastArrival := scheduler.Now()
+		var lastTs int64
+
+		observe := o.Observe(opts...)
+		for {
+			var idle <-chan time.Time
+			if eventTime.idleTimeout > 0 && windows.pending() {
+				idle = timer.after(eventTime.idleTimeout)
+			} else {
+				timer.stop()
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-idle:
+				windows.idle(lastTs + int64(now.Sub(lastArrival)))
+				if !emit(false) {
+					return
+				}
+			case item, ok := <-observe:
+				if !ok {
+					emit(true)
+					return
+				}
+				if item.Error() {
+					item.SendContext(ctx, next)
+					if option.getErrorStrategy() == StopOnError {
+						return
+					}
+					continue
+				}
+				key := ""
+				if eventTime.key != nil {
+					key = eventTime.key(item.V)
+				}
+				if !windows.add(key, timeExtractor(item.V).UnixNano(), item.V) {
+					if eventTime.late != nil && !item.SendContext(ctx, eventTime.late) {
+						return
+					}
+					continue
+				}
+				lastArrival, lastTs = scheduler.Now(), windows.maxTs
+				if !emit(false) {
+					return
+				}
+			}
+		}
+	}
+
+	return customObservableOperator(o.parent, f, opts...)
+}
+
 // WindowWithTime periodically subdivides items from an Observable into Observables based on timed windows
 // and emit them rather than emitting the items one at a time.
 func (o *ObservableImpl) WindowWithTime(timespan Duration, opts ...Option) Observable {
@@ -2814,13 +3114,15 @@ func (o *ObservableImpl) WindowWithTime(timespan Duration, opts ...Option) Obser
 				mutex.Unlock()
 			}()
 			defer close(next)
+			timer := newLoopTimer(option.getScheduler())
+			defer timer.stop()
 			for {
 				select {
 				case <-ctx.Done():
 					return
 				case <-done:
 					return
-				case <-time.After(timespan.duration()):
+				case <-timer.after(timespan.duration()):
 					mutex.Lock()
 					if empty {
 						mutex.Unlock()
@@ -2869,6 +3171,7 @@ func (o *ObservableImpl) WindowWithTime(timespan Duration, opts ...Option) Obser
 				}
 				empty = false
 				mutex.Unlock()
+				handled(option.getScheduler())
 			}
 		}
 	}
@@ -2903,13 +3206,15 @@ func (o *ObservableImpl) WindowWithTimeOrCount(timespan Duration, count int, opt
 				mutex.Unlock()
 			}()
 			defer close(next)
+			timer := newLoopTimer(option.getScheduler())
+			defer timer.stop()
 			for {
 				select {
 				case <-ctx.Done():
 					return
 				case <-done:
 					return
-				case <-time.After(timespan.duration()):
+				case <-timer.after(timespan.duration()):
 					mutex.Lock()
 					if iCount == 0 {
 						mutex.Unlock()
@@ -2968,6 +3273,7 @@ func (o *ObservableImpl) WindowWithTimeOrCount(timespan Duration, count int, opt
 					}
 				}
 				mutex.Unlock()
+				handled(option.getScheduler())
 			}
 		}
 	}
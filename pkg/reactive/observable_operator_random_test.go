@@ -132,6 +132,8 @@ func TestLeak(t *testing.T) {
 					}))
 				})
 			}
+			factory := factory
+			action := action
 			t.Run(fmt.Sprintf("%s - %s - already cancelled", testObservable, testAction), func(t *testing.T) {
 				t.Parallel()
 				ctx, cancel := context.WithCancel(context.Background())
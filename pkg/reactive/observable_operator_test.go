@@ -322,6 +322,30 @@ func Test_Observable_BufferWithTime_Multiple(t *testing.T) {
 	}))
 }
 
+func Test_Observable_BufferWithTime_Timeline(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+	ch := make(chan Item)
+
+	result := timeline(s, FromChannel(ch).BufferWithTime(WithDuration(10*time.Millisecond), WithScheduler(s),
+		WithBufferedChannel(16)))
+	s.BlockUntil(1)
+	s.Send(ch, Of(1))
+	s.Send(ch, Of(2))
+	s.Advance(10 * time.Millisecond)
+	s.Send(ch, Of(3))
+	s.Advance(25 * time.Millisecond)
+	ch <- Of(4)
+	close(ch)
+	emissions, completed := result()
+	assert.Equal(t, []emission{
+		{10 * time.Millisecond, []interface{}{1, 2}},
+		{20 * time.Millisecond, []interface{}{3}},
+		{35 * time.Millisecond, []interface{}{4}},
+	}, emissions)
+	assert.Equal(t, 35*time.Millisecond, completed)
+}
+
 func Test_Observable_BufferWithTimeOrCount(t *testing.T) {
 	defer goleak.VerifyNone(t)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -401,13 +425,28 @@ func Test_Observable_Count_Parallel(t *testing.T) {
 		HasItem(int64(10000)))
 }
 
-// FIXME
-//func Test_Observable_Debounce(t *testing.T) {
-//	defer goleak.VerifyNone(t)
-//	ctx, obs, d := timeCausality(1, tick, 2, tick, 3, 4, 5, tick, 6, tick)
-//	Assert(ctx, t, obs.Debounce(d, WithBufferedChannel(10), WithContext(ctx)),
-//		HasItems(1, 2, 5, 6))
-//}
+func Test_Observable_Debounce(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+	ch := make(chan Item)
+
+	result := timeline(s, FromChannel(ch).Debounce(WithDuration(10*time.Millisecond), WithScheduler(s),
+		WithBufferedChannel(16)))
+	s.Send(ch, Of(1))
+	s.Advance(5 * time.Millisecond)
+	// 2 restarts the timespan
+	s.Send(ch, Of(2))
+	s.Advance(20 * time.Millisecond)
+	s.Send(ch, Of(3))
+	s.Send(ch, Of(4))
+	s.Advance(10 * time.Millisecond)
+	close(ch)
+	emissions, _ := result()
+	assert.Equal(t, []emission{
+		{15 * time.Millisecond, 2},
+		{35 * time.Millisecond, 4},
+	}, emissions)
+}
 
 func Test_Observable_Debounce_Error(t *testing.T) {
 	defer goleak.VerifyNone(t)
@@ -68,7 +68,7 @@ func (o *OptionalSingleImpl) Map(apply Func, opts ...Option) OptionalSingle {
 
 // Observe observes an OptionalSingle by returning its channel.
 func (o *OptionalSingleImpl) Observe(opts ...Option) <-chan Item {
-	return o.iterable.Observe(opts...)
+	return observe(o.iterable, opts...)
 }
 
 type mapOperatorOptionalSingle struct {
@@ -23,6 +23,7 @@ package reactive
 import (
 	"context"
 	"runtime"
+	"time"
 
 	"github.com/teivah/onecontext"
 )
@@ -42,6 +43,13 @@ type Option interface {
 	isConnectable() bool
 	isConnectOperation() bool
 	isSerialized() (bool, func(interface{}) int)
+	getEventTimeOptions() eventTimeOptions
+	getJoinType() JoinType
+	getOverflowStrategy() OverflowStrategy
+	getOverflowQueueSize() int
+	getScheduler() Scheduler
+	getMonitor() *Monitor
+	getOperatorName() string
 }
 
 type funcOption struct {
@@ -57,6 +65,14 @@ type funcOption struct {
 	connectable          bool
 	connectOperation     bool
 	serialized           func(interface{}) int
+	eventTime            eventTimeOptions
+	joinType             JoinType
+	overflowStrategy     OverflowStrategy
+	overflowQueueSize    int
+	scheduler            Scheduler
+	monitor              *Monitor
+	operatorName         string
+	unmonitored          bool
 }
 
 func (fdo *funcOption) toPropagate() bool {
@@ -120,6 +136,46 @@ func (fdo *funcOption) isSerialized() (bool, func(interface{}) int) {
 	return true, fdo.serialized
 }
 
+func (fdo *funcOption) getEventTimeOptions() eventTimeOptions {
+	return fdo.eventTime
+}
+
+func (fdo *funcOption) getJoinType() JoinType {
+	return fdo.joinType
+}
+
+func (fdo *funcOption) getOverflowStrategy() OverflowStrategy {
+	return fdo.overflowStrategy
+}
+
+func (fdo *funcOption) getOverflowQueueSize() int {
+	if fdo.overflowQueueSize <= 0 {
+		return DefaultOverflowQueueSize
+	}
+	return fdo.overflowQueueSize
+}
+
+func (fdo *funcOption) getScheduler() Scheduler {
+	if fdo.scheduler == nil {
+		return wallClock{}
+	}
+	return fdo.scheduler
+}
+
+func (fdo *funcOption) getMonitor() *Monitor {
+	if fdo.unmonitored {
+		return nil
+	}
+	if fdo.monitor != nil {
+		return fdo.monitor
+	}
+	return monitorFromContext(fdo.ctx)
+}
+
+func (fdo *funcOption) getOperatorName() string {
+	return fdo.operatorName
+}
+
 func newFuncOption(f func(*funcOption)) *funcOption {
 	return &funcOption{
 		f: f,
@@ -199,6 +255,99 @@ func Serialize(identifier func(interface{}) int) Option {
 	})
 }
 
+// WithWatermarkDelay sets how long the event-time operators wait for the out-of-order items,
+// the watermark is the max event time observed minus delay.
+func WithWatermarkDelay(delay time.Duration) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.eventTime.watermarkDelay = delay
+	})
+}
+
+// WithAllowedLateness keeps the event-time windows for lateness after the watermark passes
+// their end, the late items within lateness update the windows.
+func WithAllowedLateness(lateness time.Duration) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.eventTime.allowedLateness = lateness
+	})
+}
+
+// WithLateItems sends the items which are too late for the event-time windows to late
+// instead of dropping them.
+func WithLateItems(late chan<- Item) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.eventTime.late = late
+	})
+}
+
+// WithWindowKey divides the event-time windows by the key of items, e.g., the device ID.
+func WithWindowKey(key func(interface{}) string) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.eventTime.key = key
+	})
+}
+
+// WithIdleTimeout advances the watermark of the event-time operators with the processing time
+// when the source emits no item for timeout, e.g., on a quiet link, so the last windows fire
+// without waiting for the next item. The items older than the advanced watermark are late.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.eventTime.idleTimeout = timeout
+	})
+}
+
+// WithJoinType sets the type of JoinByKey, the default is InnerJoin.
+func WithJoinType(joinType JoinType) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.joinType = joinType
+	})
+}
+
+// WithOverflowStrategy sets what RateLimit, ThrottleFirst and ThrottleByKey do with the items over the rate,
+// the default is DropOverflow.
+func WithOverflowStrategy(strategy OverflowStrategy) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.overflowStrategy = strategy
+	})
+}
+
+// DefaultOverflowQueueSize is the size of the queue of a key by QueueOverflow, unless WithOverflowQueueSize sets it.
+const DefaultOverflowQueueSize = 1024
+
+// WithOverflowQueueSize sets the number of items of a key queued by QueueOverflow, the items beyond it
+// are summarized by a ThrottleSummary, emitted after the queue.
+func WithOverflowQueueSize(size int) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.overflowQueueSize = size
+	})
+}
+
+// WithScheduler sets the Scheduler of the time-based operators, e.g., a TestScheduler in tests.
+func WithScheduler(scheduler Scheduler) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.scheduler = scheduler
+	})
+}
+
+// SchedulerOf returns the Scheduler set by WithScheduler in opts, the wall-clock time by default, e.g., for the
+// time-based operators of other packages.
+func SchedulerOf(opts ...Option) Scheduler {
+	return parseOptions(opts...).getScheduler()
+}
+
+// WithMonitor instruments the operator, its metrics are collected by monitor.
+func WithMonitor(monitor *Monitor) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.monitor = monitor
+	})
+}
+
+// WithOperatorName sets the name of the operator in its Monitor, the default is the name of the method, e.g., Map.
+func WithOperatorName(name string) Option {
+	return newFuncOption(func(options *funcOption) {
+		options.operatorName = name
+	})
+}
+
 func connect() Option {
 	return newFuncOption(func(options *funcOption) {
 		options.connectOperation = true
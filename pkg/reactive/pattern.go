@@ -0,0 +1,459 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Pattern is a declarative pattern of events for MatchPattern, a sequence of stages which is
+// created by Begin, and continued by Next, FollowedBy and NotFollowedBy.
+//
+// The quantifiers (Times, TimesOrMore and Optional), Consecutive and Within apply to the last
+// stage of the pattern.
+type Pattern struct {
+	stages []*patternStage
+	err    error
+}
+
+type patternStage struct {
+	name   string
+	cond   Predicate
+	min    int
+	max    int // -1 if unbounded
+	strict bool
+	// consecutive requires the repeated events of the stage to be contiguous.
+	consecutive bool
+	negated     bool
+	within      int64
+	// guards are the negated stages between this stage and the next one.
+	guards []*patternStage
+}
+
+// Begin starts a Pattern with a stage named name, which matches an event if cond returns true.
+func Begin(name string, cond Predicate) *Pattern {
+	p := &Pattern{}
+	return p.add(&patternStage{name: name, cond: cond})
+}
+
+// Next appends a stage which must match the event right after the previous stage.
+func (p *Pattern) Next(name string, cond Predicate) *Pattern {
+	return p.add(&patternStage{name: name, cond: cond, strict: true})
+}
+
+// FollowedBy appends a stage which matches an event after the previous stage, the events in between
+// which don't match are skipped.
+func (p *Pattern) FollowedBy(name string, cond Predicate) *Pattern {
+	return p.add(&patternStage{name: name, cond: cond})
+}
+
+// NotFollowedBy appends a stage which fails the match if an event matches cond after the previous stage,
+// before the next stage or, with Within, before the duration elapses. A pattern which ends with
+// NotFollowedBy must set Within.
+func (p *Pattern) NotFollowedBy(name string, cond Predicate) *Pattern {
+	return p.add(&patternStage{name: name, cond: cond, negated: true})
+}
+
+// Times requires the last stage to match n events.
+func (p *Pattern) Times(n int) *Pattern {
+	return p.quantify(n, n)
+}
+
+// TimesOrMore requires the last stage to match n events at least, the stage takes the events greedily.
+func (p *Pattern) TimesOrMore(n int) *Pattern {
+	return p.quantify(n, -1)
+}
+
+// Optional makes the last stage optional.
+func (p *Pattern) Optional() *Pattern {
+	return p.quantify(0, p.last().max)
+}
+
+// Consecutive requires the repeated events of the last stage to be contiguous.
+func (p *Pattern) Consecutive() *Pattern {
+	p.last().consecutive = true
+	return p
+}
+
+// Within requires the last stage to complete within d since the previous stage, or since its first event
+// for the first stage. For NotFollowedBy, the events are checked within d since the previous stage.
+func (p *Pattern) Within(d time.Duration) *Pattern {
+	if d <= 0 {
+		p.fail(fmt.Sprintf("within of %s must be positive", p.last().name))
+	}
+	p.last().within = int64(d)
+	return p
+}
+
+func (p *Pattern) add(stage *patternStage) *Pattern {
+	if stage.cond == nil {
+		p.fail(fmt.Sprintf("condition of %s must not be nil", stage.name))
+	}
+	stage.min, stage.max = 1, 1
+	p.stages = append(p.stages, stage)
+	return p
+}
+
+func (p *Pattern) last() *patternStage {
+	return p.stages[len(p.stages)-1]
+}
+
+func (p *Pattern) quantify(min, max int) *Pattern {
+	stage := p.last()
+	switch {
+	case stage.negated:
+		p.fail(fmt.Sprintf("%s can't be quantified", stage.name))
+	case min < 0 || (max >= 0 && max < 1):
+		p.fail(fmt.Sprintf("times of %s must be positive", stage.name))
+	}
+	stage.min, stage.max = min, max
+	return p
+}
+
+func (p *Pattern) fail(err string) {
+	if p.err == nil {
+		p.err = IllegalInputError{error: err}
+	}
+}
+
+// compile returns the positive stages, with the negated stages between them as their guards, and the
+// trailing NotFollowedBy.
+func (p *Pattern) compile() ([]*patternStage, *patternStage, error) {
+	if p.err != nil {
+		return nil, nil, p.err
+	}
+	switch {
+	case p.stages[0].negated:
+		return nil, nil, IllegalInputError{error: "pattern can't begin with NotFollowedBy"}
+	case p.stages[0].min == 0:
+		return nil, nil, IllegalInputError{error: "first stage of pattern can't be optional"}
+	}
+	var stages []*patternStage
+	var trailing *patternStage
+	for i, stage := range p.stages {
+		switch {
+		case !stage.negated:
+			stage.guards = nil
+			stages = append(stages, stage)
+		case i == len(p.stages)-1:
+			trailing = stage
+		default:
+			last := stages[len(stages)-1]
+			last.guards = append(last.guards, stage)
+		}
+	}
+	if trailing != nil && trailing.within == 0 {
+		return nil, nil, IllegalInputError{error: "pattern ending with NotFollowedBy must set Within"}
+	}
+	return stages, trailing, nil
+}
+
+// PatternMatch is a match of Pattern emitted by MatchPattern.
+type PatternMatch struct {
+	// Key is the key of events, empty if the events are not keyed.
+	Key string
+	// Start is the event time of the first event.
+	Start time.Time
+	// End is the event time of the last event.
+	End time.Time
+	// Events are the matched events by the name of stage, in the order of arrival.
+	Events map[string][]interface{}
+}
+
+type patternRun struct {
+	stage      int
+	count      int
+	first      int64 // sequence number of the first event
+	last       int64 // sequence number of the last event
+	start      int64
+	stageStart int64
+	end        int64
+	waiting    bool
+	events     map[string][]interface{}
+}
+
+type stepResult int
+
+const (
+	runKept stepResult = iota
+	runKilled
+	runCompleted
+)
+
+// patternMatcher matches the events of a Pattern by key, a match skips past its last event, so the
+// matches of a key don't overlap.
+type patternMatcher struct {
+	stages    []*patternStage
+	trailing  *patternStage
+	runs      map[string][]*patternRun
+	seq       int64
+	watermark int64
+}
+
+func newPatternMatcher(stages []*patternStage, trailing *patternStage) *patternMatcher {
+	return &patternMatcher{
+		stages:    stages,
+		trailing:  trailing,
+		runs:      make(map[string][]*patternRun),
+		watermark: math.MinInt64,
+	}
+}
+
+// add matches the event, it returns the matches completed by the event, or confirmed by the watermark.
+func (m *patternMatcher) add(key string, ts int64, v interface{}) []PatternMatch {
+	if ts > m.watermark {
+		m.watermark = ts
+	}
+	matches := m.advance(false)
+	m.seq++
+
+	runs := m.runs[key]
+	if m.stages[0].cond(v) {
+		runs = append(runs, &patternRun{first: m.seq, start: ts, stageStart: ts, events: make(map[string][]interface{})})
+	}
+	live := runs[:0]
+	var completed *patternRun
+	for _, run := range runs {
+		switch m.step(run, ts, v) {
+		case runKept:
+			live = append(live, run)
+		case runCompleted:
+			if completed == nil {
+				completed = run
+			}
+		}
+	}
+	if completed != nil {
+		matches = append(matches, m.match(key, completed))
+		live = skipPast(live, completed)
+	}
+	m.set(key, live)
+	return matches
+}
+
+// step applies the event to the run.
+func (m *patternMatcher) step(run *patternRun, ts int64, v interface{}) stepResult {
+	if run.waiting {
+		if m.trailing.cond(v) || m.guarded(m.stages[len(m.stages)-1], run, ts, v) {
+			return runKilled
+		}
+		return runKept
+	}
+	if run.count == 0 && run.stage > 0 && m.guarded(m.stages[run.stage-1], run, ts, v) {
+		return runKilled
+	}
+	stage := m.stages[run.stage]
+	if (stage.max < 0 || run.count < stage.max) && stage.cond(v) && inTime(stage, ts, run.stageStart) {
+		return m.take(run, run.stage, ts, v)
+	}
+	if run.count < stage.min {
+		if stage.strict && run.count == 0 || stage.consecutive && run.count > 0 {
+			return runKilled
+		}
+		return runKept
+	}
+
+	// the stage is satisfied, the event is between it and the next stage
+	if m.guarded(stage, run, ts, v) {
+		return runKilled
+	}
+	for i := run.stage + 1; i < len(m.stages); i++ {
+		next := m.stages[i]
+		if next.cond(v) && inTime(next, ts, run.end) {
+			run.stageStart = run.end
+			return m.take(run, i, ts, v)
+		}
+		if next.strict {
+			// the run waits for the stage to take more events, if it can
+			if stage.consecutive || stage.max >= 0 && run.count >= stage.max {
+				return runKilled
+			}
+			break
+		}
+		if next.min > 0 {
+			break
+		}
+	}
+	return runKept
+}
+
+// guarded returns if the event fails the run by a NotFollowedBy after stage.
+func (m *patternMatcher) guarded(stage *patternStage, run *patternRun, ts int64, v interface{}) bool {
+	for _, guard := range stage.guards {
+		if guard.cond(v) && inTime(guard, ts, run.end) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *patternMatcher) take(run *patternRun, i int, ts int64, v interface{}) stepResult {
+	stage := m.stages[i]
+	if i != run.stage {
+		run.stage, run.count = i, 0
+	}
+	run.count++
+	run.last, run.end = m.seq, ts
+	run.events[stage.name] = append(run.events[stage.name], v)
+	if run.count == stage.max && i < len(m.stages)-1 {
+		run.stage, run.count, run.stageStart = i+1, 0, ts
+	}
+	if !m.satisfied(run) {
+		return runKept
+	}
+	if m.trailing != nil {
+		run.waiting = true
+		return runKept
+	}
+	return runCompleted
+}
+
+// satisfied returns if the run has matched all of the stages.
+func (m *patternMatcher) satisfied(run *patternRun) bool {
+	if run.count < m.stages[run.stage].min {
+		return false
+	}
+	for _, stage := range m.stages[run.stage+1:] {
+		if stage.min > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func inTime(stage *patternStage, ts, since int64) bool {
+	return stage.within == 0 || ts-since <= stage.within
+}
+
+// expired returns if the run can't complete anymore by the watermark.
+func (m *patternMatcher) expired(run *patternRun) bool {
+	stage := m.stages[run.stage]
+	if run.count < stage.min {
+		return stage.within > 0 && m.watermark-run.stageStart > stage.within
+	}
+	for _, next := range m.stages[run.stage+1:] {
+		if next.within == 0 || m.watermark-run.end <= next.within {
+			return false
+		}
+		if next.min > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// deadline returns the earliest watermark which confirms a waiting run, false if no run is waiting.
+func (m *patternMatcher) deadline() (int64, bool) {
+	var deadline int64
+	found := false
+	for _, runs := range m.runs {
+		for _, run := range runs {
+			if run.waiting && (!found || run.end+m.trailing.within+1 < deadline) {
+				deadline, found = run.end+m.trailing.within+1, true
+			}
+		}
+	}
+	return deadline, found
+}
+
+// idle advances the watermark to ts when no event arrives, it returns the matches confirmed by then.
+func (m *patternMatcher) idle(ts int64) []PatternMatch {
+	if ts > m.watermark {
+		m.watermark = ts
+	}
+	return m.advance(false)
+}
+
+// advance emits the waiting runs which the trailing NotFollowedBy has not failed until the watermark, or
+// all of them if flush is true, and purges the expired runs.
+func (m *patternMatcher) advance(flush bool) []PatternMatch {
+	type confirmed struct {
+		key string
+		run *patternRun
+	}
+	var done []confirmed
+	for key, runs := range m.runs {
+		live := runs[:0]
+		for _, run := range runs {
+			switch {
+			case run.waiting && (flush || m.watermark-run.end > m.trailing.within):
+				done = append(done, confirmed{key: key, run: run})
+			case run.waiting || !m.expired(run):
+				live = append(live, run)
+			}
+		}
+		m.set(key, live)
+	}
+
+	sort.Slice(done, func(i, j int) bool {
+		a, b := done[i].run, done[j].run
+		if a.end != b.end {
+			return a.end < b.end
+		}
+		return a.first < b.first
+	})
+	var matches []PatternMatch
+	skipped := make(map[*patternRun]bool)
+	for _, c := range done {
+		if skipped[c.run] {
+			continue
+		}
+		matches = append(matches, m.match(c.key, c.run))
+		for _, other := range done {
+			if other.key == c.key && other.run.first <= c.run.last {
+				skipped[other.run] = true
+			}
+		}
+		m.set(c.key, skipPast(m.runs[c.key], c.run))
+	}
+	return matches
+}
+
+func (m *patternMatcher) set(key string, runs []*patternRun) {
+	if len(runs) == 0 {
+		delete(m.runs, key)
+	} else {
+		m.runs[key] = runs
+	}
+}
+
+func (m *patternMatcher) match(key string, run *patternRun) PatternMatch {
+	return PatternMatch{
+		Key:    key,
+		Start:  time.Unix(0, run.start),
+		End:    time.Unix(0, run.end),
+		Events: run.events,
+	}
+}
+
+// skipPast drops the runs which start before the last event of the match.
+func skipPast(runs []*patternRun, match *patternRun) []*patternRun {
+	live := runs[:0]
+	for _, run := range runs {
+		if run.first > match.last {
+			live = append(live, run)
+		}
+	}
+	return live
+}
@@ -0,0 +1,223 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+type event struct {
+	key    string
+	second int64
+	kind   string
+}
+
+func eventTime(i interface{}) time.Time {
+	return time.Unix(i.(event).second, 0)
+}
+
+func eventKey(i interface{}) string {
+	return i.(event).key
+}
+
+func isKind(kind string) Predicate {
+	return func(i interface{}) bool {
+		return i.(event).kind == kind
+	}
+}
+
+func anyEvent(interface{}) bool {
+	return true
+}
+
+func match(key string, start, end int64, events map[string][]interface{}) PatternMatch {
+	return PatternMatch{
+		Key:    key,
+		Start:  time.Unix(start, 0),
+		End:    time.Unix(end, 0),
+		Events: events,
+	}
+}
+
+func Test_Observable_MatchPattern(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// three high readings of a device within 10 seconds, followed by no reading for 30 seconds
+	pattern := Begin("high", isKind("high")).Times(3).Within(10*time.Second).
+		NotFollowedBy("quiet", anyEvent).Within(30 * time.Second)
+	obs := testObservable(ctx,
+		event{"a", 0, "high"}, event{"b", 1, "high"}, event{"b", 2, "high"},
+		event{"a", 3, "high"}, event{"a", 5, "high"},
+		// b is too slow
+		event{"b", 15, "high"},
+		// c confirms a by the watermark
+		event{"c", 40, "low"},
+		// d is not quiet
+		event{"d", 41, "high"}, event{"d", 42, "high"}, event{"d", 43, "high"}, event{"d", 44, "low"},
+	)
+	Assert(ctx, t, obs.MatchPattern(pattern, eventKey, eventTime),
+		HasItems(
+			match("a", 0, 5, map[string][]interface{}{
+				"high": {event{"a", 0, "high"}, event{"a", 3, "high"}, event{"a", 5, "high"}},
+			}),
+		),
+		HasNoError(),
+	)
+}
+
+func Test_Observable_MatchPattern_Sequence(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	obs := testObservable(ctx,
+		event{"", 0, "a"}, event{"", 1, "x"}, event{"", 2, "b"},
+		event{"", 3, "a"}, event{"", 4, "b"},
+	)
+	Assert(ctx, t, obs.MatchPattern(Begin("a", isKind("a")).Next("b", isKind("b")), nil, eventTime),
+		HasItems(
+			match("", 3, 4, map[string][]interface{}{
+				"a": {event{"", 3, "a"}},
+				"b": {event{"", 4, "b"}},
+			}),
+		),
+	)
+}
+
+func Test_Observable_MatchPattern_FollowedBy(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pattern := Begin("a", isKind("a")).
+		FollowedBy("b", isKind("b")).Optional().
+		NotFollowedBy("x", isKind("x")).
+		FollowedBy("c", isKind("c")).Within(5 * time.Second)
+	obs := testObservable(ctx,
+		// b is optional
+		event{"", 0, "a"}, event{"", 1, "c"},
+		// x fails the match
+		event{"", 2, "a"}, event{"", 3, "b"}, event{"", 4, "x"}, event{"", 5, "c"},
+		event{"", 6, "a"}, event{"", 7, "b"}, event{"", 8, "y"}, event{"", 9, "c"},
+		// c is not within 5 seconds
+		event{"", 10, "a"}, event{"", 16, "c"},
+	)
+	Assert(ctx, t, obs.MatchPattern(pattern, nil, eventTime),
+		HasItems(
+			match("", 0, 1, map[string][]interface{}{
+				"a": {event{"", 0, "a"}},
+				"c": {event{"", 1, "c"}},
+			}),
+			match("", 6, 9, map[string][]interface{}{
+				"a": {event{"", 6, "a"}},
+				"b": {event{"", 7, "b"}},
+				"c": {event{"", 9, "c"}},
+			}),
+		),
+	)
+}
+
+func Test_Observable_MatchPattern_TimesOrMore(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pattern := Begin("a", isKind("a")).TimesOrMore(2).Consecutive().FollowedBy("b", isKind("b"))
+	obs := testObservable(ctx,
+		event{"", 0, "a"}, event{"", 1, "x"}, event{"", 2, "a"},
+		event{"", 3, "a"}, event{"", 4, "a"}, event{"", 5, "b"},
+	)
+	Assert(ctx, t, obs.MatchPattern(pattern, nil, eventTime),
+		HasItems(
+			match("", 2, 5, map[string][]interface{}{
+				"a": {event{"", 2, "a"}, event{"", 3, "a"}, event{"", 4, "a"}},
+				"b": {event{"", 5, "b"}},
+			}),
+		),
+	)
+}
+
+func Test_Observable_MatchPattern_Completion(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pattern := Begin("a", isKind("a")).NotFollowedBy("b", isKind("b")).Within(time.Minute)
+	obs := testObservable(ctx, event{"", 0, "a"}, errFoo, event{"", 1, "x"})
+	Assert(ctx, t, obs.MatchPattern(pattern, nil, eventTime, WithErrorStrategy(ContinueOnError)),
+		HasItems(
+			match("", 0, 0, map[string][]interface{}{
+				"a": {event{"", 0, "a"}},
+			}),
+		),
+		HasError(errFoo),
+	)
+}
+
+func Test_Observable_MatchPattern_Idle(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+	ch := make(chan Item)
+
+	pattern := Begin("a", isKind("a")).NotFollowedBy("b", isKind("b")).Within(30 * time.Second)
+	result := timeline(s, FromChannel(ch).MatchPattern(pattern, eventKey, eventTime, WithScheduler(s),
+		WithBufferedChannel(16)))
+	s.Send(ch, Of(event{"a", 0, "a"}))
+	s.Advance(10 * time.Second)
+	s.Send(ch, Of(event{"c", 10, "x"}))
+	// a is confirmed when the link stays quiet for 30 seconds, without waiting for the next event
+	s.Advance(time.Minute)
+	close(ch)
+	emissions, completed := result()
+	assert.Equal(t, []emission{
+		{30*time.Second + 1, match("a", 0, 0, map[string][]interface{}{
+			"a": {event{"a", 0, "a"}},
+		})},
+	}, emissions)
+	assert.Equal(t, 70*time.Second, completed)
+	assert.Equal(t, 0, s.Pending())
+}
+
+func Test_Observable_MatchPattern_IllegalPattern(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, pattern := range []*Pattern{
+		nil,
+		Begin("a", nil),
+		Begin("a", anyEvent).Optional(),
+		Begin("a", anyEvent).Times(0),
+		Begin("a", anyEvent).Within(0),
+		Begin("a", anyEvent).NotFollowedBy("b", anyEvent),
+		Begin("a", anyEvent).NotFollowedBy("b", anyEvent).Times(2).Within(time.Second),
+	} {
+		Assert(ctx, t, Empty().MatchPattern(pattern, nil, eventTime), HasAnError())
+	}
+	Assert(ctx, t, Empty().MatchPattern(Begin("a", anyEvent), nil, nil), HasAnError())
+}
@@ -0,0 +1,316 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Scheduler tells the time and creates the timers of the time-based operators, e.g., Debounce,
+// BufferWithTime, WindowWithTime, Repeat, Interval and Timer. The default is the wall-clock time,
+// a TestScheduler advances the virtual time in tests.
+type Scheduler interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer creates a timer which sends the time on its channel after d.
+	NewTimer(d time.Duration) SchedulerTimer
+}
+
+// SchedulerTimer is a timer created by a Scheduler.
+type SchedulerTimer interface {
+	// C returns the channel on which the time is sent.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it returns false if the timer has already fired or been stopped.
+	// The receiver of the time calls Stop once it has handled it, which tells a TestScheduler to go on.
+	Stop() bool
+}
+
+// handler is implemented by the schedulers which wait for the operators to handle their items, e.g.,
+// TestScheduler.
+type handler interface {
+	handled()
+}
+
+// handled tells scheduler that an operator has handled an item, and waits for the next one.
+func handled(scheduler Scheduler) {
+	if h, ok := scheduler.(handler); ok {
+		h.handled()
+	}
+}
+
+// wallClock is the Scheduler of the wall-clock time.
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+func (wallClock) NewTimer(d time.Duration) SchedulerTimer {
+	return &wallTimer{timer: time.NewTimer(d)}
+}
+
+type wallTimer struct {
+	timer *time.Timer
+}
+
+func (t *wallTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *wallTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// loopTimer restarts a timer on every iteration of a select loop, like time.After, and stops the
+// previous one, so the timers which are not waited for anymore don't fire. Every iteration but the first
+// one tells the scheduler that the operator has handled an item or a fired timer.
+type loopTimer struct {
+	scheduler Scheduler
+	timer     SchedulerTimer
+	started   bool
+}
+
+func newLoopTimer(scheduler Scheduler) *loopTimer {
+	return &loopTimer{scheduler: scheduler}
+}
+
+func (t *loopTimer) after(d time.Duration) <-chan time.Time {
+	// the next timer is created before the previous one is stopped, so it is pending once the fire of
+	// the previous one is acknowledged
+	previous := t.timer
+	t.timer = t.scheduler.NewTimer(d)
+	if previous != nil {
+		previous.Stop()
+	}
+	t.iterate()
+	return t.timer.C()
+}
+
+func (t *loopTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	t.iterate()
+}
+
+func (t *loopTimer) iterate() {
+	if t.started {
+		handled(t.scheduler)
+	}
+	t.started = true
+}
+
+// TestScheduler is a Scheduler of virtual time, which only advances by Advance and AdvanceTo, so the
+// tests of time-based operators are fast, and assert the exact time of emissions.
+//
+// The virtual time advances synchronously: a fired timer is acknowledged by the Stop of its receiver, once
+// it has handled the time, and Send and Close wait for the operator to handle the item or the completion,
+// so the emissions sent on a buffered channel meanwhile are there once they return.
+type TestScheduler struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	seq     int
+	timers  []*testTimer
+	handles int
+	steps   []func()
+}
+
+type testTimer struct {
+	scheduler *TestScheduler
+	at        time.Time
+	seq       int
+	c         chan time.Time
+	fired     bool
+	acked     bool
+}
+
+// NewTestScheduler creates a TestScheduler starting at start.
+func NewTestScheduler(start time.Time) *TestScheduler {
+	s := &TestScheduler{now: start}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Now returns the virtual time.
+func (s *TestScheduler) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// NewTimer creates a timer which fires when the virtual time advances by d.
+func (s *TestScheduler) NewTimer(d time.Duration) SchedulerTimer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	t := &testTimer{scheduler: s, at: s.now.Add(d), seq: s.seq, c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- s.now
+	} else {
+		s.timers = append(s.timers, t)
+	}
+	s.cond.Broadcast()
+	return t
+}
+
+// Pending returns the number of timers which have not fired or been stopped.
+func (s *TestScheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.timers)
+}
+
+// BlockUntil blocks until n timers at least are pending, e.g., until an operator waits for its timer.
+func (s *TestScheduler) BlockUntil(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.timers) < n {
+		s.cond.Wait()
+	}
+}
+
+// Send sends item to ch, and blocks until the operator receiving from ch has handled it.
+func (s *TestScheduler) Send(ch chan<- Item, item Item) {
+	s.await(func() {
+		ch <- item
+	})
+}
+
+// Close closes ch, and blocks until the operator receiving from ch has handled the completion.
+func (s *TestScheduler) Close(ch chan<- Item) {
+	s.await(func() {
+		close(ch)
+	})
+}
+
+// await calls f, and blocks until an operator has handled an item.
+func (s *TestScheduler) await(f func()) {
+	s.mu.Lock()
+	handles := s.handles
+	s.mu.Unlock()
+	f()
+	s.mu.Lock()
+	for s.handles == handles {
+		s.cond.Wait()
+	}
+	s.mu.Unlock()
+	s.step()
+}
+
+func (s *TestScheduler) handled() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handles++
+	s.cond.Broadcast()
+}
+
+// onStep registers f, which is called every time the virtual time advances, or an item is handled.
+func (s *TestScheduler) onStep(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = append(s.steps, f)
+}
+
+func (s *TestScheduler) step() {
+	s.mu.Lock()
+	steps := s.steps
+	s.mu.Unlock()
+	for _, f := range steps {
+		f()
+	}
+}
+
+// Advance advances the virtual time by d.
+func (s *TestScheduler) Advance(d time.Duration) {
+	s.AdvanceTo(s.Now().Add(d))
+}
+
+// AdvanceTo advances the virtual time to t, and fires the timers due by then in the order of their time.
+// The virtual time is set to the time of every timer when it fires, and the next timer fires once the fired
+// one is acknowledged, so the timers created meanwhile fire in order as well.
+func (s *TestScheduler) AdvanceTo(t time.Time) {
+	s.step()
+	for {
+		s.mu.Lock()
+		timer := s.next(t)
+		if timer == nil {
+			if t.After(s.now) {
+				s.now = t
+			}
+			s.mu.Unlock()
+			s.step()
+			return
+		}
+		s.remove(timer)
+		s.now = timer.at
+		timer.fired = true
+		timer.c <- timer.at
+		for !timer.acked {
+			s.cond.Wait()
+		}
+		s.mu.Unlock()
+		s.step()
+	}
+}
+
+// next returns the earliest timer due by t.
+func (s *TestScheduler) next(t time.Time) *testTimer {
+	sort.Slice(s.timers, func(i, j int) bool {
+		a, b := s.timers[i], s.timers[j]
+		if !a.at.Equal(b.at) {
+			return a.at.Before(b.at)
+		}
+		return a.seq < b.seq
+	})
+	if len(s.timers) == 0 || s.timers[0].at.After(t) {
+		return nil
+	}
+	return s.timers[0]
+}
+
+func (s *TestScheduler) remove(timer *testTimer) bool {
+	for i, t := range s.timers {
+		if t == timer {
+			s.timers = append(s.timers[:i], s.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (t *testTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *testTimer) Stop() bool {
+	s := t.scheduler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.fired {
+		t.acked = true
+	}
+	s.cond.Broadcast()
+	return s.remove(t)
+}
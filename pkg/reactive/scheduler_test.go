@@ -0,0 +1,166 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+var virtualStart = time.Unix(0, 0)
+
+type emission struct {
+	at time.Duration
+	v  interface{}
+}
+
+// timeline observes iterable, and records the virtual time of its items and completion every time s
+// steps, so the operator under test sends its items on a buffered channel before it waits again.
+func timeline(s *TestScheduler, iterable Iterable) func() ([]emission, time.Duration) {
+	var emissions []emission
+	var completed time.Duration
+	done := false
+	observe := iterable.Observe()
+	record := func(item Item, ok bool) {
+		at := s.Now().Sub(virtualStart)
+		switch {
+		case !ok:
+			done = true
+			completed = at
+		case item.Error():
+			emissions = append(emissions, emission{at: at, v: item.E})
+		default:
+			emissions = append(emissions, emission{at: at, v: item.V})
+		}
+	}
+	s.onStep(func() {
+		for !done {
+			select {
+			case item, ok := <-observe:
+				record(item, ok)
+			default:
+				return
+			}
+		}
+	})
+	return func() ([]emission, time.Duration) {
+		for !done {
+			item, ok := <-observe
+			record(item, ok)
+		}
+		return emissions, completed
+	}
+}
+
+func Test_TestScheduler_Timers(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+	a := s.NewTimer(10 * time.Millisecond)
+	b := s.NewTimer(5 * time.Millisecond)
+	c := s.NewTimer(20 * time.Millisecond)
+	assert.Equal(t, 3, s.Pending())
+	assert.True(t, c.Stop())
+	assert.False(t, c.Stop())
+
+	// the fire of a timer is acknowledged by the Stop of its receiver
+	fired := make(chan time.Time, 2)
+	for _, timer := range []SchedulerTimer{a, b} {
+		go func(timer SchedulerTimer) {
+			fired <- <-timer.C()
+			timer.Stop()
+		}(timer)
+	}
+
+	s.Advance(7 * time.Millisecond)
+	assert.Equal(t, virtualStart.Add(5*time.Millisecond), <-fired)
+	assert.Equal(t, virtualStart.Add(7*time.Millisecond), s.Now())
+	assert.Equal(t, 1, s.Pending())
+
+	s.AdvanceTo(virtualStart.Add(time.Second))
+	assert.Equal(t, virtualStart.Add(10*time.Millisecond), <-fired)
+	assert.False(t, a.Stop())
+	assert.Equal(t, virtualStart.Add(time.Second), s.Now())
+
+	// a timer of no duration fires immediately
+	assert.Equal(t, virtualStart.Add(time.Second), <-s.NewTimer(0).C())
+}
+
+func Test_TestScheduler_Interval(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewTestScheduler(virtualStart)
+
+	observe := Interval(WithDuration(10*time.Millisecond), WithScheduler(s), WithContext(ctx)).Observe()
+	s.BlockUntil(1)
+	for i := 0; i < 3; i++ {
+		s.Advance(10 * time.Millisecond)
+		assert.Equal(t, i, (<-observe).V)
+	}
+	s.Advance(5 * time.Millisecond)
+	cancel()
+	for item := range observe {
+		assert.Fail(t, "unexpected item", item.V)
+	}
+}
+
+func Test_TestScheduler_Timer(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+
+	result := timeline(s, Timer(WithDuration(10*time.Millisecond), WithScheduler(s)))
+	s.BlockUntil(1)
+	s.Advance(time.Hour)
+	emissions, completed := result()
+	assert.Empty(t, emissions)
+	assert.Equal(t, 10*time.Millisecond, completed)
+}
+
+func Test_TestScheduler_Repeat(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+
+	result := timeline(s, Just(1, 2)().Repeat(2, WithDuration(10*time.Millisecond), WithScheduler(s),
+		WithBufferedChannel(8)))
+	s.BlockUntil(1)
+	// Repeat completes once the fire of its last timer is acknowledged, so the time stops there
+	s.Advance(20 * time.Millisecond)
+	emissions, completed := result()
+	assert.Equal(t, []emission{
+		{0, 1}, {0, 2},
+		{10 * time.Millisecond, 1}, {10 * time.Millisecond, 2},
+		{20 * time.Millisecond, 1}, {20 * time.Millisecond, 2},
+	}, emissions)
+	assert.Equal(t, 20*time.Millisecond, completed)
+	assert.Equal(t, 0, s.Pending())
+}
+
+func Test_TestScheduler_Timestamp(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewTestScheduler(virtualStart)
+
+	Assert(ctx, t, Just(1)().Timestamp(WithScheduler(s)), HasItems(TimestampItem{Timestamp: virtualStart.UTC(), V: 1}))
+}
@@ -99,7 +99,7 @@ func (op *mapOperatorSingle) gatherNext(ctx context.Context, item Item, dst chan
 
 // Observe observes a Single by returning its channel.
 func (s *SingleImpl) Observe(opts ...Option) <-chan Item {
-	return s.iterable.Observe(opts...)
+	return observe(s.iterable, opts...)
 }
 
 type filterOperatorSingle struct {
@@ -0,0 +1,285 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// ThrottleSummary is emitted by the rate-limited operators with SummarizeOverflow, in place of the items
+// of a key over the rate, when the rate allows again.
+type ThrottleSummary struct {
+	// Key is the key of items, it's empty but for ThrottleByKey.
+	Key string
+	// Count is the number of the items summarized.
+	Count int
+	// First and Last are the first and last items summarized.
+	First, Last interface{}
+}
+
+// limiter tells when the items of a key can be emitted.
+type limiter interface {
+	// reserve takes a slot to emit an item at now if there is one, or returns the time of the next slot.
+	reserve(now time.Time) (bool, time.Time)
+	// idle returns if the limiter is back to its initial state at now, so it can be dropped.
+	idle(now time.Time) bool
+}
+
+// tokenBucket is a token bucket of count tokens, refilled by a token every per/count, implemented as a
+// generic cell rate algorithm, which keeps the theoretical arrival time of the next item.
+type tokenBucket struct {
+	interval  time.Duration
+	tolerance time.Duration
+	tat       time.Time
+}
+
+func newTokenBucket(count int, per time.Duration) *tokenBucket {
+	interval := per / time.Duration(count)
+	return &tokenBucket{interval: interval, tolerance: per - interval}
+}
+
+func (b *tokenBucket) reserve(now time.Time) (bool, time.Time) {
+	tat := b.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	if allowed := tat.Add(-b.tolerance); allowed.After(now) {
+		return false, allowed
+	}
+	b.tat = tat.Add(b.interval)
+	return true, now
+}
+
+func (b *tokenBucket) idle(now time.Time) bool {
+	return !b.tat.After(now)
+}
+
+// slidingLog allows count items in any window of per, by keeping the time of the last count items.
+type slidingLog struct {
+	count int
+	per   time.Duration
+	times []time.Time
+}
+
+func newSlidingLog(count int, per time.Duration) *slidingLog {
+	return &slidingLog{count: count, per: per}
+}
+
+func (l *slidingLog) reserve(now time.Time) (bool, time.Time) {
+	l.expire(now)
+	if len(l.times) < l.count {
+		l.times = append(l.times, now)
+		return true, now
+	}
+	return false, l.times[0].Add(l.per)
+}
+
+func (l *slidingLog) expire(now time.Time) {
+	i := 0
+	for i < len(l.times) && !l.times[i].Add(l.per).After(now) {
+		i++
+	}
+	l.times = l.times[i:]
+}
+
+func (l *slidingLog) idle(now time.Time) bool {
+	l.expire(now)
+	return len(l.times) == 0
+}
+
+// throttleKey is the state of a key of a rate-limited operator.
+type throttleKey struct {
+	key     string
+	limiter limiter
+	queue   []interface{}
+	summary *ThrottleSummary
+	// at is the time of the next slot, when there are items pending.
+	at time.Time
+}
+
+func (k *throttleKey) pending() bool {
+	return len(k.queue) > 0 || k.summary != nil
+}
+
+// overflow handles an item over the rate by the strategy, the items beyond the size of queue are
+// summarized.
+func (k *throttleKey) overflow(strategy OverflowStrategy, queueSize int, v interface{}) {
+	if strategy == QueueOverflow && len(k.queue) < queueSize && k.summary == nil {
+		k.queue = append(k.queue, v)
+		return
+	}
+	switch strategy {
+	case QueueOverflow, SummarizeOverflow:
+		if k.summary == nil {
+			k.summary = &ThrottleSummary{Key: k.key, First: v}
+		}
+		k.summary.Count++
+		k.summary.Last = v
+	}
+}
+
+// pop returns the next pending item.
+func (k *throttleKey) pop() interface{} {
+	if len(k.queue) > 0 {
+		v := k.queue[0]
+		k.queue[0] = nil
+		k.queue = k.queue[1:]
+		return v
+	}
+	summary := *k.summary
+	k.summary = nil
+	return summary
+}
+
+// throttle emits the items of o by key at the rate of the limiters created by newLimiter, and handles the
+// items over the rate by the overflow strategy of the options.
+func (o *ObservableImpl) throttle(key func(interface{}) string, newLimiter func() limiter, opts ...Option) Observable {
+	f := func(ctx context.Context, next chan Item, option Option, opts ...Option) {
+		scheduler := option.getScheduler()
+		timer := newLoopTimer(scheduler)
+		// the timer is stopped once next is closed, so the completion is there once its fire is acknowledged
+		defer timer.stop()
+		defer close(next)
+		observe := o.Observe(opts...)
+		strategy := option.getOverflowStrategy()
+		queueSize := option.getOverflowQueueSize()
+
+		keys := make(map[string]*throttleKey)
+		pending := make(map[string]*throttleKey)
+		sweep := 1024
+
+		// release emits the pending items whose slot has come.
+		release := func() bool {
+			now := scheduler.Now()
+			due := make([]*throttleKey, 0, len(pending))
+			for _, k := range pending {
+				if !k.at.After(now) {
+					due = append(due, k)
+				}
+			}
+			sort.Slice(due, func(i, j int) bool {
+				if !due[i].at.Equal(due[j].at) {
+					return due[i].at.Before(due[j].at)
+				}
+				return due[i].key < due[j].key
+			})
+			for _, k := range due {
+				for k.pending() {
+					ok, at := k.limiter.reserve(now)
+					if !ok {
+						k.at = at
+						break
+					}
+					if !Of(k.pop()).SendContext(ctx, next) {
+						return false
+					}
+				}
+				if !k.pending() {
+					delete(pending, k.key)
+				}
+			}
+			return true
+		}
+
+		for {
+			if observe == nil && len(pending) == 0 {
+				return
+			}
+			var wake <-chan time.Time
+			if len(pending) > 0 {
+				var earliest time.Time
+				for _, k := range pending {
+					if earliest.IsZero() || k.at.Before(earliest) {
+						earliest = k.at
+					}
+				}
+				wake = timer.after(earliest.Sub(scheduler.Now()))
+			} else {
+				timer.stop()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-wake:
+				if !release() {
+					return
+				}
+			case item, ok := <-observe:
+				if !ok {
+					// the pending items are still emitted at the rate
+					observe = nil
+					continue
+				}
+				if item.Error() {
+					if !item.SendContext(ctx, next) {
+						return
+					}
+					if option.getErrorStrategy() == StopOnError {
+						return
+					}
+					continue
+				}
+
+				name := ""
+				if key != nil {
+					name = key(item.V)
+				}
+				k, exists := keys[name]
+				if !exists {
+					k = &throttleKey{key: name, limiter: newLimiter()}
+					keys[name] = k
+				}
+				now := scheduler.Now()
+				if !k.pending() {
+					ok, at := k.limiter.reserve(now)
+					if ok {
+						if !item.SendContext(ctx, next) {
+							return
+						}
+						continue
+					}
+					k.at = at
+				}
+				k.overflow(strategy, queueSize, item.V)
+				if k.pending() {
+					pending[name] = k
+				}
+
+				// drops the state of the idle keys, when their number doubles
+				if len(keys) >= sweep {
+					for name, k := range keys {
+						if !k.pending() && k.limiter.idle(now) {
+							delete(keys, name)
+						}
+					}
+					if len(keys)*2 > sweep {
+						sweep = len(keys) * 2
+					}
+				}
+			}
+		}
+	}
+
+	return customObservableOperator(o.parent, f, opts...)
+}
@@ -0,0 +1,186 @@
+package reactive
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+func Test_Observable_RateLimit(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+	ch := make(chan Item)
+
+	// a token every 5ms, bursts of 2
+	result := timeline(s, FromChannel(ch).RateLimit(2, WithDuration(10*time.Millisecond), WithScheduler(s),
+		WithBufferedChannel(16)))
+	s.Send(ch, Of(1))
+	s.Send(ch, Of(2))
+	s.Send(ch, Of(3))
+	s.Advance(5 * time.Millisecond)
+	s.Send(ch, Of(4))
+	s.Send(ch, Of(5))
+	s.Advance(10 * time.Millisecond)
+	close(ch)
+	emissions, completed := result()
+	assert.Equal(t, []emission{
+		{0, 1},
+		{0, 2},
+		{5 * time.Millisecond, 4},
+	}, emissions)
+	assert.Equal(t, 15*time.Millisecond, completed)
+}
+
+func Test_Observable_RateLimit_Queue(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+	ch := make(chan Item)
+
+	result := timeline(s, FromChannel(ch).RateLimit(1, WithDuration(10*time.Millisecond), WithScheduler(s),
+		WithBufferedChannel(16), WithOverflowStrategy(QueueOverflow)))
+	s.Send(ch, Of(1))
+	s.Send(ch, Of(2))
+	s.Send(ch, Of(3))
+	// the queued items are emitted after the completion of the Observable
+	s.Close(ch)
+	s.Advance(30 * time.Millisecond)
+	emissions, completed := result()
+	assert.Equal(t, []emission{
+		{0, 1},
+		{10 * time.Millisecond, 2},
+		{20 * time.Millisecond, 3},
+	}, emissions)
+	assert.Equal(t, 20*time.Millisecond, completed)
+}
+
+func Test_Observable_RateLimit_QueueSize(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+	ch := make(chan Item)
+
+	result := timeline(s, FromChannel(ch).RateLimit(1, WithDuration(10*time.Millisecond), WithScheduler(s),
+		WithBufferedChannel(16), WithOverflowStrategy(QueueOverflow), WithOverflowQueueSize(1)))
+	s.Send(ch, Of(1))
+	s.Send(ch, Of(2))
+	// the items beyond the queue are summarized
+	s.Send(ch, Of(3))
+	s.Send(ch, Of(4))
+	s.Close(ch)
+	s.Advance(30 * time.Millisecond)
+	emissions, completed := result()
+	assert.Equal(t, []emission{
+		{0, 1},
+		{10 * time.Millisecond, 2},
+		{20 * time.Millisecond, ThrottleSummary{Count: 2, First: 3, Last: 4}},
+	}, emissions)
+	assert.Equal(t, 20*time.Millisecond, completed)
+}
+
+func Test_Observable_RateLimit_InvalidInput(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	Assert(ctx, t, Just(1)().RateLimit(0, WithDuration(time.Second)), IsEmpty(), HasAnError())
+	Assert(ctx, t, Just(1)().ThrottleFirst(WithDuration(0)), IsEmpty(), HasAnError())
+	Assert(ctx, t, Just(1)().ThrottleByKey(nil, 1, WithDuration(time.Second)), IsEmpty(), HasAnError())
+}
+
+func Test_Observable_ThrottleFirst(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+	ch := make(chan Item)
+
+	result := timeline(s, FromChannel(ch).ThrottleFirst(WithDuration(10*time.Millisecond), WithScheduler(s),
+		WithBufferedChannel(16), WithOverflowStrategy(SummarizeOverflow)))
+	s.Send(ch, Of(1))
+	s.Send(ch, Of(2))
+	s.Send(ch, Of(3))
+	// the summary of 2 and 3 is emitted when the window ends, and starts the next window
+	s.Advance(10 * time.Millisecond)
+	s.Advance(15 * time.Millisecond)
+	ch <- Of(4)
+	ch <- Error(errFoo)
+	close(ch)
+	emissions, _ := result()
+	assert.Equal(t, []emission{
+		{0, 1},
+		{10 * time.Millisecond, ThrottleSummary{Count: 2, First: 2, Last: 3}},
+		{25 * time.Millisecond, 4},
+		{25 * time.Millisecond, errFoo},
+	}, emissions)
+}
+
+type alert struct {
+	device string
+	n      int
+}
+
+func Test_Observable_ThrottleByKey(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	s := NewTestScheduler(virtualStart)
+	ch := make(chan Item)
+
+	// 2 alerts per minute by device
+	result := timeline(s, FromChannel(ch).ThrottleByKey(func(i interface{}) string {
+		return i.(alert).device
+	}, 2, WithDuration(time.Minute), WithScheduler(s),
+		WithBufferedChannel(16), WithOverflowStrategy(SummarizeOverflow)))
+	s.Send(ch, Of(alert{"a", 1}))
+	s.Send(ch, Of(alert{"a", 2}))
+	s.Send(ch, Of(alert{"a", 3}))
+	s.Send(ch, Of(alert{"b", 1}))
+	s.Advance(30 * time.Second)
+	s.Send(ch, Of(alert{"a", 4}))
+	s.Advance(30 * time.Second)
+	s.Send(ch, Of(alert{"a", 5}))
+	s.Send(ch, Of(alert{"a", 6}))
+	s.Close(ch)
+	s.Advance(time.Minute)
+	emissions, completed := result()
+	assert.Equal(t, []emission{
+		{0, alert{"a", 1}},
+		{0, alert{"a", 2}},
+		{0, alert{"b", 1}},
+		{time.Minute, ThrottleSummary{Key: "a", Count: 2, First: alert{"a", 3}, Last: alert{"a", 4}}},
+		{time.Minute, alert{"a", 5}},
+		{2 * time.Minute, ThrottleSummary{Key: "a", Count: 1, First: alert{"a", 6}, Last: alert{"a", 6}}},
+	}, emissions)
+	assert.Equal(t, 2*time.Minute, completed)
+}
+
+func Test_tokenBucket(t *testing.T) {
+	b := newTokenBucket(3, 30*time.Millisecond)
+	now := virtualStart
+	for i := 0; i < 3; i++ {
+		ok, _ := b.reserve(now)
+		assert.True(t, ok)
+	}
+	ok, at := b.reserve(now)
+	assert.False(t, ok)
+	assert.Equal(t, now.Add(10*time.Millisecond), at)
+	assert.False(t, b.idle(now))
+	assert.True(t, b.idle(now.Add(30*time.Millisecond)))
+}
@@ -89,6 +89,31 @@ const (
 	ContinueOnError
 )
 
+// JoinType is the type of JoinByKey.
+type JoinType uint32
+
+const (
+	// InnerJoin is the default join type, it emits the pairs of matched items only.
+	InnerJoin JoinType = iota
+	// LeftJoin emits the items of the left Observable without a match as well, paired with nil.
+	LeftJoin
+	// OuterJoin emits the items of both Observables without a match as well, paired with nil.
+	OuterJoin
+)
+
+// OverflowStrategy is what the rate-limited operators do with the items over the rate.
+type OverflowStrategy uint32
+
+const (
+	// DropOverflow is the default overflow strategy, it drops the items.
+	DropOverflow OverflowStrategy = iota
+	// QueueOverflow delays the items until the rate allows them, in order. The items beyond the size of
+	// queue, set by WithOverflowQueueSize, are summarized as by SummarizeOverflow.
+	QueueOverflow
+	// SummarizeOverflow emits a ThrottleSummary of the items, when the rate allows again.
+	SummarizeOverflow
+)
+
 // ObservationStrategy defines the strategy to consume from an Observable.
 type ObservationStrategy uint32
 
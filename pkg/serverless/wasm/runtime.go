@@ -0,0 +1,126 @@
+package wasm
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	engine "github.com/bhojpur/service/pkg/engine/core"
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+)
+
+// The host ABI of a WebAssembly stream function. The module imports the host functions from the
+// "env" namespace, and exports its linear memory as "memory" and the functions below; all the
+// parameters are i32, and the pointers are offsets in the memory of module.
+const (
+	// ImportModule is the namespace of the host functions.
+	ImportModule = "env"
+
+	// ObserveDataTagFunc is the host function `bhojpur_observe_datatag(tag)`, which the module calls
+	// in InitFunc once for each data tag it observes.
+	ObserveDataTagFunc = "bhojpur_observe_datatag"
+	// LoadInputFunc is the host function `bhojpur_load_input(pointer)`, which copies the payload of
+	// the data being handled to the memory of module at pointer.
+	LoadInputFunc = "bhojpur_load_input"
+	// DumpOutputFunc is the host function `bhojpur_dump_output(tag, pointer, length)`, which emits
+	// the bytes of memory at pointer as a data of tag; a handler emits zero or several outputs.
+	DumpOutputFunc = "bhojpur_dump_output"
+
+	// InitFunc is the exported function `bhojpur_init()`, which is called once after the module
+	// is instantiated.
+	InitFunc = "bhojpur_init"
+	// HandlerFunc is the exported function `bhojpur_handler(tag, length)`, which is called for each
+	// data of an observed tag, with the length of its payload.
+	HandlerFunc = "bhojpur_handler"
+	// MemoryName is the name of the exported memory.
+	MemoryName = "memory"
+)
+
+// ErrNoRuntime is returned when the command was built without cgo, which the WebAssembly runtime needs.
+var ErrNoRuntime = errors.New("wasm: no WebAssembly runtime, build with CGO_ENABLED=1 to run .wasm stream functions")
+
+// Runtime hosts a WebAssembly stream function in-process.
+type Runtime interface {
+	// Init loads and instantiates the module of file, and calls its InitFunc.
+	Init(file string) error
+	// ObserveDataTags returns the data tags which the module observes.
+	ObserveDataTags() []byte
+	// RunHandler calls the HandlerFunc of module with the data of tag, and returns its outputs.
+	RunHandler(tag byte, data []byte) ([]*frame.PayloadFrame, error)
+	// Close releases the module.
+	Close() error
+}
+
+// abi keeps the state of the host functions, shared by the runtimes: the data being handled and
+// the outputs of handler.
+type abi struct {
+	mu      sync.Mutex
+	tags    []byte
+	input   []byte
+	outputs []*frame.PayloadFrame
+}
+
+// observe is the ObserveDataTagFunc.
+func (a *abi) observe(tag int32) {
+	a.tags = append(a.tags, byte(tag))
+}
+
+// load is the LoadInputFunc, it copies the input to mem at pointer.
+func (a *abi) load(mem []byte, pointer int32) error {
+	if pointer < 0 || int(pointer)+len(a.input) > len(mem) {
+		return errors.New("wasm: bhojpur_load_input out of memory bounds")
+	}
+	copy(mem[pointer:], a.input)
+	return nil
+}
+
+// dump is the DumpOutputFunc, it copies length bytes of mem at pointer to an output of tag.
+func (a *abi) dump(mem []byte, tag, pointer, length int32) error {
+	if pointer < 0 || length < 0 || int(pointer)+int(length) > len(mem) {
+		return errors.New("wasm: bhojpur_dump_output out of memory bounds")
+	}
+	data := make([]byte, length)
+	copy(data, mem[pointer:])
+	a.outputs = append(a.outputs, engine.Output(byte(tag), data))
+	return nil
+}
+
+// handle calls fn with the input of data, one at a time as the module runs single-threaded, and
+// returns the outputs which fn dumps.
+func (a *abi) handle(data []byte, fn func() error) ([]*frame.PayloadFrame, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.input, a.outputs = data, nil
+	defer func() { a.input, a.outputs = nil, nil }()
+	if err := fn(); err != nil {
+		return nil, err
+	}
+	return a.outputs, nil
+}
+
+// handler adapts the Runtime to the Handler of stream function.
+func handler(rt Runtime) engine.Handler {
+	return func(_ context.Context, msg *engine.Message) ([]*frame.PayloadFrame, error) {
+		return rt.RunHandler(msg.Tag, msg.Payload)
+	}
+}
@@ -0,0 +1,99 @@
+package wasm
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+
+	engine "github.com/bhojpur/service/pkg/engine/core"
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+	"github.com/stretchr/testify/assert"
+)
+
+// echoRuntime runs the host functions like a module which observes 0x33 and 0x34, and dumps
+// the input as the output of tag+1, then its first byte as the output of 0x40.
+type echoRuntime struct {
+	abi
+	mem []byte
+}
+
+func (r *echoRuntime) Init(file string) error {
+	r.observe(0x33)
+	r.observe(0x34)
+	r.mem = make([]byte, 64)
+	return nil
+}
+
+func (r *echoRuntime) ObserveDataTags() []byte {
+	return r.tags
+}
+
+func (r *echoRuntime) RunHandler(tag byte, data []byte) ([]*frame.PayloadFrame, error) {
+	return r.handle(data, func() error {
+		if err := r.load(r.mem, 8); err != nil {
+			return err
+		}
+		if err := r.dump(r.mem, int32(tag)+1, 8, int32(len(data))); err != nil {
+			return err
+		}
+		return r.dump(r.mem, 0x40, 8, 1)
+	})
+}
+
+func (r *echoRuntime) Close() error {
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	rt := &echoRuntime{}
+	assert.NoError(t, rt.Init("echo.wasm"))
+	assert.Equal(t, []byte{0x33, 0x34}, rt.ObserveDataTags())
+
+	fn := handler(rt)
+	outputs, err := fn(context.Background(), &engine.Message{Tag: 0x34, Payload: []byte("hello")})
+	assert.NoError(t, err)
+	assert.Equal(t, []*frame.PayloadFrame{
+		engine.Output(0x35, []byte("hello")),
+		engine.Output(0x40, []byte("h")),
+	}, outputs)
+
+	// the outputs of a data are not mixed up with those of the previous data
+	outputs, err = fn(context.Background(), &engine.Message{Tag: 0x33, Payload: []byte("bye")})
+	assert.NoError(t, err)
+	assert.Equal(t, []*frame.PayloadFrame{
+		engine.Output(0x34, []byte("bye")),
+		engine.Output(0x40, []byte("b")),
+	}, outputs)
+}
+
+func TestHandler_OutOfBounds(t *testing.T) {
+	rt := &echoRuntime{}
+	assert.NoError(t, rt.Init("echo.wasm"))
+
+	_, err := handler(rt)(context.Background(), &engine.Message{Tag: 0x33, Payload: make([]byte, 64)})
+	assert.Error(t, err)
+
+	a := &abi{}
+	assert.Error(t, a.dump(make([]byte, 8), 0x33, 4, 8))
+	assert.Error(t, a.dump(make([]byte, 8), 0x33, -1, 1))
+	assert.Empty(t, a.outputs)
+}
@@ -0,0 +1,156 @@
+//go:build cgo
+// +build cgo
+
+package wasm
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/bhojpur/service/pkg/engine/core/frame"
+	"github.com/bhojpur/service/pkg/wasm/wasmer"
+)
+
+// wasmerRuntime hosts the module by Wasmer.
+type wasmerRuntime struct {
+	abi
+	store    *wasmer.Store
+	instance *wasmer.Instance
+	memory   *wasmer.Memory
+	handler  wasmer.NativeFunction
+}
+
+func newRuntime() (Runtime, error) {
+	return &wasmerRuntime{}, nil
+}
+
+// Init loads and instantiates the module of file, and calls its InitFunc.
+func (r *wasmerRuntime) Init(file string) error {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	r.store = wasmer.NewStore(wasmer.NewEngine())
+	module, err := wasmer.NewModule(r.store, bytes)
+	if err != nil {
+		return fmt.Errorf("wasm: compile %s: %w", file, err)
+	}
+
+	imports := wasmer.NewImportObject()
+	if wasmer.GetWasiVersion(module) != wasmer.WASI_VERSION_INVALID {
+		env, err := wasmer.NewWasiStateBuilder(filepath.Base(file)).
+			InheritStdout().
+			InheritStderr().
+			Finalize()
+		if err != nil {
+			return err
+		}
+		if imports, err = env.GenerateImportObject(r.store, module); err != nil {
+			return err
+		}
+	}
+	imports.Register(ImportModule, map[string]wasmer.IntoExtern{
+		ObserveDataTagFunc: r.function(1, func(args []wasmer.Value) error {
+			r.observe(args[0].I32())
+			return nil
+		}),
+		LoadInputFunc: r.function(1, func(args []wasmer.Value) error {
+			return r.load(r.memory.Data(), args[0].I32())
+		}),
+		DumpOutputFunc: r.function(3, func(args []wasmer.Value) error {
+			return r.dump(r.memory.Data(), args[0].I32(), args[1].I32(), args[2].I32())
+		}),
+	})
+
+	if r.instance, err = wasmer.NewInstance(module, imports); err != nil {
+		return fmt.Errorf("wasm: instantiate %s: %w", file, err)
+	}
+	if r.memory, err = r.instance.Exports.GetMemory(MemoryName); err != nil {
+		return err
+	}
+	if r.handler, err = r.instance.Exports.GetFunction(HandlerFunc); err != nil {
+		return err
+	}
+	// the runtime of a WASI module, e.g., of TinyGo, is initialized by _initialize or _start
+	for _, name := range []string{"_initialize", "_start"} {
+		if start, err := r.instance.Exports.GetFunction(name); err == nil {
+			if _, err := start(); err != nil {
+				return fmt.Errorf("wasm: %s: %w", name, err)
+			}
+			break
+		}
+	}
+	init, err := r.instance.Exports.GetFunction(InitFunc)
+	if err != nil {
+		return err
+	}
+	if _, err := init(); err != nil {
+		return fmt.Errorf("wasm: %s: %w", InitFunc, err)
+	}
+	return nil
+}
+
+// function returns a host function of n i32 parameters and no result.
+func (r *wasmerRuntime) function(n int, fn func([]wasmer.Value) error) *wasmer.Function {
+	params := make([]wasmer.ValueKind, n)
+	for i := range params {
+		params[i] = wasmer.I32
+	}
+	ty := wasmer.NewFunctionType(wasmer.NewValueTypes(params...), wasmer.NewValueTypes())
+	return wasmer.NewFunction(r.store, ty, func(args []wasmer.Value) ([]wasmer.Value, error) {
+		return []wasmer.Value{}, fn(args)
+	})
+}
+
+// ObserveDataTags returns the data tags which the module observes.
+func (r *wasmerRuntime) ObserveDataTags() []byte {
+	return r.tags
+}
+
+// RunHandler calls the HandlerFunc of module with the data of tag, and returns its outputs.
+func (r *wasmerRuntime) RunHandler(tag byte, data []byte) ([]*frame.PayloadFrame, error) {
+	if r.instance == nil {
+		return nil, errors.New("wasm: the runtime isn't initialized")
+	}
+	return r.handle(data, func() error {
+		if _, err := r.handler(int32(tag), int32(len(data))); err != nil {
+			return fmt.Errorf("wasm: %s: %w", HandlerFunc, err)
+		}
+		return nil
+	})
+}
+
+// Close releases the module.
+func (r *wasmerRuntime) Close() error {
+	if r.instance != nil {
+		r.instance.Close()
+		r.instance = nil
+	}
+	if r.store != nil {
+		r.store.Close()
+		r.store = nil
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package wasm
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	svcsvr "github.com/bhojpur/service/pkg/engine"
+	"github.com/bhojpur/service/pkg/engine/logger"
+	"github.com/bhojpur/service/pkg/serverless"
+	"github.com/bhojpur/service/pkg/utils"
+)
+
+// WasmServerless defines WebAssembly implementation of Serverless stream function interface, it
+// hosts the module in-process, and connects it to the Processor by the host ABI in runtime.go.
+type WasmServerless struct {
+	opts    *serverless.Options
+	runtime Runtime
+}
+
+// Init initializes the serverless stream function
+func (s *WasmServerless) Init(opts *serverless.Options) error {
+	if !utils.Exists(opts.Filename) {
+		return fmt.Errorf("the file %s doesn't exist", opts.Filename)
+	}
+	s.opts = opts
+
+	return nil
+}
+
+// Build loads the WebAssembly module, and calls its init function to get the observed data tags
+func (s *WasmServerless) Build(clean bool) error {
+	if s.runtime != nil {
+		s.runtime.Close()
+		s.runtime = nil
+	}
+	rt, err := newRuntime()
+	if err != nil {
+		return err
+	}
+	if err := rt.Init(s.opts.Filename); err != nil {
+		rt.Close()
+		return err
+	}
+	s.runtime = rt
+
+	return nil
+}
+
+// Run connects the WebAssembly stream function to the Processor, and runs it until it's terminated
+func (s *WasmServerless) Run(verbose bool) error {
+	if s.runtime == nil {
+		if err := s.Build(false); err != nil {
+			return err
+		}
+	}
+	defer s.runtime.Close()
+
+	tags := s.runtime.ObserveDataTags()
+	utils.InfoStatusEvent(os.Stdout, "Run WebAssembly serverless: %s, observe data tags: %#x", s.opts.Filename, tags)
+	opts := []svcsvr.Option{
+		svcsvr.WithProcessorAddr(fmt.Sprintf("%s:%d", s.opts.Host, s.opts.Port)),
+		svcsvr.WithObserveDataTags(tags...),
+	}
+	if verbose {
+		opts = append(opts, svcsvr.WithLogger(logger.Default(true)))
+	}
+	sfn := svcsvr.NewStreamFunction(s.opts.Name, opts...)
+	defer sfn.Close()
+
+	sfn.(svcsvr.ContextStreamFunction).SetContextHandler(handler(s.runtime))
+	errc := make(chan error, 1)
+	sfn.(svcsvr.ErrorHandlerSetter).SetErrorHandler(func(err error) {
+		select {
+		case errc <- err:
+		default:
+		}
+	})
+	if err := sfn.Connect(); err != nil {
+		return err
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sigc)
+	select {
+	case <-sigc:
+		return nil
+	case err := <-errc:
+		return err
+	}
+}
+
+func (s *WasmServerless) Executable() bool {
+	return false
+}
+
+func init() {
+	serverless.Register(&WasmServerless{}, ".wasm")
+}